@@ -0,0 +1,4 @@
+// Package walletv1 holds the generated gRPC API of the wallet service.
+package walletv1
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative wallet.proto
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/totorialman/go-test-ac/internal/auth"
+	"github.com/totorialman/go-test-ac/internal/repository/apikey"
+)
+
+const apikeyUsage = "usage: apikey create -name NAME -scopes SCOPES [-wallets IDS] [-owner OWNER] [-ttl DURATION] | list | revoke ID"
+
+// runAPIKey manages API keys: create prints the new key once, list shows
+// every key without secrets, revoke disables a key by id.
+func runAPIKey(ctx context.Context, repo *apikey.Repository, out io.Writer, args []string) error {
+	if len(args) == 0 {
+		return errors.New(apikeyUsage)
+	}
+
+	switch args[0] {
+	case "create":
+		return createAPIKey(ctx, repo, out, args[1:])
+	case "list":
+		keys, err := repo.List(ctx)
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tNAME\tPREFIX\tSCOPES\tWALLETS\tOWNER\tEXPIRES AT\tREVOKED AT")
+		for _, k := range keys {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+				k.ID, k.Name, k.Prefix, strings.Join(k.Scopes, ","), joinIDs(k.WalletIDs), k.OwnerID,
+				formatTime(k.ExpiresAt), formatTime(k.RevokedAt))
+		}
+		return w.Flush()
+	case "revoke":
+		if len(args) != 2 {
+			return errors.New(apikeyUsage)
+		}
+		id, err := uuid.Parse(args[1])
+		if err != nil {
+			return err
+		}
+		if err := repo.Revoke(ctx, id); err != nil {
+			return err
+		}
+		fmt.Fprintln(out, "revoked", id)
+		return nil
+	default:
+		return fmt.Errorf("unknown apikey command %q, %s", args[0], apikeyUsage)
+	}
+}
+
+func createAPIKey(ctx context.Context, repo *apikey.Repository, out io.Writer, args []string) error {
+	fs := flag.NewFlagSet("apikey create", flag.ContinueOnError)
+	name := fs.String("name", "", "who the key is for")
+	scopeList := fs.String("scopes", "", "comma-separated scopes: balance:read, wallet:deposit, wallet:withdraw, admin")
+	walletList := fs.String("wallets", "", "comma-separated wallet ids the key is restricted to")
+	owner := fs.String("owner", "", "owner whose wallets the key is restricted to")
+	ttl := fs.Duration("ttl", 0, "how long the key is valid; forever when 0")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *name == "" || *scopeList == "" {
+		return errors.New(apikeyUsage)
+	}
+
+	scopes, err := auth.ParseScopes(*scopeList)
+	if err != nil {
+		return err
+	}
+	var walletIDs []uuid.UUID
+	if *walletList != "" {
+		for _, s := range strings.Split(*walletList, ",") {
+			id, err := uuid.Parse(strings.TrimSpace(s))
+			if err != nil {
+				return fmt.Errorf("wallet id %q: %w", s, err)
+			}
+			walletIDs = append(walletIDs, id)
+		}
+	}
+
+	key, record, err := auth.NewKey(*name, scopes, walletIDs, *owner)
+	if err != nil {
+		return err
+	}
+	if *ttl > 0 {
+		expiresAt := time.Now().Add(*ttl)
+		record.ExpiresAt = &expiresAt
+	}
+
+	record, err = repo.Create(ctx, record)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "id:  %s\nkey: %s\n", record.ID, key)
+	fmt.Fprintln(out, "The key is shown only once; store it now.")
+	return nil
+}
+
+func joinIDs(ids []uuid.UUID) string {
+	s := make([]string, len(ids))
+	for i, id := range ids {
+		s[i] = id.String()
+	}
+	return strings.Join(s, ",")
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return "-"
+	}
+	return t.Format(time.RFC3339)
+}
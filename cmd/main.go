@@ -2,75 +2,396 @@ package main
 
 import (
 	"context"
-	"log"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
 	"syscall"
 	"time"
 
 	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"google.golang.org/grpc"
 
+	walletv1 "github.com/totorialman/go-test-ac/api/wallet/v1"
+	"github.com/totorialman/go-test-ac/internal/auth"
 	"github.com/totorialman/go-test-ac/internal/config"
+	"github.com/totorialman/go-test-ac/internal/grpc/interceptor"
+	walletGRPC "github.com/totorialman/go-test-ac/internal/grpc/wallet"
 	walletHandler "github.com/totorialman/go-test-ac/internal/handler/wallet"
+	webhookHandler "github.com/totorialman/go-test-ac/internal/handler/webhook"
+	"github.com/totorialman/go-test-ac/internal/health"
+	"github.com/totorialman/go-test-ac/internal/logging"
+	"github.com/totorialman/go-test-ac/internal/metrics"
+	"github.com/totorialman/go-test-ac/internal/outbox"
+	"github.com/totorialman/go-test-ac/internal/ratelimit"
+	apikeyRepository "github.com/totorialman/go-test-ac/internal/repository/apikey"
+	outboxRepository "github.com/totorialman/go-test-ac/internal/repository/outbox"
+	ratelimitRepository "github.com/totorialman/go-test-ac/internal/repository/ratelimit"
+	schemaRepository "github.com/totorialman/go-test-ac/internal/repository/schema"
 	walletRepository "github.com/totorialman/go-test-ac/internal/repository/wallet"
+	webhookRepository "github.com/totorialman/go-test-ac/internal/repository/webhook"
+	"github.com/totorialman/go-test-ac/internal/requestid"
+	"github.com/totorialman/go-test-ac/internal/tracing"
 	walletUsecase "github.com/totorialman/go-test-ac/internal/usecase/wallet"
+	webhookUsecase "github.com/totorialman/go-test-ac/internal/usecase/webhook"
+	"github.com/totorialman/go-test-ac/internal/webhook"
+	"github.com/totorialman/go-test-ac/migrations"
 )
 
+// outboxRetention is how long published events are kept for inspection.
+const outboxRetention = 7 * 24 * time.Hour
+
+// rateLimitIdle is after how long unused rate limit buckets are dropped from
+// Postgres; it must exceed the time any bucket takes to refill.
+const rateLimitIdle = time.Hour
+
 func main() {
-	servPort := ":" + os.Getenv("PORT")
+	cfg, args, err := config.Load(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		// No logger yet; print the list of invalid fields as is.
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
 
 	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 	defer stop()
 
-	dbPool := config.MustInitDB(ctx)
+	logger := mustInitLogger(cfg.Log)
+	slog.SetDefault(logger)
+
+	shutdownTracing, err := tracing.Setup(ctx)
+	if err != nil {
+		logging.Fatal(logger, "failed to set up tracing", err)
+	}
+	defer func() {
+		if err := shutdownTracing(context.Background()); err != nil {
+			logger.Error("tracing shutdown error", "error", err)
+		}
+	}()
+
+	dbPool := config.MustInitDB(ctx, cfg.Database, logger)
 	defer dbPool.Close()
 
-	walletRepo := walletRepository.NewRepository(dbPool)
-	walletUsecase := walletUsecase.NewUsecase(walletRepo)
-	walletHandler := walletHandler.NewHandler(walletUsecase)
+	apiKeyRepo := apikeyRepository.NewRepository(dbPool)
+
+	if len(args) > 0 {
+		switch args[0] {
+		case "migrate":
+			err = runMigrate(ctx, dbPool, logger, os.Stdout, args[1:])
+		case "apikey":
+			err = runAPIKey(ctx, apiKeyRepo, os.Stdout, args[1:])
+		default:
+			err = fmt.Errorf("unknown command %q", args[0])
+		}
+		if err != nil {
+			logging.Fatal(logger, "command failed", err)
+		}
+		return
+	}
+
+	if cfg.Features.MigrateOnStart {
+		if err := migrateOnStart(ctx, dbPool, logger); err != nil {
+			logging.Fatal(logger, "failed to apply migrations", err)
+		}
+	}
+
+	schemaVersion, err := migrations.LatestVersion()
+	if err != nil {
+		logging.Fatal(logger, "failed to read embedded migrations", err)
+	}
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		metrics.NewPoolCollector(dbPool),
+	)
+	appMetrics := metrics.New(registry)
+
+	walletRepo := walletRepository.NewRepository(dbPool, logger,
+		walletRepository.WithHotBuckets(cfg.Wallet.HotBuckets),
+		walletRepository.WithGroupCommit(cfg.Wallet.GroupCommitWindow, cfg.Wallet.GroupCommitMaxOps),
+	)
+	walletUsecase := walletUsecase.NewUsecase(walletRepo,
+		walletUsecase.WithIdempotencyTTL(cfg.Wallet.IdempotencyKeyTTL),
+		walletUsecase.WithHoldTTL(cfg.Wallet.HoldTTL),
+		walletUsecase.WithImplicitCreate(cfg.Features.ImplicitWalletCreate),
+		walletUsecase.WithOperationObserver(appMetrics),
+		walletUsecase.WithLogger(logger),
+	)
+	authorizer := auth.NewAuthorizer(apiKeyRepo)
+	// The HTTP middleware, the batch handler and the gRPC interceptors share
+	// the limiter, so they all take from the same buckets.
+	var limiter *ratelimit.Limiter
+	if cfg.RateLimit.Enabled {
+		if cfg.RateLimit.Store == "postgres" {
+			rateLimitRepo := ratelimitRepository.NewRepository(dbPool)
+			limiter = newLimiter(rateLimitRepo, authorizer, walletRepo, cfg.RateLimit, logger)
+			go runPeriodically(ctx, logger, 10*time.Minute, "rate limit buckets cleanup", func(ctx context.Context) (int64, error) {
+				return rateLimitRepo.DeleteIdle(ctx, rateLimitIdle)
+			})
+		} else {
+			limiter = newLimiter(ratelimit.NewMemoryStore(), authorizer, walletRepo, cfg.RateLimit, logger)
+		}
+	}
+	var walletHandlerOpts []walletHandler.Option
+	if limiter != nil {
+		walletHandlerOpts = append(walletHandlerOpts, walletHandler.WithRateLimiter(limiter))
+	}
+	walletHandler := walletHandler.NewHandler(walletUsecase, authorizer, logger, walletHandlerOpts...)
+
+	outboxOutput, err := openOutboxOutput(cfg.Outbox.File)
+	if err != nil {
+		logging.Fatal(logger, "failed to open outbox output", err)
+	}
+	defer outboxOutput.Close()
+
+	webhookRepo := webhookRepository.NewRepository(dbPool)
+	webhookHandler := webhookHandler.NewHandler(webhookUsecase.NewUsecase(webhookRepo), logger)
+	dispatcher := webhook.NewDispatcher(webhookRepo,
+		webhook.WithMaxAttempts(cfg.Webhook.MaxAttempts),
+		webhook.WithBackoff(cfg.Webhook.BaseBackoff, cfg.Webhook.MaxBackoff),
+		webhook.WithHTTPClient(&http.Client{Timeout: cfg.Webhook.Timeout}),
+		webhook.WithLogger(logger),
+	)
+
+	outboxRepo := outboxRepository.NewRepository(dbPool)
+	relay := outbox.NewRelay(outboxRepo, outbox.MultiPublisher{
+		outbox.NewWriterPublisher(outboxOutput),
+		webhook.NewFanout(webhookRepo),
+	}, outbox.WithLogger(logger))
+
+	checker := health.NewChecker(logger)
+	checker.Add("database", health.Database(dbPool))
+	checker.Add("migrations", health.Migrations(schemaRepository.NewRepository(dbPool), schemaVersion))
+
+	var jwtAuth auth.Authenticator
+	if cfg.Auth.JWT.Enabled() {
+		jwtAuth, err = newJWTAuthenticator(cfg.Auth.JWT, logger)
+		if err != nil {
+			logging.Fatal(logger, "failed to set up JWT authentication", err)
+		}
+	}
 
 	r := mux.NewRouter()
-	r.Use(loggingMiddleware)
-	r.HandleFunc("/api/v1/wallet", walletHandler.Operate).Methods("POST")
-	r.HandleFunc("/api/v1/wallets/{WALLET_UUID}", walletHandler.Balance).Methods("GET")
+	r.Use(requestid.Middleware)
+	r.Use(tracing.Middleware)
+	r.Use(logging.Middleware(logger))
+	r.Use(appMetrics.Middleware)
+	r.HandleFunc("/healthz", checker.Liveness).Methods("GET")
+	r.HandleFunc("/readyz", checker.Readiness).Methods("GET")
+	r.Handle("/metrics", promhttp.HandlerFor(registry, promhttp.HandlerOpts{})).Methods("GET")
+
+	authn := auth.Authenticators{
+		APIKeys: auth.NewAPIKeys(apiKeyRepo),
+		JWT:     jwtAuth,
+	}
+
+	api := r.PathPrefix("/api/v1").Subrouter()
+	api.Use(auth.Middleware(authn, logger))
+	if limiter != nil {
+		api.Use(limiter.Middleware)
+	}
+	// Operate, Batch and Balance check scopes and wallet restrictions
+	// themselves; every other endpoint needs an unrestricted admin key.
+	admin := auth.RequireScope(auth.ScopeAdmin)
+	api.HandleFunc("/wallet", walletHandler.Operate).Methods("POST")
+	api.HandleFunc("/wallet/batch", walletHandler.Batch).Methods("POST")
+	api.HandleFunc("/wallets/{WALLET_UUID}", walletHandler.Balance).Methods("GET")
+	api.HandleFunc("/transfers", admin(walletHandler.Transfer)).Methods("POST")
+	api.HandleFunc("/wallets", admin(walletHandler.Create)).Methods("POST")
+	api.HandleFunc("/wallets/{WALLET_UUID}", admin(walletHandler.UpdateStatus)).Methods("PATCH")
+	api.HandleFunc("/wallets/{WALLET_UUID}/hot", admin(walletHandler.SetHot)).Methods("PUT")
+	api.HandleFunc("/wallets/{WALLET_UUID}/transactions", admin(walletHandler.History)).Methods("GET")
+	api.HandleFunc("/wallets/{WALLET_UUID}/limits", admin(walletHandler.GetLimits)).Methods("GET")
+	api.HandleFunc("/wallets/{WALLET_UUID}/limits", admin(walletHandler.SetLimits)).Methods("PUT")
+	api.HandleFunc("/wallets/{WALLET_UUID}/holds", admin(walletHandler.Authorize)).Methods("POST")
+	api.HandleFunc("/holds/{HOLD_UUID}/capture", admin(walletHandler.Capture)).Methods("POST")
+	api.HandleFunc("/holds/{HOLD_UUID}/void", admin(walletHandler.Void)).Methods("POST")
+	api.HandleFunc("/transactions/{TRANSACTION_UUID}/reverse", admin(walletHandler.Reverse)).Methods("POST")
+	api.HandleFunc("/webhooks", admin(webhookHandler.Register)).Methods("POST")
+	api.HandleFunc("/webhooks", admin(webhookHandler.ListSubscriptions)).Methods("GET")
+	api.HandleFunc("/webhooks/{WEBHOOK_UUID}", admin(webhookHandler.DeleteSubscription)).Methods("DELETE")
+	api.HandleFunc("/admin/webhook-deliveries", admin(webhookHandler.ListDeliveries)).Methods("GET")
+	api.HandleFunc("/admin/webhook-deliveries/{DELIVERY_UUID}/replay", admin(webhookHandler.Replay)).Methods("POST")
+
+	servAddr := ":" + strconv.Itoa(cfg.Server.Port)
+	grpcAddr := ":" + strconv.Itoa(cfg.Server.GRPCPort)
 
 	server := &http.Server{
-		Addr:         servPort,
+		Addr:         servAddr,
 		Handler:      r,
-		ReadTimeout:  5 * time.Second,
-		WriteTimeout: 10 * time.Second,
-		IdleTimeout:  60 * time.Second,
+		ReadTimeout:  cfg.Server.ReadTimeout,
+		WriteTimeout: cfg.Server.WriteTimeout,
+		IdleTimeout:  cfg.Server.IdleTimeout,
 	}
 
+	unary := []grpc.UnaryServerInterceptor{interceptor.RequestIDUnary, interceptor.LoggingUnary(logger), interceptor.AuthUnary(authn, logger)}
+	stream := []grpc.StreamServerInterceptor{interceptor.RequestIDStream, interceptor.LoggingStream(logger), interceptor.AuthStream(authn, logger)}
+	if limiter != nil {
+		unary = append(unary, interceptor.RateLimitUnary(limiter))
+		stream = append(stream, interceptor.RateLimitStream(limiter))
+	}
+	grpcServer := grpc.NewServer(grpc.ChainUnaryInterceptor(unary...), grpc.ChainStreamInterceptor(stream...))
+	walletv1.RegisterWalletServiceServer(grpcServer, walletGRPC.NewServer(walletUsecase, authorizer, logger))
+
+	go runPeriodically(ctx, logger, time.Hour, "idempotency keys cleanup", walletRepo.DeleteExpiredIdempotencyKeys)
+	go runPeriodically(ctx, logger, time.Minute, "holds expiry", walletRepo.ExpireHolds)
+	// Runs even with hot buckets disabled, to fold what was left in them.
+	go runPeriodically(ctx, logger, cfg.Wallet.HotCompactInterval, "hot wallets compaction", walletRepo.CompactHotWallets)
+	go runPeriodically(ctx, logger, time.Hour, "published outbox events cleanup", func(ctx context.Context) (int64, error) {
+		return outboxRepo.DeletePublished(ctx, outboxRetention)
+	})
+	go relay.Run(ctx)
+	go dispatcher.Run(ctx)
+
 	go func() {
-		log.Printf("Server started on %s\n", servPort)
+		logger.Info("server started", "addr", servAddr)
 		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatalf("ListenAnd Serve error: %v", err)
+			logging.Fatal(logger, "ListenAndServe error", err)
+		}
+	}()
+
+	grpcListener, err := net.Listen("tcp", grpcAddr)
+	if err != nil {
+		logging.Fatal(logger, "gRPC listen error", err)
+	}
+	go func() {
+		logger.Info("gRPC server started", "addr", grpcAddr)
+		if err := grpcServer.Serve(grpcListener); err != nil {
+			logging.Fatal(logger, "gRPC Serve error", err)
 		}
 	}()
 
 	<-ctx.Done()
+	// A second signal kills the process instead of waiting for the drain.
+	stop()
 
-	log.Println("Shutting down server")
+	checker.SetDraining()
+	logger.Info("draining before shutdown", "delay", cfg.Server.DrainDelay)
+	time.Sleep(cfg.Server.DrainDelay)
 
-	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	logger.Info("shutting down server")
+
+	grpcStopped := make(chan struct{})
+	go func() {
+		grpcServer.GracefulStop()
+		close(grpcStopped)
+	}()
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
 	defer cancel()
 
 	if err := server.Shutdown(shutdownCtx); err != nil {
-		log.Printf("Server shutdown error: %v", err)
+		logger.Error("server shutdown error", "error", err)
+	}
+	select {
+	case <-grpcStopped:
+	case <-shutdownCtx.Done():
+		grpcServer.Stop()
+	}
+	logger.Info("server stopped gracefully")
+}
+
+// runPeriodically calls job every interval until ctx is cancelled. job
+// reports how many rows it touched.
+func runPeriodically(ctx context.Context, logger *slog.Logger, interval time.Duration, name string, job func(context.Context) (int64, error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			n, err := job(ctx)
+			if err != nil {
+				logger.ErrorContext(ctx, "periodic job error", "job", name, "error", err)
+				continue
+			}
+			if n > 0 {
+				logger.InfoContext(ctx, "periodic job done", "job", name, "rows", n)
+			}
+		}
 	}
-	log.Println("Server stopped gracefully")
 }
 
-func loggingMiddleware(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		start := time.Now()
+// openOutboxOutput returns where the outbox relay writes events: the file at
+// path, appended to, or stdout when path is empty.
+func openOutboxOutput(path string) (io.WriteCloser, error) {
+	if path == "" {
+		return os.Stdout, nil
+	}
+	return os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+}
 
-		log.Printf("started %s %s from %s", r.Method, r.RequestURI, r.RemoteAddr)
+// mustInitLogger builds the logger writing to stdout.
+func mustInitLogger(conf config.LogConfig) *slog.Logger {
+	logger, err := logging.New(os.Stdout, conf.Level, conf.Format)
+	if err != nil {
+		logging.Fatal(slog.Default(), "failed to create logger", err)
+	}
+	return logger
+}
 
-		next.ServeHTTP(w, r)
+// newJWTAuthenticator verifies bearer tokens against the configured key set.
+func newJWTAuthenticator(conf config.JWTConfig, logger *slog.Logger) (*auth.JWT, error) {
+	scopes, err := auth.ParseScopes(conf.DefaultScopes)
+	if err != nil {
+		return nil, err
+	}
 
-		log.Printf("completed %s %s in %v\n\n", r.Method, r.RequestURI, time.Since(start))
-	})
+	var keys *auth.JWKS
+	if conf.JWKSFile != "" {
+		keys = auth.NewJWKSFile(conf.JWKSFile, conf.JWKSCacheTTL, logger)
+	} else {
+		keys = auth.NewJWKSURL(conf.JWKSURL, &http.Client{Timeout: 5 * time.Second}, conf.JWKSCacheTTL, logger)
+	}
+
+	return auth.NewJWT(keys,
+		auth.WithIssuer(conf.Issuer),
+		auth.WithAudience(conf.Audience),
+		auth.WithSubjectClaim(conf.SubjectClaim),
+		auth.WithDefaultScopes(scopes),
+		auth.WithLeeway(conf.Leeway),
+	), nil
+}
+
+type rateLimitStore interface {
+	Take(ctx context.Context, key string, l ratelimit.Limit) (ratelimit.Result, error)
+}
+
+// newLimiter builds the API rate limiter with the configured per-route
+// limits. Hold and reversal requests are charged to the wallet of the hold
+// or the reversed entry.
+func newLimiter(store rateLimitStore, authorizer *auth.Authorizer, walletRepo *walletRepository.Repository, conf config.RateLimitConfig, logger *slog.Logger) *ratelimit.Limiter {
+	opts := []ratelimit.Option{
+		ratelimit.WithWalletAccess(authorizer),
+		ratelimit.WithWalletLookup("HOLD_UUID", walletRepo.HoldWalletID),
+		ratelimit.WithWalletLookup("TRANSACTION_UUID", walletRepo.TransactionWalletID),
+		ratelimit.WithLogger(logger),
+	}
+	for route, limits := range conf.Routes {
+		opts = append(opts, ratelimit.WithRoute(route, routeLimits(limits)))
+	}
+	return ratelimit.NewLimiter(store, routeLimits(conf.Default), opts...)
+}
+
+func routeLimits(conf config.RouteLimits) ratelimit.RouteLimits {
+	return ratelimit.RouteLimits{
+		Client: ratelimit.Limit{Rate: conf.Client.Rate, Burst: conf.Client.Burst},
+		Wallet: ratelimit.Limit{Rate: conf.Wallet.Rate, Burst: conf.Wallet.Burst},
+	}
 }
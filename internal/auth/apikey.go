@@ -0,0 +1,72 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"slices"
+
+	"github.com/google/uuid"
+
+	authErrors "github.com/totorialman/go-test-ac/internal/errors/auth"
+	"github.com/totorialman/go-test-ac/internal/repository/apikey"
+)
+
+// APIKeys authenticates callers by the API keys stored in the database.
+type APIKeys struct {
+	store keyStore
+}
+
+func NewAPIKeys(store keyStore) *APIKeys {
+	return &APIKeys{store: store}
+}
+
+func (a *APIKeys) Authenticate(ctx context.Context, key string) (Principal, error) {
+	k, err := a.store.FindActive(ctx, HashKey(key))
+	if errors.Is(err, authErrors.ErrAPIKeyNotFound) {
+		return Principal{}, authErrors.ErrUnauthenticated
+	}
+	if err != nil {
+		return Principal{}, err
+	}
+
+	scopes := make([]Scope, len(k.Scopes))
+	for i, s := range k.Scopes {
+		scopes[i] = Scope(s)
+	}
+
+	return Principal{
+		ID:        k.ID.String(),
+		Scopes:    scopes,
+		WalletIDs: k.WalletIDs,
+		OwnerID:   k.OwnerID,
+	}, nil
+}
+
+// NewKey generates an API key with the given scopes and restrictions. The
+// returned record holds only the hash; the key itself is shown to the
+// caller once and cannot be recovered.
+func NewKey(name string, scopes []Scope, walletIDs []uuid.UUID, ownerID string) (string, apikey.APIKeyDB, error) {
+	p := Principal{Scopes: scopes, WalletIDs: walletIDs, OwnerID: ownerID}
+	if slices.Contains(scopes, ScopeAdmin) && p.Restricted() {
+		return "", apikey.APIKeyDB{}, authErrors.ErrInvalidBinding
+	}
+
+	key, err := GenerateKey()
+	if err != nil {
+		return "", apikey.APIKeyDB{}, err
+	}
+
+	names := make([]string, len(scopes))
+	for i, s := range scopes {
+		names[i] = string(s)
+	}
+
+	return key, apikey.APIKeyDB{
+		Name:      name,
+		Prefix:    key[:len(keyPrefix)+6],
+		KeyHash:   HashKey(key),
+		Scopes:    names,
+		WalletIDs: walletIDs,
+		OwnerID:   ownerID,
+	}, nil
+}
@@ -0,0 +1,95 @@
+// Package auth authenticates API callers and checks what they may do.
+// Credentials resolve to a Principal that carries scopes and, optionally,
+// the wallets the caller is restricted to.
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+
+	authErrors "github.com/totorialman/go-test-ac/internal/errors/auth"
+)
+
+type Scope string
+
+const (
+	ScopeBalanceRead Scope = "balance:read"
+	ScopeDeposit     Scope = "wallet:deposit"
+	ScopeWithdraw    Scope = "wallet:withdraw"
+	// ScopeAdmin grants every other scope and the endpoints that are not
+	// tied to a single wallet.
+	ScopeAdmin Scope = "admin"
+)
+
+var knownScopes = []Scope{ScopeBalanceRead, ScopeDeposit, ScopeWithdraw, ScopeAdmin}
+
+// ParseScopes parses a comma-separated scope list.
+func ParseScopes(s string) ([]Scope, error) {
+	var scopes []Scope
+	for _, part := range strings.Split(s, ",") {
+		scope := Scope(strings.TrimSpace(part))
+		if !slices.Contains(knownScopes, scope) {
+			return nil, fmt.Errorf("%w: %q", authErrors.ErrInvalidScope, scope)
+		}
+		scopes = append(scopes, scope)
+	}
+	return scopes, nil
+}
+
+// Principal is an authenticated caller.
+type Principal struct {
+	// ID identifies the credential, e.g. the API key id.
+	ID     string
+	Scopes []Scope
+	// WalletIDs and OwnerID restrict the caller to the listed wallets and to
+	// the wallets of the owner. When both are empty every wallet is allowed.
+	WalletIDs []uuid.UUID
+	OwnerID   string
+}
+
+func (p Principal) HasScope(s Scope) bool {
+	return slices.Contains(p.Scopes, s) || slices.Contains(p.Scopes, ScopeAdmin)
+}
+
+// Restricted reports whether the principal is bound to particular wallets.
+func (p Principal) Restricted() bool {
+	return len(p.WalletIDs) > 0 || p.OwnerID != ""
+}
+
+type ctxKey struct{}
+
+func WithPrincipal(ctx context.Context, p Principal) context.Context {
+	return context.WithValue(ctx, ctxKey{}, p)
+}
+
+func FromContext(ctx context.Context) (Principal, bool) {
+	p, ok := ctx.Value(ctxKey{}).(Principal)
+	return p, ok
+}
+
+// keyPrefix marks wallet API keys so that they are easy to find in leaked
+// text.
+const keyPrefix = "wk_"
+
+// GenerateKey returns a new random API key.
+func GenerateKey() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return keyPrefix + base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// HashKey returns the digest under which key is stored. Keys are random and
+// long, so a fast hash is enough.
+func HashKey(key string) []byte {
+	sum := sha256.Sum256([]byte(key))
+	return sum[:]
+}
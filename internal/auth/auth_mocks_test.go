@@ -0,0 +1,129 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: contract.go
+
+// Package auth_test is a generated GoMock package.
+package auth_test
+
+import (
+	context "context"
+	crypto "crypto"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	uuid "github.com/google/uuid"
+	apikey "github.com/totorialman/go-test-ac/internal/repository/apikey"
+)
+
+// MockkeyStore is a mock of keyStore interface.
+type MockkeyStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockkeyStoreMockRecorder
+}
+
+// MockkeyStoreMockRecorder is the mock recorder for MockkeyStore.
+type MockkeyStoreMockRecorder struct {
+	mock *MockkeyStore
+}
+
+// NewMockkeyStore creates a new mock instance.
+func NewMockkeyStore(ctrl *gomock.Controller) *MockkeyStore {
+	mock := &MockkeyStore{ctrl: ctrl}
+	mock.recorder = &MockkeyStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockkeyStore) EXPECT() *MockkeyStoreMockRecorder {
+	return m.recorder
+}
+
+// FindActive mocks base method.
+func (m *MockkeyStore) FindActive(ctx context.Context, keyHash []byte) (apikey.APIKeyDB, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindActive", ctx, keyHash)
+	ret0, _ := ret[0].(apikey.APIKeyDB)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindActive indicates an expected call of FindActive.
+func (mr *MockkeyStoreMockRecorder) FindActive(ctx, keyHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindActive", reflect.TypeOf((*MockkeyStore)(nil).FindActive), ctx, keyHash)
+}
+
+// MockownerStore is a mock of ownerStore interface.
+type MockownerStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockownerStoreMockRecorder
+}
+
+// MockownerStoreMockRecorder is the mock recorder for MockownerStore.
+type MockownerStoreMockRecorder struct {
+	mock *MockownerStore
+}
+
+// NewMockownerStore creates a new mock instance.
+func NewMockownerStore(ctrl *gomock.Controller) *MockownerStore {
+	mock := &MockownerStore{ctrl: ctrl}
+	mock.recorder = &MockownerStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockownerStore) EXPECT() *MockownerStoreMockRecorder {
+	return m.recorder
+}
+
+// WalletOwner mocks base method.
+func (m *MockownerStore) WalletOwner(ctx context.Context, walletID uuid.UUID) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WalletOwner", ctx, walletID)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// WalletOwner indicates an expected call of WalletOwner.
+func (mr *MockownerStoreMockRecorder) WalletOwner(ctx, walletID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WalletOwner", reflect.TypeOf((*MockownerStore)(nil).WalletOwner), ctx, walletID)
+}
+
+// MockkeySource is a mock of keySource interface.
+type MockkeySource struct {
+	ctrl     *gomock.Controller
+	recorder *MockkeySourceMockRecorder
+}
+
+// MockkeySourceMockRecorder is the mock recorder for MockkeySource.
+type MockkeySourceMockRecorder struct {
+	mock *MockkeySource
+}
+
+// NewMockkeySource creates a new mock instance.
+func NewMockkeySource(ctrl *gomock.Controller) *MockkeySource {
+	mock := &MockkeySource{ctrl: ctrl}
+	mock.recorder = &MockkeySourceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockkeySource) EXPECT() *MockkeySourceMockRecorder {
+	return m.recorder
+}
+
+// Key mocks base method.
+func (m *MockkeySource) Key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Key", ctx, kid)
+	ret0, _ := ret[0].(crypto.PublicKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Key indicates an expected call of Key.
+func (mr *MockkeySourceMockRecorder) Key(ctx, kid interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Key", reflect.TypeOf((*MockkeySource)(nil).Key), ctx, kid)
+}
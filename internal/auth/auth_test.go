@@ -0,0 +1,196 @@
+package auth_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/totorialman/go-test-ac/internal/auth"
+	authErrors "github.com/totorialman/go-test-ac/internal/errors/auth"
+	"github.com/totorialman/go-test-ac/internal/logging"
+	"github.com/totorialman/go-test-ac/internal/repository/apikey"
+)
+
+func TestMiddleware(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := NewMockkeyStore(ctrl)
+	keyID := uuid.New()
+	walletID := uuid.New()
+
+	tests := []struct {
+		name              string
+		header            string
+		value             string
+		mockReturn        func()
+		expectedStatus    int
+		expectedBody      string
+		expectedPrincipal auth.Principal
+	}{
+		{
+			name:   "bearer api key",
+			header: "Authorization",
+			value:  "Bearer wk_valid",
+			mockReturn: func() {
+				store.EXPECT().FindActive(gomock.Any(), auth.HashKey("wk_valid")).Return(apikey.APIKeyDB{
+					ID:        keyID,
+					Scopes:    []string{"balance:read"},
+					WalletIDs: []uuid.UUID{walletID},
+				}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedPrincipal: auth.Principal{
+				ID:        keyID.String(),
+				Scopes:    []auth.Scope{auth.ScopeBalanceRead},
+				WalletIDs: []uuid.UUID{walletID},
+			},
+		},
+		{
+			name:   "x-api-key header",
+			header: auth.APIKeyHeader,
+			value:  "wk_valid",
+			mockReturn: func() {
+				store.EXPECT().FindActive(gomock.Any(), auth.HashKey("wk_valid")).Return(apikey.APIKeyDB{
+					ID:     keyID,
+					Scopes: []string{"admin"},
+				}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedPrincipal: auth.Principal{
+				ID:     keyID.String(),
+				Scopes: []auth.Scope{auth.ScopeAdmin},
+			},
+		},
+		{
+			name:           "missing credentials",
+			mockReturn:     func() {},
+			expectedStatus: http.StatusUnauthorized,
+			expectedBody:   `"code":"UNAUTHENTICATED"`,
+		},
+		{
+			name:           "basic auth is not accepted",
+			header:         "Authorization",
+			value:          "Basic dXNlcjpwYXNz",
+			mockReturn:     func() {},
+			expectedStatus: http.StatusUnauthorized,
+			expectedBody:   `"code":"UNAUTHENTICATED"`,
+		},
+		{
+			name:   "unknown or revoked key",
+			header: auth.APIKeyHeader,
+			value:  "wk_revoked",
+			mockReturn: func() {
+				store.EXPECT().FindActive(gomock.Any(), auth.HashKey("wk_revoked")).Return(apikey.APIKeyDB{}, authErrors.ErrAPIKeyNotFound)
+			},
+			expectedStatus: http.StatusUnauthorized,
+			expectedBody:   `"code":"UNAUTHENTICATED"`,
+		},
+		{
+			name:   "store error",
+			header: auth.APIKeyHeader,
+			value:  "wk_valid",
+			mockReturn: func() {
+				store.EXPECT().FindActive(gomock.Any(), gomock.Any()).Return(apikey.APIKeyDB{}, errors.New("connection refused"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `"code":"INTERNAL_ERROR"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockReturn()
+
+			var got auth.Principal
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got, _ = auth.FromContext(r.Context())
+			})
+			h := auth.Middleware(auth.NewAPIKeys(store), logging.Discard())(next)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/wallets/x", nil)
+			if tt.header != "" {
+				req.Header.Set(tt.header, tt.value)
+			}
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tt.expectedBody)
+			assert.Equal(t, tt.expectedPrincipal, got)
+		})
+	}
+}
+
+func TestRequireScope(t *testing.T) {
+	tests := []struct {
+		name           string
+		principal      *auth.Principal
+		expectedStatus int
+	}{
+		{
+			name:           "admin",
+			principal:      &auth.Principal{Scopes: []auth.Scope{auth.ScopeAdmin}},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "missing scope",
+			principal:      &auth.Principal{Scopes: []auth.Scope{auth.ScopeDeposit, auth.ScopeWithdraw}},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "restricted key",
+			principal:      &auth.Principal{Scopes: []auth.Scope{auth.ScopeAdmin}, OwnerID: "owner-1"},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "unauthenticated",
+			expectedStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := auth.RequireScope(auth.ScopeAdmin)(func(w http.ResponseWriter, r *http.Request) {})
+
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/transfers", nil)
+			if tt.principal != nil {
+				req = req.WithContext(auth.WithPrincipal(req.Context(), *tt.principal))
+			}
+			w := httptest.NewRecorder()
+			h(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+}
+
+func TestNewKey(t *testing.T) {
+	walletID := uuid.New()
+
+	key, record, err := auth.NewKey("payroll", []auth.Scope{auth.ScopeDeposit}, []uuid.UUID{walletID}, "")
+	require.NoError(t, err)
+	assert.Regexp(t, `^wk_[A-Za-z0-9_-]{43}$`, key)
+	assert.Equal(t, key[:9], record.Prefix)
+	assert.Equal(t, auth.HashKey(key), record.KeyHash)
+	assert.Equal(t, []string{"wallet:deposit"}, record.Scopes)
+	assert.Equal(t, []uuid.UUID{walletID}, record.WalletIDs)
+
+	_, _, err = auth.NewKey("ops", []auth.Scope{auth.ScopeAdmin}, nil, "owner-1")
+	assert.ErrorIs(t, err, authErrors.ErrInvalidBinding)
+}
+
+func TestParseScopes(t *testing.T) {
+	scopes, err := auth.ParseScopes("balance:read, wallet:withdraw")
+	require.NoError(t, err)
+	assert.Equal(t, []auth.Scope{auth.ScopeBalanceRead, auth.ScopeWithdraw}, scopes)
+
+	_, err = auth.ParseScopes("balance:read,root")
+	assert.ErrorIs(t, err, authErrors.ErrInvalidScope)
+}
@@ -0,0 +1,59 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"slices"
+
+	"github.com/google/uuid"
+
+	authErrors "github.com/totorialman/go-test-ac/internal/errors/auth"
+	walletErrors "github.com/totorialman/go-test-ac/internal/errors/wallet"
+)
+
+// Authorizer checks that the caller in the context may act on a wallet.
+type Authorizer struct {
+	owners ownerStore
+}
+
+func NewAuthorizer(owners ownerStore) *Authorizer {
+	return &Authorizer{owners: owners}
+}
+
+// Authorize returns nil if the caller has the scope and may access the
+// wallet. A missing wallet is reported to restricted callers as access
+// denied so that they cannot probe which ids exist.
+func (a *Authorizer) Authorize(ctx context.Context, walletID uuid.UUID, scope Scope) error {
+	p, ok := FromContext(ctx)
+	if !ok {
+		return authErrors.ErrUnauthenticated
+	}
+	if !p.HasScope(scope) {
+		return authErrors.ErrForbidden
+	}
+	return a.AuthorizeWallet(ctx, walletID)
+}
+
+// AuthorizeWallet returns nil if the caller may access the wallet with some
+// scope, without checking which.
+func (a *Authorizer) AuthorizeWallet(ctx context.Context, walletID uuid.UUID) error {
+	p, ok := FromContext(ctx)
+	if !ok {
+		return authErrors.ErrUnauthenticated
+	}
+	if !p.Restricted() || slices.Contains(p.WalletIDs, walletID) {
+		return nil
+	}
+
+	if p.OwnerID != "" {
+		owner, err := a.owners.WalletOwner(ctx, walletID)
+		if err != nil && !errors.Is(err, walletErrors.ErrWalletNotFound) {
+			return err
+		}
+		if err == nil && owner == p.OwnerID {
+			return nil
+		}
+	}
+
+	return authErrors.ErrWalletAccessDenied
+}
@@ -0,0 +1,156 @@
+package auth_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/totorialman/go-test-ac/internal/auth"
+	authErrors "github.com/totorialman/go-test-ac/internal/errors/auth"
+	walletErrors "github.com/totorialman/go-test-ac/internal/errors/wallet"
+)
+
+func TestAuthorizer_Authorize(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	owners := NewMockownerStore(ctrl)
+	a := auth.NewAuthorizer(owners)
+
+	walletID := uuid.New()
+	otherID := uuid.New()
+
+	tests := []struct {
+		name        string
+		principal   *auth.Principal
+		walletID    uuid.UUID
+		scope       auth.Scope
+		mockReturn  func()
+		expectedErr error
+	}{
+		{
+			name:        "unrestricted key",
+			principal:   &auth.Principal{Scopes: []auth.Scope{auth.ScopeDeposit}},
+			walletID:    walletID,
+			scope:       auth.ScopeDeposit,
+			mockReturn:  func() {},
+			expectedErr: nil,
+		},
+		{
+			name:        "admin has every scope",
+			principal:   &auth.Principal{Scopes: []auth.Scope{auth.ScopeAdmin}},
+			walletID:    walletID,
+			scope:       auth.ScopeWithdraw,
+			mockReturn:  func() {},
+			expectedErr: nil,
+		},
+		{
+			name:        "missing scope",
+			principal:   &auth.Principal{Scopes: []auth.Scope{auth.ScopeDeposit}},
+			walletID:    walletID,
+			scope:       auth.ScopeWithdraw,
+			mockReturn:  func() {},
+			expectedErr: authErrors.ErrForbidden,
+		},
+		{
+			name:        "bound wallet",
+			principal:   &auth.Principal{Scopes: []auth.Scope{auth.ScopeBalanceRead}, WalletIDs: []uuid.UUID{walletID}},
+			walletID:    walletID,
+			scope:       auth.ScopeBalanceRead,
+			mockReturn:  func() {},
+			expectedErr: nil,
+		},
+		{
+			name:        "other wallet",
+			principal:   &auth.Principal{Scopes: []auth.Scope{auth.ScopeBalanceRead}, WalletIDs: []uuid.UUID{walletID}},
+			walletID:    otherID,
+			scope:       auth.ScopeBalanceRead,
+			mockReturn:  func() {},
+			expectedErr: authErrors.ErrWalletAccessDenied,
+		},
+		{
+			name:      "owner's wallet",
+			principal: &auth.Principal{Scopes: []auth.Scope{auth.ScopeWithdraw}, OwnerID: "owner-1"},
+			walletID:  walletID,
+			scope:     auth.ScopeWithdraw,
+			mockReturn: func() {
+				owners.EXPECT().WalletOwner(gomock.Any(), walletID).Return("owner-1", nil)
+			},
+			expectedErr: nil,
+		},
+		{
+			name:      "wallet of another owner",
+			principal: &auth.Principal{Scopes: []auth.Scope{auth.ScopeWithdraw}, OwnerID: "owner-1"},
+			walletID:  walletID,
+			scope:     auth.ScopeWithdraw,
+			mockReturn: func() {
+				owners.EXPECT().WalletOwner(gomock.Any(), walletID).Return("owner-2", nil)
+			},
+			expectedErr: authErrors.ErrWalletAccessDenied,
+		},
+		{
+			name:      "missing wallet is not revealed",
+			principal: &auth.Principal{Scopes: []auth.Scope{auth.ScopeBalanceRead}, OwnerID: "owner-1"},
+			walletID:  walletID,
+			scope:     auth.ScopeBalanceRead,
+			mockReturn: func() {
+				owners.EXPECT().WalletOwner(gomock.Any(), walletID).Return("", walletErrors.ErrWalletNotFound)
+			},
+			expectedErr: authErrors.ErrWalletAccessDenied,
+		},
+		{
+			name:      "store error",
+			principal: &auth.Principal{Scopes: []auth.Scope{auth.ScopeBalanceRead}, OwnerID: "owner-1"},
+			walletID:  walletID,
+			scope:     auth.ScopeBalanceRead,
+			mockReturn: func() {
+				owners.EXPECT().WalletOwner(gomock.Any(), walletID).Return("", errors.New("connection refused"))
+			},
+			expectedErr: errors.New("connection refused"),
+		},
+		{
+			name:        "unauthenticated",
+			walletID:    walletID,
+			scope:       auth.ScopeBalanceRead,
+			mockReturn:  func() {},
+			expectedErr: authErrors.ErrUnauthenticated,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockReturn()
+
+			ctx := context.Background()
+			if tt.principal != nil {
+				ctx = auth.WithPrincipal(ctx, *tt.principal)
+			}
+
+			err := a.Authorize(ctx, tt.walletID, tt.scope)
+
+			assert.Equal(t, tt.expectedErr, err)
+		})
+	}
+}
+
+func TestAuthorizer_AuthorizeWallet(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	owners := NewMockownerStore(ctrl)
+	a := auth.NewAuthorizer(owners)
+
+	own, other := uuid.New(), uuid.New()
+	owners.EXPECT().WalletOwner(gomock.Any(), other).Return("bob", nil)
+
+	// The scope does not matter, only the wallet.
+	ctx := auth.WithPrincipal(context.Background(), auth.Principal{Scopes: []auth.Scope{auth.ScopeBalanceRead}, WalletIDs: []uuid.UUID{own}, OwnerID: "alice"})
+
+	assert.NoError(t, a.AuthorizeWallet(ctx, own))
+	assert.Equal(t, authErrors.ErrWalletAccessDenied, a.AuthorizeWallet(ctx, other))
+	assert.Equal(t, authErrors.ErrUnauthenticated, a.AuthorizeWallet(context.Background(), own))
+}
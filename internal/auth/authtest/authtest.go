@@ -0,0 +1,84 @@
+// Package authtest issues JWTs signed with locally generated keys and
+// publishes them as a JWKS, so that bearer authentication can be exercised
+// without a real identity provider.
+package authtest
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Issuer signs tokens with one key.
+type Issuer struct {
+	kid    string
+	key    crypto.Signer
+	method jwt.SigningMethod
+}
+
+// NewRSAIssuer returns an RS256 issuer with a fresh 2048-bit key.
+func NewRSAIssuer(kid string) (*Issuer, error) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, err
+	}
+	return &Issuer{kid: kid, key: key, method: jwt.SigningMethodRS256}, nil
+}
+
+// NewECIssuer returns an ES256 issuer with a fresh P-256 key.
+func NewECIssuer(kid string) (*Issuer, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	return &Issuer{kid: kid, key: key, method: jwt.SigningMethodES256}, nil
+}
+
+// Token signs claims. Claims without exp expire in an hour.
+func (i *Issuer) Token(claims jwt.MapClaims) (string, error) {
+	c := jwt.MapClaims{"exp": time.Now().Add(time.Hour).Unix()}
+	for k, v := range claims {
+		c[k] = v
+	}
+
+	t := jwt.NewWithClaims(i.method, c)
+	t.Header["kid"] = i.kid
+	return t.SignedString(i.key)
+}
+
+// JWKS returns the public keys of the issuers as a JWKS document.
+func JWKS(issuers ...*Issuer) ([]byte, error) {
+	keys := make([]map[string]string, 0, len(issuers))
+	for _, i := range issuers {
+		k := map[string]string{"kid": i.kid, "use": "sig", "alg": i.method.Alg()}
+		switch pub := i.key.Public().(type) {
+		case *rsa.PublicKey:
+			k["kty"] = "RSA"
+			k["n"] = encode(pub.N.Bytes())
+			k["e"] = encode(big.NewInt(int64(pub.E)).Bytes())
+		case *ecdsa.PublicKey:
+			point, err := pub.Bytes()
+			if err != nil {
+				return nil, err
+			}
+			k["kty"] = "EC"
+			k["crv"] = "P-256"
+			k["x"] = encode(point[1:33])
+			k["y"] = encode(point[33:])
+		}
+		keys = append(keys, k)
+	}
+	return json.Marshal(map[string]any{"keys": keys})
+}
+
+func encode(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
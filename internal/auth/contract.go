@@ -0,0 +1,23 @@
+//go:generate mockgen -source=contract.go -destination=auth_mocks_test.go -package=auth_test
+package auth
+
+import (
+	"context"
+	"crypto"
+
+	"github.com/google/uuid"
+
+	"github.com/totorialman/go-test-ac/internal/repository/apikey"
+)
+
+type keyStore interface {
+	FindActive(ctx context.Context, keyHash []byte) (apikey.APIKeyDB, error)
+}
+
+type ownerStore interface {
+	WalletOwner(ctx context.Context, walletID uuid.UUID) (string, error)
+}
+
+type keySource interface {
+	Key(ctx context.Context, kid string) (crypto.PublicKey, error)
+}
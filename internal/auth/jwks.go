@@ -0,0 +1,250 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	DefaultJWKSCacheTTL = 10 * time.Minute
+
+	// minJWKSRefresh limits refetching on unknown key ids, so that tokens
+	// with made-up ids cannot make the service hammer the JWKS endpoint.
+	minJWKSRefresh = time.Minute
+
+	// jwksLoadTimeout bounds a refetch. It runs apart from the request that
+	// triggered it, so that a cancelled request neither aborts it for the
+	// others waiting on it nor counts as a failed attempt.
+	jwksLoadTimeout = 10 * time.Second
+)
+
+var errUnknownKey = errors.New("unknown signing key")
+
+// JWKS is a JSON Web Key Set read from a file or an HTTP endpoint. Keys are
+// cached for the TTL and refetched early when a token names an unknown key,
+// which is how signing key rotation shows up. When a refetch fails the
+// previous keys stay in use.
+type JWKS struct {
+	load   func(ctx context.Context) ([]byte, error)
+	ttl    time.Duration
+	now    func() time.Time
+	logger *slog.Logger
+
+	// refetch lets concurrent requests share one load; mu is never held
+	// while loading.
+	refetch singleflight.Group
+
+	mu   sync.RWMutex
+	keys map[string]crypto.PublicKey
+	// checkedAt is the time of the last load attempt, successful or not, so
+	// that a failing endpoint is not retried on every request.
+	checkedAt time.Time
+}
+
+// NewJWKSFile returns a key set read from path.
+func NewJWKSFile(path string, ttl time.Duration, logger *slog.Logger) *JWKS {
+	return newJWKS(func(context.Context) ([]byte, error) {
+		return os.ReadFile(path)
+	}, ttl, logger)
+}
+
+// NewJWKSURL returns a key set fetched from url with client.
+func NewJWKSURL(url string, client *http.Client, ttl time.Duration, logger *slog.Logger) *JWKS {
+	return newJWKS(func(ctx context.Context) ([]byte, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("JWKS endpoint responded %d", resp.StatusCode)
+		}
+		return io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	}, ttl, logger)
+}
+
+func newJWKS(load func(context.Context) ([]byte, error), ttl time.Duration, logger *slog.Logger) *JWKS {
+	return &JWKS{load: load, ttl: ttl, now: time.Now, logger: logger}
+}
+
+// Key returns the public key with the given id. An empty kid matches the
+// only key of a single-key set.
+func (s *JWKS) Key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	s.mu.RLock()
+	age := s.now().Sub(s.checkedAt)
+	key, ok := s.lookup(kid)
+	stale := s.keys == nil || age >= s.ttl || (!ok && age >= minJWKSRefresh)
+	s.mu.RUnlock()
+
+	if stale {
+		err := s.refresh(ctx)
+
+		s.mu.RLock()
+		cached := s.keys != nil
+		key, ok = s.lookup(kid)
+		s.mu.RUnlock()
+
+		if err != nil {
+			if !cached {
+				return nil, err
+			}
+			s.logger.WarnContext(ctx, "JWKS refresh error, using cached keys", "error", err)
+		}
+	}
+	if !ok {
+		return nil, fmt.Errorf("%w %q", errUnknownKey, kid)
+	}
+
+	return key, nil
+}
+
+// lookup must be called with mu held.
+func (s *JWKS) lookup(kid string) (crypto.PublicKey, bool) {
+	if kid == "" && len(s.keys) == 1 {
+		for _, k := range s.keys {
+			return k, true
+		}
+	}
+	k, ok := s.keys[kid]
+	return k, ok
+}
+
+// refresh reloads the keys, joining a load already in flight. It returns
+// early when ctx is done; the load itself carries on and its keys are kept.
+func (s *JWKS) refresh(ctx context.Context) error {
+	ch := s.refetch.DoChan("", func() (any, error) {
+		loadCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), jwksLoadTimeout)
+		defer cancel()
+
+		data, err := s.load(loadCtx)
+		var keys map[string]crypto.PublicKey
+		if err != nil {
+			err = fmt.Errorf("load JWKS: %w", err)
+		} else {
+			keys, err = ParseJWKS(data)
+		}
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.checkedAt = s.now()
+		if err == nil {
+			s.keys = keys
+		}
+		return nil, err
+	})
+
+	select {
+	case res := <-ch:
+		return res.Err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// ParseJWKS returns the RSA and P-256 signing keys of a JWKS document by key
+// id. Keys of other types are skipped.
+func ParseJWKS(data []byte) (map[string]crypto.PublicKey, error) {
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("parse JWKS: %w", err)
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		var (
+			key crypto.PublicKey
+			err error
+		)
+		switch {
+		case k.Kty == "RSA":
+			key, err = parseRSAKey(k)
+		case k.Kty == "EC" && k.Crv == "P-256":
+			key, err = parseECKey(k)
+		default:
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("JWKS key %q: %w", k.Kid, err)
+		}
+		keys[k.Kid] = key
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("JWKS has no usable signing keys")
+	}
+
+	return keys, nil
+}
+
+func parseRSAKey(k jsonWebKey) (*rsa.PublicKey, error) {
+	n, err := base64.RawURLEncoding.DecodeString(k.N)
+	if err != nil {
+		return nil, fmt.Errorf("modulus: %w", err)
+	}
+	e, err := base64.RawURLEncoding.DecodeString(k.E)
+	if err != nil {
+		return nil, fmt.Errorf("exponent: %w", err)
+	}
+	exp := new(big.Int).SetBytes(e)
+	if !exp.IsInt64() || exp.Int64() < 3 || exp.Int64() > 1<<31-1 {
+		return nil, errors.New("invalid exponent")
+	}
+	if len(n) < 256 {
+		return nil, errors.New("modulus shorter than 2048 bits")
+	}
+
+	return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exp.Int64())}, nil
+}
+
+func parseECKey(k jsonWebKey) (*ecdsa.PublicKey, error) {
+	x, err := base64.RawURLEncoding.DecodeString(k.X)
+	if err != nil {
+		return nil, fmt.Errorf("x: %w", err)
+	}
+	y, err := base64.RawURLEncoding.DecodeString(k.Y)
+	if err != nil {
+		return nil, fmt.Errorf("y: %w", err)
+	}
+	if len(x) != 32 || len(y) != 32 {
+		return nil, errors.New("invalid P-256 coordinates")
+	}
+
+	point := append(append([]byte{4}, x...), y...)
+	return ecdsa.ParseUncompressedPublicKey(elliptic.P256(), point)
+}
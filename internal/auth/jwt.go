@@ -0,0 +1,168 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	authErrors "github.com/totorialman/go-test-ac/internal/errors/auth"
+)
+
+const (
+	DefaultSubjectClaim = "sub"
+	DefaultJWTLeeway    = 30 * time.Second
+)
+
+// JWT authenticates callers by RS256 or ES256 bearer tokens. The subject
+// claim becomes the owner the caller is restricted to, so a token holder can
+// act only on the wallets it owns.
+type JWT struct {
+	keys          keySource
+	issuer        string
+	audience      string
+	subjectClaim  string
+	defaultScopes []Scope
+	leeway        time.Duration
+}
+
+type JWTOption func(*JWT)
+
+// WithIssuer makes tokens from any other issuer invalid.
+func WithIssuer(iss string) JWTOption {
+	return func(j *JWT) {
+		j.issuer = iss
+	}
+}
+
+// WithAudience makes tokens not issued for aud invalid.
+func WithAudience(aud string) JWTOption {
+	return func(j *JWT) {
+		j.audience = aud
+	}
+}
+
+// WithSubjectClaim sets the claim holding the wallet owner id.
+func WithSubjectClaim(claim string) JWTOption {
+	return func(j *JWT) {
+		j.subjectClaim = claim
+	}
+}
+
+// WithDefaultScopes sets the scopes of tokens without a scope claim.
+func WithDefaultScopes(scopes []Scope) JWTOption {
+	return func(j *JWT) {
+		j.defaultScopes = scopes
+	}
+}
+
+// WithLeeway sets the allowed clock skew for exp, nbf and iat.
+func WithLeeway(d time.Duration) JWTOption {
+	return func(j *JWT) {
+		j.leeway = d
+	}
+}
+
+func NewJWT(keys keySource, opts ...JWTOption) *JWT {
+	j := &JWT{
+		keys:          keys,
+		subjectClaim:  DefaultSubjectClaim,
+		defaultScopes: []Scope{ScopeBalanceRead, ScopeDeposit, ScopeWithdraw},
+		leeway:        DefaultJWTLeeway,
+	}
+	for _, opt := range opts {
+		opt(j)
+	}
+	return j
+}
+
+func (j *JWT) Authenticate(ctx context.Context, token string) (Principal, error) {
+	parserOpts := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Alg(), jwt.SigningMethodES256.Alg()}),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(j.leeway),
+	}
+	if j.issuer != "" {
+		parserOpts = append(parserOpts, jwt.WithIssuer(j.issuer))
+	}
+	if j.audience != "" {
+		parserOpts = append(parserOpts, jwt.WithAudience(j.audience))
+	}
+
+	// A key set that cannot be loaded is our failure, not the caller's.
+	var keyErr error
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(token, claims, func(t *jwt.Token) (any, error) {
+		kid, _ := t.Header["kid"].(string)
+		key, err := j.keys.Key(ctx, kid)
+		if err != nil && !errors.Is(err, errUnknownKey) {
+			keyErr = err
+		}
+		return key, err
+	}, parserOpts...)
+	if keyErr != nil {
+		return Principal{}, keyErr
+	}
+	if err != nil {
+		return Principal{}, fmt.Errorf("%w: %v", authErrors.ErrUnauthenticated, err)
+	}
+
+	subject, _ := claims[j.subjectClaim].(string)
+	if subject == "" {
+		return Principal{}, fmt.Errorf("%w: token has no %s claim", authErrors.ErrUnauthenticated, j.subjectClaim)
+	}
+
+	return Principal{
+		ID:      "jwt:" + subject,
+		Scopes:  j.scopes(claims),
+		OwnerID: subject,
+	}, nil
+}
+
+// scopes reads the OAuth "scope" claim, a space-separated string, or the
+// "scp" list some issuers use instead. Scopes the service does not know,
+// such as openid, are ignored.
+func (j *JWT) scopes(claims jwt.MapClaims) []Scope {
+	var names []string
+	if v, ok := claims["scope"].(string); ok {
+		names = strings.Fields(v)
+	} else if list, ok := claims["scp"].([]any); ok {
+		for _, v := range list {
+			if name, ok := v.(string); ok {
+				names = append(names, name)
+			}
+		}
+	} else {
+		return j.defaultScopes
+	}
+
+	var scopes []Scope
+	for _, name := range names {
+		if s := Scope(name); slices.Contains(knownScopes, s) {
+			scopes = append(scopes, s)
+		}
+	}
+	return scopes
+}
+
+// Authenticators routes each credential to the authenticator for its kind:
+// API keys carry a fixed prefix, anything else is treated as a JWT.
+type Authenticators struct {
+	APIKeys Authenticator
+	// JWT is nil when bearer tokens are not accepted.
+	JWT Authenticator
+}
+
+func (a Authenticators) Authenticate(ctx context.Context, credential string) (Principal, error) {
+	if strings.HasPrefix(credential, keyPrefix) {
+		return a.APIKeys.Authenticate(ctx, credential)
+	}
+	if a.JWT == nil {
+		return Principal{}, authErrors.ErrUnauthenticated
+	}
+	return a.JWT.Authenticate(ctx, credential)
+}
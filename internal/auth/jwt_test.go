@@ -0,0 +1,286 @@
+package auth_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/totorialman/go-test-ac/internal/auth"
+	"github.com/totorialman/go-test-ac/internal/auth/authtest"
+	authErrors "github.com/totorialman/go-test-ac/internal/errors/auth"
+	"github.com/totorialman/go-test-ac/internal/logging"
+)
+
+func newIssuers(t *testing.T) (*authtest.Issuer, *authtest.Issuer) {
+	rsaIssuer, err := authtest.NewRSAIssuer("rsa-1")
+	require.NoError(t, err)
+	ecIssuer, err := authtest.NewECIssuer("ec-1")
+	require.NoError(t, err)
+	return rsaIssuer, ecIssuer
+}
+
+func writeJWKS(t *testing.T, issuers ...*authtest.Issuer) string {
+	data, err := authtest.JWKS(issuers...)
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+	return path
+}
+
+func TestJWT_Authenticate(t *testing.T) {
+	rsaIssuer, ecIssuer := newIssuers(t)
+	stranger, err := authtest.NewRSAIssuer("rsa-1")
+	require.NoError(t, err)
+
+	keys := auth.NewJWKSFile(writeJWKS(t, rsaIssuer, ecIssuer), time.Minute, logging.Discard())
+	a := auth.NewJWT(keys, auth.WithIssuer("https://id.example.com"), auth.WithAudience("wallet"))
+
+	valid := jwt.MapClaims{"iss": "https://id.example.com", "aud": "wallet", "sub": "user-42"}
+	with := func(extra jwt.MapClaims) jwt.MapClaims {
+		c := jwt.MapClaims{}
+		for k, v := range valid {
+			c[k] = v
+		}
+		for k, v := range extra {
+			c[k] = v
+		}
+		return c
+	}
+
+	tests := []struct {
+		name              string
+		issuer            *authtest.Issuer
+		claims            jwt.MapClaims
+		expectedPrincipal auth.Principal
+		expectedErr       error
+	}{
+		{
+			name:   "RS256 with default scopes",
+			issuer: rsaIssuer,
+			claims: valid,
+			expectedPrincipal: auth.Principal{
+				ID:      "jwt:user-42",
+				Scopes:  []auth.Scope{auth.ScopeBalanceRead, auth.ScopeDeposit, auth.ScopeWithdraw},
+				OwnerID: "user-42",
+			},
+		},
+		{
+			name:   "ES256 with scope claim",
+			issuer: ecIssuer,
+			claims: with(jwt.MapClaims{"scope": "openid balance:read"}),
+			expectedPrincipal: auth.Principal{
+				ID:      "jwt:user-42",
+				Scopes:  []auth.Scope{auth.ScopeBalanceRead},
+				OwnerID: "user-42",
+			},
+		},
+		{
+			name:   "scp list",
+			issuer: ecIssuer,
+			claims: with(jwt.MapClaims{"scp": []string{"wallet:deposit"}}),
+			expectedPrincipal: auth.Principal{
+				ID:      "jwt:user-42",
+				Scopes:  []auth.Scope{auth.ScopeDeposit},
+				OwnerID: "user-42",
+			},
+		},
+		{
+			name:        "expired",
+			issuer:      rsaIssuer,
+			claims:      with(jwt.MapClaims{"exp": time.Now().Add(-time.Hour).Unix()}),
+			expectedErr: authErrors.ErrUnauthenticated,
+		},
+		{
+			name:        "other issuer",
+			issuer:      rsaIssuer,
+			claims:      with(jwt.MapClaims{"iss": "https://evil.example.com"}),
+			expectedErr: authErrors.ErrUnauthenticated,
+		},
+		{
+			name:        "other audience",
+			issuer:      rsaIssuer,
+			claims:      with(jwt.MapClaims{"aud": "billing"}),
+			expectedErr: authErrors.ErrUnauthenticated,
+		},
+		{
+			name:        "no subject",
+			issuer:      rsaIssuer,
+			claims:      with(jwt.MapClaims{"sub": ""}),
+			expectedErr: authErrors.ErrUnauthenticated,
+		},
+		{
+			name:        "signed by an unknown key with a known kid",
+			issuer:      stranger,
+			claims:      valid,
+			expectedErr: authErrors.ErrUnauthenticated,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, err := tt.issuer.Token(tt.claims)
+			require.NoError(t, err)
+
+			p, err := a.Authenticate(context.Background(), token)
+
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedPrincipal, p)
+		})
+	}
+}
+
+func TestJWT_RejectsHMAC(t *testing.T) {
+	rsaIssuer, _ := newIssuers(t)
+	a := auth.NewJWT(auth.NewJWKSFile(writeJWKS(t, rsaIssuer), time.Minute, logging.Discard()))
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"sub": "user-42", "exp": time.Now().Add(time.Hour).Unix()})
+	token.Header["kid"] = "rsa-1"
+	signed, err := token.SignedString([]byte("secret"))
+	require.NoError(t, err)
+
+	_, err = a.Authenticate(context.Background(), signed)
+	assert.ErrorIs(t, err, authErrors.ErrUnauthenticated)
+}
+
+func TestJWKS_HTTP(t *testing.T) {
+	oldKey, newKey := newIssuers(t)
+
+	var (
+		requests atomic.Int32
+		current  atomic.Pointer[[]byte]
+	)
+	set := func(issuers ...*authtest.Issuer) {
+		data, err := authtest.JWKS(issuers...)
+		require.NoError(t, err)
+		current.Store(&data)
+	}
+	set(oldKey)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Write(*current.Load())
+	}))
+	defer srv.Close()
+
+	a := auth.NewJWT(auth.NewJWKSURL(srv.URL, srv.Client(), time.Hour, logging.Discard()))
+	ctx := context.Background()
+
+	for range 3 {
+		token, err := oldKey.Token(jwt.MapClaims{"sub": "user-42"})
+		require.NoError(t, err)
+		_, err = a.Authenticate(ctx, token)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, int32(1), requests.Load(), "keys are cached")
+
+	// A rotated key is not fetched within a minute of the last fetch.
+	set(oldKey, newKey)
+	token, err := newKey.Token(jwt.MapClaims{"sub": "user-42"})
+	require.NoError(t, err)
+	_, err = a.Authenticate(ctx, token)
+	assert.ErrorIs(t, err, authErrors.ErrUnauthenticated)
+	assert.Equal(t, int32(1), requests.Load())
+}
+
+func TestJWKS_SharedRefetch(t *testing.T) {
+	issuer, _ := newIssuers(t)
+	data, err := authtest.JWKS(issuer)
+	require.NoError(t, err)
+
+	var requests atomic.Int32
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		<-release
+		w.Write(data)
+	}))
+	defer srv.Close()
+
+	keys := auth.NewJWKSURL(srv.URL, srv.Client(), time.Hour, logging.Discard())
+
+	// The request that started the fetch gives up, the fetch does not.
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = keys.Key(ctx, "rsa-1")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	var wg sync.WaitGroup
+	for range 5 {
+		wg.Go(func() {
+			_, err := keys.Key(context.Background(), "rsa-1")
+			assert.NoError(t, err)
+		})
+	}
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), requests.Load(), "concurrent requests share one fetch")
+}
+
+func TestJWKS_Unavailable(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	issuer, _ := newIssuers(t)
+	token, err := issuer.Token(jwt.MapClaims{"sub": "user-42"})
+	require.NoError(t, err)
+
+	a := auth.NewJWT(auth.NewJWKSURL(srv.URL, srv.Client(), time.Hour, logging.Discard()))
+	_, err = a.Authenticate(context.Background(), token)
+
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, authErrors.ErrUnauthenticated, "a broken key set is a server error")
+}
+
+func TestAuthenticators_OwnWalletsOnly(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	issuer, _ := newIssuers(t)
+	keys := NewMockkeyStore(ctrl)
+	owners := NewMockownerStore(ctrl)
+	authn := auth.Authenticators{
+		APIKeys: auth.NewAPIKeys(keys),
+		JWT:     auth.NewJWT(auth.NewJWKSFile(writeJWKS(t, issuer), time.Minute, logging.Discard())),
+	}
+	authz := auth.NewAuthorizer(owners)
+
+	own, foreign := uuid.New(), uuid.New()
+	owners.EXPECT().WalletOwner(gomock.Any(), own).Return("user-42", nil)
+	owners.EXPECT().WalletOwner(gomock.Any(), foreign).Return("user-7", nil)
+
+	var authzErr []error
+	h := auth.Middleware(authn, logging.Discard())(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authzErr = append(authzErr,
+			authz.Authorize(r.Context(), own, auth.ScopeBalanceRead),
+			authz.Authorize(r.Context(), foreign, auth.ScopeBalanceRead),
+		)
+	}))
+
+	token, err := issuer.Token(jwt.MapClaims{"sub": "user-42"})
+	require.NoError(t, err)
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/wallets/x", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, []error{nil, authErrors.ErrWalletAccessDenied}, authzErr)
+}
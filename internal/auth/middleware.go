@@ -0,0 +1,84 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	authErrors "github.com/totorialman/go-test-ac/internal/errors/auth"
+	"github.com/totorialman/go-test-ac/internal/handler/response"
+)
+
+const APIKeyHeader = "X-API-Key"
+
+// Authenticator resolves a credential presented by the caller.
+type Authenticator interface {
+	Authenticate(ctx context.Context, credential string) (Principal, error)
+}
+
+// Middleware rejects requests without valid credentials with 401 and stores
+// the Principal of the others in the request context. The credential is read
+// from "Authorization: Bearer" or X-API-Key.
+func Middleware(authn Authenticator, logger *slog.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			credential := credentialFromRequest(r)
+			if credential == "" {
+				logger.WarnContext(r.Context(), "missing credentials")
+				unauthorized(w, r)
+				return
+			}
+
+			p, err := authn.Authenticate(r.Context(), credential)
+			if err != nil {
+				logger.Log(r.Context(), response.LogLevel(err), "authentication error", "error", err)
+				if errors.Is(err, authErrors.ErrUnauthenticated) {
+					unauthorized(w, r)
+				} else {
+					response.Error(w, r, err)
+				}
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(WithPrincipal(r.Context(), p)))
+		})
+	}
+}
+
+// RequireScope wraps a handler that is not tied to a single wallet. Callers
+// need the scope and must not be restricted to particular wallets.
+func RequireScope(scope Scope) func(http.HandlerFunc) http.HandlerFunc {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			p, ok := FromContext(r.Context())
+			switch {
+			case !ok:
+				unauthorized(w, r)
+			case !p.HasScope(scope):
+				response.Error(w, r, authErrors.ErrForbidden)
+			case p.Restricted():
+				response.Error(w, r, authErrors.ErrWalletAccessDenied)
+			default:
+				next(w, r)
+			}
+		}
+	}
+}
+
+func credentialFromRequest(r *http.Request) string {
+	if key := r.Header.Get(APIKeyHeader); key != "" {
+		return key
+	}
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if ok && strings.EqualFold(scheme, "Bearer") {
+		return strings.TrimSpace(token)
+	}
+	return ""
+}
+
+func unauthorized(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("WWW-Authenticate", "Bearer")
+	response.Error(w, r, authErrors.ErrUnauthenticated)
+}
@@ -1,30 +1,592 @@
 package config
 
 import (
+	"errors"
+	"flag"
 	"fmt"
+	"log/slog"
+	"net/url"
 	"os"
+	"strconv"
+	"time"
+
+	"go.yaml.in/yaml/v3"
+
+	walletUsecase "github.com/totorialman/go-test-ac/internal/usecase/wallet"
 )
 
-type PostgresConf struct {
-	Host     string
-	Port     string
-	User     string
-	Password string
-	Name     string
+// Config is the whole service configuration. Values are taken, each source
+// overriding the previous one, from the defaults, the YAML file, the
+// environment and command-line flags.
+type Config struct {
+	Server    ServerConfig    `yaml:"server"`
+	Database  DatabaseConfig  `yaml:"database"`
+	Log       LogConfig       `yaml:"log"`
+	Wallet    WalletConfig    `yaml:"wallet"`
+	Webhook   WebhookConfig   `yaml:"webhook"`
+	Outbox    OutboxConfig    `yaml:"outbox"`
+	Features  FeaturesConfig  `yaml:"features"`
+	Auth      AuthConfig      `yaml:"auth"`
+	RateLimit RateLimitConfig `yaml:"rate_limit"`
+}
+
+type ServerConfig struct {
+	Port         int           `yaml:"port"`
+	GRPCPort     int           `yaml:"grpc_port"`
+	ReadTimeout  time.Duration `yaml:"read_timeout"`
+	WriteTimeout time.Duration `yaml:"write_timeout"`
+	IdleTimeout  time.Duration `yaml:"idle_timeout"`
+	// DrainDelay is how long the server keeps serving after SIGTERM with
+	// readiness already failing, so that the load balancer can take the
+	// instance out of rotation before connections are closed.
+	DrainDelay time.Duration `yaml:"drain_delay"`
+	// ShutdownTimeout bounds waiting for in-flight requests on shutdown.
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout"`
+}
+
+type DatabaseConfig struct {
+	Host     string `yaml:"host"`
+	Port     int    `yaml:"port"`
+	User     string `yaml:"user"`
+	Password string `yaml:"password"`
+	Name     string `yaml:"name"`
+	// SSLMode is the libpq sslmode: disable, allow, prefer, require,
+	// verify-ca or verify-full.
+	SSLMode string `yaml:"sslmode"`
+	// SSLRootCert is the CA bundle used to verify the server certificate.
+	SSLRootCert     string        `yaml:"sslrootcert"`
+	MaxConns        int           `yaml:"max_conns"`
+	MinConns        int           `yaml:"min_conns"`
+	MaxConnLifetime time.Duration `yaml:"max_conn_lifetime"`
+	MaxConnIdleTime time.Duration `yaml:"max_conn_idle_time"`
+	Connect         RetryConfig   `yaml:"connect"`
+}
+
+// RetryConfig is how many times an operation is attempted and how long to
+// wait between attempts.
+type RetryConfig struct {
+	Attempts int           `yaml:"attempts"`
+	Interval time.Duration `yaml:"interval"`
+	Timeout  time.Duration `yaml:"timeout"`
+}
+
+type LogConfig struct {
+	Level  slog.Level `yaml:"level"`
+	Format string     `yaml:"format"`
+}
+
+type WalletConfig struct {
+	// IdempotencyKeyTTL is the retention window for Idempotency-Key values.
+	IdempotencyKeyTTL time.Duration `yaml:"idempotency_key_ttl"`
+	// HoldTTL is how long a hold reserves funds when the caller does not
+	// ask for a specific duration.
+	HoldTTL time.Duration `yaml:"hold_ttl"`
+	// HotBuckets is how many sub-balance buckets deposits to a hot wallet
+	// are spread over. Zero disables the mode.
+	HotBuckets int `yaml:"hot_buckets"`
+	// HotCompactInterval is how often the buckets are folded back into the
+	// wallet balance.
+	HotCompactInterval time.Duration `yaml:"hot_compact_interval"`
+	// GroupCommitWindow is how long a deposit or withdrawal waits for others
+	// to the same wallet to share its transaction. Zero disables grouping.
+	GroupCommitWindow time.Duration `yaml:"group_commit_window"`
+	// GroupCommitMaxOps closes a group early once it has this many
+	// operations.
+	GroupCommitMaxOps int `yaml:"group_commit_max_ops"`
+}
+
+type WebhookConfig struct {
+	// MaxAttempts is after how many failed attempts a delivery is moved to
+	// the dead-letter list.
+	MaxAttempts int           `yaml:"max_attempts"`
+	BaseBackoff time.Duration `yaml:"base_backoff"`
+	MaxBackoff  time.Duration `yaml:"max_backoff"`
+	Timeout     time.Duration `yaml:"timeout"`
+}
+
+type OutboxConfig struct {
+	// File is where the relay appends events; stdout when empty.
+	File string `yaml:"file"`
+}
+
+type FeaturesConfig struct {
+	// ImplicitWalletCreate lets a DEPOSIT create a wallet that does not
+	// exist.
+	ImplicitWalletCreate bool `yaml:"implicit_wallet_create"`
+	// MigrateOnStart applies pending migrations before the server starts.
+	MigrateOnStart bool `yaml:"migrate_on_start"`
+}
+
+type AuthConfig struct {
+	JWT JWTConfig `yaml:"jwt"`
+}
+
+// JWTConfig enables bearer JWTs when JWKSURL or JWKSFile is set.
+type JWTConfig struct {
+	JWKSURL      string        `yaml:"jwks_url"`
+	JWKSFile     string        `yaml:"jwks_file"`
+	JWKSCacheTTL time.Duration `yaml:"jwks_cache_ttl"`
+	Issuer       string        `yaml:"issuer"`
+	Audience     string        `yaml:"audience"`
+	// SubjectClaim holds the owner id the caller is restricted to.
+	SubjectClaim string `yaml:"subject_claim"`
+	// DefaultScopes is the comma-separated scope list of tokens without a
+	// scope claim.
+	DefaultScopes string        `yaml:"default_scopes"`
+	Leeway        time.Duration `yaml:"leeway"`
+}
+
+type RateLimitConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// Store keeps the buckets: memory limits each replica on its own,
+	// postgres shares them between replicas.
+	Store   string      `yaml:"store"`
+	Default RouteLimits `yaml:"default"`
+	// Routes replace Default for a route, keyed by the method and the path
+	// template, e.g. "POST /api/v1/wallet", or by the full gRPC method name.
+	// Set only from the YAML file.
+	Routes map[string]RouteLimits `yaml:"routes"`
+}
+
+// RouteLimits are the per-client and per-wallet buckets of a route.
+type RouteLimits struct {
+	Client LimitConfig `yaml:"client"`
+	Wallet LimitConfig `yaml:"wallet"`
+}
+
+// LimitConfig is a token bucket of Burst requests refilled at Rate per
+// second. A zero Rate disables it.
+type LimitConfig struct {
+	Rate  float64 `yaml:"rate"`
+	Burst int     `yaml:"burst"`
+}
+
+// Enabled reports whether a key set is configured.
+func (j JWTConfig) Enabled() bool {
+	return j.JWKSURL != "" || j.JWKSFile != ""
+}
+
+// Default returns the configuration used for everything no source sets.
+func Default() Config {
+	return Config{
+		Server: ServerConfig{
+			Port:            8080,
+			GRPCPort:        9090,
+			ReadTimeout:     5 * time.Second,
+			WriteTimeout:    10 * time.Second,
+			IdleTimeout:     60 * time.Second,
+			DrainDelay:      5 * time.Second,
+			ShutdownTimeout: 5 * time.Second,
+		},
+		Database: DatabaseConfig{
+			Port:            5432,
+			SSLMode:         "disable",
+			MaxConns:        100,
+			MinConns:        2,
+			MaxConnLifetime: time.Hour,
+			MaxConnIdleTime: 30 * time.Minute,
+			Connect: RetryConfig{
+				Attempts: 5,
+				Interval: 2 * time.Second,
+				Timeout:  2 * time.Second,
+			},
+		},
+		Log: LogConfig{
+			Level:  slog.LevelInfo,
+			Format: "json",
+		},
+		Wallet: WalletConfig{
+			IdempotencyKeyTTL:  24 * time.Hour,
+			HoldTTL:            walletUsecase.DefaultHoldTTL,
+			HotCompactInterval: 10 * time.Second,
+			GroupCommitMaxOps:  100,
+		},
+		Webhook: WebhookConfig{
+			MaxAttempts: 8,
+			BaseBackoff: 10 * time.Second,
+			MaxBackoff:  time.Hour,
+			Timeout:     10 * time.Second,
+		},
+		Auth: AuthConfig{
+			JWT: JWTConfig{
+				JWKSCacheTTL:  10 * time.Minute,
+				SubjectClaim:  "sub",
+				DefaultScopes: "balance:read,wallet:deposit,wallet:withdraw",
+				Leeway:        30 * time.Second,
+			},
+		},
+		RateLimit: RateLimitConfig{
+			Store: "memory",
+			Default: RouteLimits{
+				Client: LimitConfig{Rate: 100, Burst: 200},
+				Wallet: LimitConfig{Rate: 50, Burst: 100},
+			},
+		},
+	}
+}
+
+// Load builds the configuration from args, the command line without the
+// program name, and the process environment. The YAML file is read from the
+// -config flag or CONFIG_FILE. Load returns the arguments left after the
+// flags and an error listing every invalid value.
+func Load(args []string) (*Config, []string, error) {
+	return load(args, os.Getenv)
+}
+
+func load(args []string, getenv func(string) string) (*Config, []string, error) {
+	cfg := Default()
+	path := getenv("CONFIG_FILE")
+
+	fs := flag.NewFlagSet("wallet", flag.ContinueOnError)
+	fs.StringVar(&path, "config", path, "path to the YAML config file")
+	cfg.bindFlags(fs)
+
+	// Flags are parsed twice: first to find the config file, then again
+	// after the file and the environment so that they take precedence.
+	if err := fs.Parse(args); err != nil {
+		return nil, nil, err
+	}
+	if path != "" {
+		if err := cfg.loadFile(path); err != nil {
+			return nil, nil, err
+		}
+	}
+	envErr := cfg.loadEnv(getenv)
+	if err := fs.Parse(args); err != nil {
+		return nil, nil, err
+	}
+
+	if err := errors.Join(envErr, cfg.Validate()); err != nil {
+		return nil, nil, fmt.Errorf("invalid config:\n%w", err)
+	}
+
+	return &cfg, fs.Args(), nil
+}
+
+func (c *Config) loadFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("open config file: %w", err)
+	}
+	defer f.Close()
+
+	dec := yaml.NewDecoder(f)
+	dec.KnownFields(true)
+	if err := dec.Decode(c); err != nil {
+		return fmt.Errorf("parse config file %s: %w", path, err)
+	}
+
+	return nil
+}
+
+func (c *Config) loadEnv(getenv func(string) string) error {
+	e := envLoader{getenv: getenv}
+
+	e.int(&c.Server.Port, "PORT")
+	e.int(&c.Server.GRPCPort, "GRPC_PORT")
+	e.duration(&c.Server.ReadTimeout, "HTTP_READ_TIMEOUT")
+	e.duration(&c.Server.WriteTimeout, "HTTP_WRITE_TIMEOUT")
+	e.duration(&c.Server.IdleTimeout, "HTTP_IDLE_TIMEOUT")
+	e.duration(&c.Server.DrainDelay, "SHUTDOWN_DRAIN_DELAY")
+	e.duration(&c.Server.ShutdownTimeout, "SHUTDOWN_TIMEOUT")
+
+	e.string(&c.Database.Host, "POSTGRES_HOST")
+	e.int(&c.Database.Port, "POSTGRES_PORT")
+	e.string(&c.Database.User, "POSTGRES_USER")
+	e.string(&c.Database.Password, "POSTGRES_PASSWORD")
+	e.string(&c.Database.Name, "POSTGRES_DB")
+	e.string(&c.Database.SSLMode, "POSTGRES_SSLMODE")
+	e.string(&c.Database.SSLRootCert, "POSTGRES_SSLROOTCERT")
+	e.int(&c.Database.MaxConns, "POSTGRES_MAX_CONNS")
+	e.int(&c.Database.MinConns, "POSTGRES_MIN_CONNS")
+	e.duration(&c.Database.MaxConnLifetime, "POSTGRES_MAX_CONN_LIFETIME")
+	e.duration(&c.Database.MaxConnIdleTime, "POSTGRES_MAX_CONN_IDLE_TIME")
+	e.int(&c.Database.Connect.Attempts, "POSTGRES_CONNECT_ATTEMPTS")
+	e.duration(&c.Database.Connect.Interval, "POSTGRES_CONNECT_INTERVAL")
+	e.duration(&c.Database.Connect.Timeout, "POSTGRES_CONNECT_TIMEOUT")
+
+	e.text(&c.Log.Level, "LOG_LEVEL")
+	e.string(&c.Log.Format, "LOG_FORMAT")
+
+	e.duration(&c.Wallet.IdempotencyKeyTTL, "IDEMPOTENCY_KEY_TTL")
+	e.duration(&c.Wallet.HoldTTL, "WALLET_HOLD_TTL")
+	e.int(&c.Wallet.HotBuckets, "WALLET_HOT_BUCKETS")
+	e.duration(&c.Wallet.HotCompactInterval, "WALLET_HOT_COMPACT_INTERVAL")
+	e.duration(&c.Wallet.GroupCommitWindow, "WALLET_GROUP_COMMIT_WINDOW")
+	e.int(&c.Wallet.GroupCommitMaxOps, "WALLET_GROUP_COMMIT_MAX_OPS")
+
+	e.int(&c.Webhook.MaxAttempts, "WEBHOOK_MAX_ATTEMPTS")
+	e.duration(&c.Webhook.BaseBackoff, "WEBHOOK_BASE_BACKOFF")
+	e.duration(&c.Webhook.MaxBackoff, "WEBHOOK_MAX_BACKOFF")
+	e.duration(&c.Webhook.Timeout, "WEBHOOK_TIMEOUT")
+
+	e.string(&c.Outbox.File, "OUTBOX_FILE")
+
+	e.bool(&c.Features.ImplicitWalletCreate, "WALLET_IMPLICIT_CREATE")
+	e.bool(&c.Features.MigrateOnStart, "MIGRATE_ON_START")
+
+	e.string(&c.Auth.JWT.JWKSURL, "JWT_JWKS_URL")
+	e.string(&c.Auth.JWT.JWKSFile, "JWT_JWKS_FILE")
+	e.duration(&c.Auth.JWT.JWKSCacheTTL, "JWT_JWKS_CACHE_TTL")
+	e.string(&c.Auth.JWT.Issuer, "JWT_ISSUER")
+	e.string(&c.Auth.JWT.Audience, "JWT_AUDIENCE")
+	e.string(&c.Auth.JWT.SubjectClaim, "JWT_SUBJECT_CLAIM")
+	e.string(&c.Auth.JWT.DefaultScopes, "JWT_DEFAULT_SCOPES")
+	e.duration(&c.Auth.JWT.Leeway, "JWT_LEEWAY")
+
+	e.bool(&c.RateLimit.Enabled, "RATE_LIMIT_ENABLED")
+	e.string(&c.RateLimit.Store, "RATE_LIMIT_STORE")
+	e.float(&c.RateLimit.Default.Client.Rate, "RATE_LIMIT_CLIENT_RATE")
+	e.int(&c.RateLimit.Default.Client.Burst, "RATE_LIMIT_CLIENT_BURST")
+	e.float(&c.RateLimit.Default.Wallet.Rate, "RATE_LIMIT_WALLET_RATE")
+	e.int(&c.RateLimit.Default.Wallet.Burst, "RATE_LIMIT_WALLET_BURST")
+
+	return errors.Join(e.errs...)
+}
+
+// bindFlags registers a flag for every field, named after its YAML path.
+// The database password has no flag so that it never shows up in the
+// process list.
+func (c *Config) bindFlags(fs *flag.FlagSet) {
+	fs.IntVar(&c.Server.Port, "server.port", c.Server.Port, "HTTP port")
+	fs.IntVar(&c.Server.GRPCPort, "server.grpc_port", c.Server.GRPCPort, "gRPC port")
+	fs.DurationVar(&c.Server.ReadTimeout, "server.read_timeout", c.Server.ReadTimeout, "HTTP read timeout")
+	fs.DurationVar(&c.Server.WriteTimeout, "server.write_timeout", c.Server.WriteTimeout, "HTTP write timeout")
+	fs.DurationVar(&c.Server.IdleTimeout, "server.idle_timeout", c.Server.IdleTimeout, "HTTP keep-alive idle timeout")
+	fs.DurationVar(&c.Server.DrainDelay, "server.drain_delay", c.Server.DrainDelay, "time to keep serving after SIGTERM with readiness failing")
+	fs.DurationVar(&c.Server.ShutdownTimeout, "server.shutdown_timeout", c.Server.ShutdownTimeout, "time to wait for in-flight requests on shutdown")
+
+	fs.StringVar(&c.Database.Host, "database.host", c.Database.Host, "Postgres host")
+	fs.IntVar(&c.Database.Port, "database.port", c.Database.Port, "Postgres port")
+	fs.StringVar(&c.Database.User, "database.user", c.Database.User, "Postgres user")
+	fs.StringVar(&c.Database.Name, "database.name", c.Database.Name, "Postgres database")
+	fs.StringVar(&c.Database.SSLMode, "database.sslmode", c.Database.SSLMode, "Postgres sslmode")
+	fs.StringVar(&c.Database.SSLRootCert, "database.sslrootcert", c.Database.SSLRootCert, "CA bundle for verifying the Postgres server")
+	fs.IntVar(&c.Database.MaxConns, "database.max_conns", c.Database.MaxConns, "maximum pool size")
+	fs.IntVar(&c.Database.MinConns, "database.min_conns", c.Database.MinConns, "minimum pool size")
+	fs.DurationVar(&c.Database.MaxConnLifetime, "database.max_conn_lifetime", c.Database.MaxConnLifetime, "maximum lifetime of a connection")
+	fs.DurationVar(&c.Database.MaxConnIdleTime, "database.max_conn_idle_time", c.Database.MaxConnIdleTime, "maximum idle time of a connection")
+	fs.IntVar(&c.Database.Connect.Attempts, "database.connect.attempts", c.Database.Connect.Attempts, "connection attempts on startup")
+	fs.DurationVar(&c.Database.Connect.Interval, "database.connect.interval", c.Database.Connect.Interval, "delay between connection attempts")
+	fs.DurationVar(&c.Database.Connect.Timeout, "database.connect.timeout", c.Database.Connect.Timeout, "timeout of one connection attempt")
+
+	fs.TextVar(&c.Log.Level, "log.level", c.Log.Level, "minimum log level: debug, info, warn or error")
+	fs.StringVar(&c.Log.Format, "log.format", c.Log.Format, "log format: json or text")
+
+	fs.DurationVar(&c.Wallet.IdempotencyKeyTTL, "wallet.idempotency_key_ttl", c.Wallet.IdempotencyKeyTTL, "retention of Idempotency-Key values")
+	fs.DurationVar(&c.Wallet.HoldTTL, "wallet.hold_ttl", c.Wallet.HoldTTL, "default lifetime of a hold")
+	fs.IntVar(&c.Wallet.HotBuckets, "wallet.hot_buckets", c.Wallet.HotBuckets, "sub-balance buckets per hot wallet, 0 disables")
+	fs.DurationVar(&c.Wallet.HotCompactInterval, "wallet.hot_compact_interval", c.Wallet.HotCompactInterval, "how often hot wallet buckets are compacted")
+	fs.DurationVar(&c.Wallet.GroupCommitWindow, "wallet.group_commit_window", c.Wallet.GroupCommitWindow, "how long operations on a wallet wait to be committed together, 0 disables")
+	fs.IntVar(&c.Wallet.GroupCommitMaxOps, "wallet.group_commit_max_ops", c.Wallet.GroupCommitMaxOps, "most operations committed together")
+
+	fs.IntVar(&c.Webhook.MaxAttempts, "webhook.max_attempts", c.Webhook.MaxAttempts, "failed attempts before a delivery is dead")
+	fs.DurationVar(&c.Webhook.BaseBackoff, "webhook.base_backoff", c.Webhook.BaseBackoff, "delay after the first failed delivery")
+	fs.DurationVar(&c.Webhook.MaxBackoff, "webhook.max_backoff", c.Webhook.MaxBackoff, "upper bound of the delivery delay")
+	fs.DurationVar(&c.Webhook.Timeout, "webhook.timeout", c.Webhook.Timeout, "timeout of one delivery")
+
+	fs.StringVar(&c.Outbox.File, "outbox.file", c.Outbox.File, "file the outbox relay appends events to; stdout when empty")
+
+	fs.BoolVar(&c.Features.ImplicitWalletCreate, "features.implicit_wallet_create", c.Features.ImplicitWalletCreate, "let DEPOSIT create missing wallets")
+	fs.BoolVar(&c.Features.MigrateOnStart, "features.migrate_on_start", c.Features.MigrateOnStart, "apply pending migrations on start")
+
+	fs.StringVar(&c.Auth.JWT.JWKSURL, "auth.jwt.jwks_url", c.Auth.JWT.JWKSURL, "JWKS endpoint of the token issuer")
+	fs.StringVar(&c.Auth.JWT.JWKSFile, "auth.jwt.jwks_file", c.Auth.JWT.JWKSFile, "JWKS file of the token issuer")
+	fs.DurationVar(&c.Auth.JWT.JWKSCacheTTL, "auth.jwt.jwks_cache_ttl", c.Auth.JWT.JWKSCacheTTL, "how long JWKS keys are cached")
+	fs.StringVar(&c.Auth.JWT.Issuer, "auth.jwt.issuer", c.Auth.JWT.Issuer, "required iss claim")
+	fs.StringVar(&c.Auth.JWT.Audience, "auth.jwt.audience", c.Auth.JWT.Audience, "required aud claim")
+	fs.StringVar(&c.Auth.JWT.SubjectClaim, "auth.jwt.subject_claim", c.Auth.JWT.SubjectClaim, "claim holding the wallet owner id")
+	fs.StringVar(&c.Auth.JWT.DefaultScopes, "auth.jwt.default_scopes", c.Auth.JWT.DefaultScopes, "scopes of tokens without a scope claim")
+	fs.DurationVar(&c.Auth.JWT.Leeway, "auth.jwt.leeway", c.Auth.JWT.Leeway, "allowed clock skew")
+
+	fs.BoolVar(&c.RateLimit.Enabled, "rate_limit.enabled", c.RateLimit.Enabled, "limit API calls per client and per wallet")
+	fs.StringVar(&c.RateLimit.Store, "rate_limit.store", c.RateLimit.Store, "rate limit buckets store: memory or postgres")
+	fs.Float64Var(&c.RateLimit.Default.Client.Rate, "rate_limit.default.client.rate", c.RateLimit.Default.Client.Rate, "requests per second per client")
+	fs.IntVar(&c.RateLimit.Default.Client.Burst, "rate_limit.default.client.burst", c.RateLimit.Default.Client.Burst, "request burst per client")
+	fs.Float64Var(&c.RateLimit.Default.Wallet.Rate, "rate_limit.default.wallet.rate", c.RateLimit.Default.Wallet.Rate, "requests per second per wallet")
+	fs.IntVar(&c.RateLimit.Default.Wallet.Burst, "rate_limit.default.wallet.burst", c.RateLimit.Default.Wallet.Burst, "request burst per wallet")
+}
+
+var sslModes = map[string]bool{
+	"disable":     true,
+	"allow":       true,
+	"prefer":      true,
+	"require":     true,
+	"verify-ca":   true,
+	"verify-full": true,
+}
+
+// Validate checks every field and reports all invalid ones together.
+func (c *Config) Validate() error {
+	var errs []error
+	check := func(ok bool, field, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf("%s: %s", field, fmt.Sprintf(format, args...)))
+		}
+	}
+
+	s := c.Server
+	check(validPort(s.Port), "server.port", "must be between 1 and 65535, got %d", s.Port)
+	check(validPort(s.GRPCPort), "server.grpc_port", "must be between 1 and 65535, got %d", s.GRPCPort)
+	check(s.Port != s.GRPCPort, "server.grpc_port", "must differ from server.port")
+	check(s.ReadTimeout > 0, "server.read_timeout", "must be positive")
+	check(s.WriteTimeout > 0, "server.write_timeout", "must be positive")
+	check(s.IdleTimeout > 0, "server.idle_timeout", "must be positive")
+	check(s.DrainDelay >= 0, "server.drain_delay", "must not be negative")
+	check(s.ShutdownTimeout > 0, "server.shutdown_timeout", "must be positive")
+
+	d := c.Database
+	check(d.Host != "", "database.host", "is required")
+	check(validPort(d.Port), "database.port", "must be between 1 and 65535, got %d", d.Port)
+	check(d.User != "", "database.user", "is required")
+	check(d.Password != "", "database.password", "is required")
+	check(d.Name != "", "database.name", "is required")
+	check(sslModes[d.SSLMode], "database.sslmode", "unknown mode %q", d.SSLMode)
+	check(d.MaxConns > 0, "database.max_conns", "must be positive")
+	check(d.MinConns >= 0 && d.MinConns <= d.MaxConns, "database.min_conns", "must be between 0 and database.max_conns")
+	check(d.MaxConnLifetime > 0, "database.max_conn_lifetime", "must be positive")
+	check(d.MaxConnIdleTime > 0, "database.max_conn_idle_time", "must be positive")
+	check(d.Connect.Attempts > 0, "database.connect.attempts", "must be positive")
+	check(d.Connect.Interval >= 0, "database.connect.interval", "must not be negative")
+	check(d.Connect.Timeout > 0, "database.connect.timeout", "must be positive")
+
+	check(c.Log.Format == "json" || c.Log.Format == "text", "log.format", "must be json or text, got %q", c.Log.Format)
+
+	check(c.Wallet.IdempotencyKeyTTL > 0, "wallet.idempotency_key_ttl", "must be positive")
+	check(c.Wallet.HoldTTL > 0 && c.Wallet.HoldTTL <= walletUsecase.MaxHoldTTL, "wallet.hold_ttl", "must be positive and at most %s", walletUsecase.MaxHoldTTL)
+	check(c.Wallet.HotBuckets >= 0, "wallet.hot_buckets", "must not be negative")
+	check(c.Wallet.HotCompactInterval > 0, "wallet.hot_compact_interval", "must be positive")
+	check(c.Wallet.GroupCommitWindow >= 0, "wallet.group_commit_window", "must not be negative")
+	check(c.Wallet.GroupCommitMaxOps > 0, "wallet.group_commit_max_ops", "must be positive")
+
+	w := c.Webhook
+	check(w.MaxAttempts > 0, "webhook.max_attempts", "must be positive")
+	check(w.BaseBackoff > 0, "webhook.base_backoff", "must be positive")
+	check(w.MaxBackoff >= w.BaseBackoff, "webhook.max_backoff", "must not be less than webhook.base_backoff")
+	check(w.Timeout > 0, "webhook.timeout", "must be positive")
+
+	j := c.Auth.JWT
+	check(j.JWKSURL == "" || j.JWKSFile == "", "auth.jwt.jwks_file", "must not be set together with auth.jwt.jwks_url")
+	check(j.JWKSURL == "" || validHTTPURL(j.JWKSURL), "auth.jwt.jwks_url", "must be an absolute http or https url")
+	check(j.JWKSCacheTTL > 0, "auth.jwt.jwks_cache_ttl", "must be positive")
+	check(j.SubjectClaim != "", "auth.jwt.subject_claim", "is required")
+	check(j.Leeway >= 0, "auth.jwt.leeway", "must not be negative")
+
+	rl := c.RateLimit
+	check(rl.Store == "memory" || rl.Store == "postgres", "rate_limit.store", "must be memory or postgres, got %q", rl.Store)
+	checkLimits := func(field string, l RouteLimits) {
+		check(l.Client.Rate >= 0, field+".client.rate", "must not be negative")
+		check(l.Client.Rate == 0 || l.Client.Burst > 0, field+".client.burst", "must be positive")
+		check(l.Wallet.Rate >= 0, field+".wallet.rate", "must not be negative")
+		check(l.Wallet.Rate == 0 || l.Wallet.Burst > 0, field+".wallet.burst", "must be positive")
+	}
+	checkLimits("rate_limit.default", rl.Default)
+	for route, l := range rl.Routes {
+		checkLimits(fmt.Sprintf("rate_limit.routes[%q]", route), l)
+	}
+
+	return errors.Join(errs...)
+}
+
+func validPort(p int) bool {
+	return p > 0 && p <= 65535
+}
+
+func validHTTPURL(s string) bool {
+	u, err := url.Parse(s)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
 }
 
-func LoadConfigDB() (string, error) {
-	conf := PostgresConf{
-		Host:     os.Getenv("POSTGRES_HOST"),
-		Port:     os.Getenv("POSTGRES_PORT"),
-		User:     os.Getenv("POSTGRES_USER"),
-		Password: os.Getenv("POSTGRES_PASSWORD"),
-		Name:     os.Getenv("POSTGRES_DB"),
+// DSN returns the connection URL of the database.
+func (d DatabaseConfig) DSN() string {
+	q := url.Values{}
+	q.Set("sslmode", d.SSLMode)
+	if d.SSLRootCert != "" {
+		q.Set("sslrootcert", d.SSLRootCert)
 	}
 
-	if conf.Host == "" || conf.Port == "" || conf.User == "" || conf.Password == "" || conf.Name == "" {
-		return "", fmt.Errorf("missing required DB env vars")
+	u := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(d.User, d.Password),
+		Host:     fmt.Sprintf("%s:%d", d.Host, d.Port),
+		Path:     "/" + d.Name,
+		RawQuery: q.Encode(),
 	}
+	return u.String()
+}
+
+// envLoader reads environment variables into config fields, collecting parse
+// errors instead of stopping at the first one. Unset variables leave the
+// field as is.
+type envLoader struct {
+	getenv func(string) string
+	errs   []error
+}
+
+func (e *envLoader) string(dst *string, key string) {
+	if v := e.getenv(key); v != "" {
+		*dst = v
+	}
+}
+
+func (e *envLoader) int(dst *int, key string) {
+	v := e.getenv(key)
+	if v == "" {
+		return
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		e.errs = append(e.errs, fmt.Errorf("%s: invalid integer %q", key, v))
+		return
+	}
+	*dst = n
+}
 
-	return fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=disable", conf.User, conf.Password, conf.Host, conf.Port, conf.Name), nil
+func (e *envLoader) float(dst *float64, key string) {
+	v := e.getenv(key)
+	if v == "" {
+		return
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		e.errs = append(e.errs, fmt.Errorf("%s: invalid number %q", key, v))
+		return
+	}
+	*dst = f
+}
+
+func (e *envLoader) bool(dst *bool, key string) {
+	v := e.getenv(key)
+	if v == "" {
+		return
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		e.errs = append(e.errs, fmt.Errorf("%s: invalid boolean %q", key, v))
+		return
+	}
+	*dst = b
+}
+
+func (e *envLoader) duration(dst *time.Duration, key string) {
+	v := e.getenv(key)
+	if v == "" {
+		return
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		e.errs = append(e.errs, fmt.Errorf("%s: invalid duration %q", key, v))
+		return
+	}
+	*dst = d
+}
+
+func (e *envLoader) text(dst interface{ UnmarshalText([]byte) error }, key string) {
+	v := e.getenv(key)
+	if v == "" {
+		return
+	}
+	if err := dst.UnmarshalText([]byte(v)); err != nil {
+		e.errs = append(e.errs, fmt.Errorf("%s: %w", key, err))
+	}
 }
@@ -2,53 +2,54 @@ package config
 
 import (
 	"context"
-	"log"
+	"log/slog"
 	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
-)
 
-func MustInitDB(ctx context.Context) *pgxpool.Pool {
-	dsn, err := LoadConfigDB()
-	if err != nil {
-		log.Fatalf("failed to load DB config: %v", err)
-	}
+	"github.com/totorialman/go-test-ac/internal/logging"
+	"github.com/totorialman/go-test-ac/internal/tracing"
+)
 
-	cfg, err := pgxpool.ParseConfig(dsn)
+// MustInitDB creates the connection pool and waits until the database
+// answers, retrying as configured.
+func MustInitDB(ctx context.Context, conf DatabaseConfig, logger *slog.Logger) *pgxpool.Pool {
+	cfg, err := pgxpool.ParseConfig(conf.DSN())
 	if err != nil {
-		log.Fatalf("failed to parse DB config: %v", err)
+		logging.Fatal(logger, "failed to parse DB config", err)
 	}
 
-	cfg.MaxConns = 100
-	cfg.MinConns = 2
-	cfg.MaxConnLifetime = time.Hour
-	cfg.MaxConnIdleTime = 30 * time.Minute
+	cfg.MaxConns = int32(conf.MaxConns)
+	cfg.MinConns = int32(conf.MinConns)
+	cfg.MaxConnLifetime = conf.MaxConnLifetime
+	cfg.MaxConnIdleTime = conf.MaxConnIdleTime
+	cfg.ConnConfig.Tracer = tracing.QueryTracer{}
 
 	pool, err := pgxpool.NewWithConfig(ctx, cfg)
 	if err != nil {
-		log.Fatalf("failed to create DB pool: %v", err)
+		logging.Fatal(logger, "failed to create DB pool", err)
 	}
 
-	for i := range 5 {
+	for i := range conf.Connect.Attempts {
 		select {
 		case <-ctx.Done():
-			log.Fatalf("DB init cancelled: %v", ctx.Err())
+			logging.Fatal(logger, "DB init cancelled", ctx.Err())
 		default:
 		}
 
-		pingCtx, cancel := context.WithTimeout(ctx, 2*time.Second)
+		pingCtx, cancel := context.WithTimeout(ctx, conf.Connect.Timeout)
 		err := pool.Ping(pingCtx)
 		cancel()
 
 		if err == nil {
-			log.Println("DB connection established")
+			logger.Info("DB connection established")
 			return pool
 		}
 
-		log.Printf("DB not ready (attempt %d/5): %v", i+1, err)
-		time.Sleep(2 * time.Second)
+		logger.Warn("DB not ready", "attempt", i+1, "max_attempts", conf.Connect.Attempts, "error", err)
+		time.Sleep(conf.Connect.Interval)
 	}
 
-	log.Fatalf("DB connection failed after retries")
+	logging.Fatal(logger, "DB connection failed after retries", nil)
 	return nil
 }
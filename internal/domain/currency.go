@@ -0,0 +1,47 @@
+package domain
+
+// DefaultCurrency is used for wallets created without an explicit currency,
+// including every wallet that existed before currencies were introduced.
+const DefaultCurrency = "RUB"
+
+// Minor-unit exponents from ISO 4217: an amount of 1050 in a currency with
+// exponent 2 means 10.50.
+var currencyExponents = map[string]int{
+	"AED": 2,
+	"AMD": 2,
+	"AUD": 2,
+	"AZN": 2,
+	"BHD": 3,
+	"BYN": 2,
+	"CAD": 2,
+	"CHF": 2,
+	"CNY": 2,
+	"CZK": 2,
+	"EUR": 2,
+	"GBP": 2,
+	"GEL": 2,
+	"HKD": 2,
+	"INR": 2,
+	"JOD": 3,
+	"JPY": 0,
+	"KGS": 2,
+	"KRW": 0,
+	"KWD": 3,
+	"KZT": 2,
+	"OMR": 3,
+	"PLN": 2,
+	"RUB": 2,
+	"SEK": 2,
+	"TJS": 2,
+	"TRY": 2,
+	"UAH": 2,
+	"USD": 2,
+	"UZS": 2,
+	"VND": 0,
+}
+
+// CurrencyExponent returns the minor-unit exponent of an ISO 4217 code.
+func CurrencyExponent(code string) (int, bool) {
+	exp, ok := currencyExponents[code]
+	return exp, ok
+}
@@ -0,0 +1,21 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const EventBalanceChanged = "wallet.balance_changed"
+
+// BalanceChanged is the payload of a wallet.balance_changed event, emitted
+// for every ledger entry.
+type BalanceChanged struct {
+	WalletID      uuid.UUID `json:"walletId"`
+	TransactionID uuid.UUID `json:"transactionId"`
+	OperationType string    `json:"operationType"`
+	Amount        int64     `json:"amount"`
+	BalanceAfter  int64     `json:"balanceAfter"`
+	Currency      string    `json:"currency"`
+	OccurredAt    time.Time `json:"occurredAt"`
+}
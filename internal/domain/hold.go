@@ -0,0 +1,10 @@
+package domain
+
+// Hold lifecycle. Only an ACTIVE hold that has not reached its expiry
+// reserves funds.
+const (
+	HoldActive   string = "ACTIVE"
+	HoldCaptured string = "CAPTURED"
+	HoldVoided   string = "VOIDED"
+	HoldExpired  string = "EXPIRED"
+)
@@ -3,4 +3,60 @@ package domain
 const (
 	Deposit  string = "DEPOSIT"
 	Withdraw string = "WITHDRAW"
-)
\ No newline at end of file
+
+	// Ledger entries written by a transfer between two wallets.
+	TransferOut string = "TRANSFER_OUT"
+	TransferIn  string = "TRANSFER_IN"
+
+	// Two-phase operations: AUTHORIZE reserves funds, CAPTURE settles a hold
+	// and VOID releases it. Only CAPTURE changes the ledger balance.
+	Authorize string = "AUTHORIZE"
+	Capture   string = "CAPTURE"
+	Void      string = "VOID"
+
+	// Compensating entries written when a DEPOSIT or WITHDRAW is reversed,
+	// fully or in part.
+	DepositReversal  string = "DEPOSIT_REVERSAL"
+	WithdrawReversal string = "WITHDRAW_REVERSAL"
+
+	// Reverse names the reversal operation itself, whichever entry it
+	// writes.
+	Reverse string = "REVERSE"
+)
+
+// IsLedgerOperation reports whether op can appear in the transaction ledger.
+func IsLedgerOperation(op string) bool {
+	switch op {
+	case Deposit, Withdraw, TransferOut, TransferIn, Capture, DepositReversal, WithdrawReversal:
+		return true
+	default:
+		return false
+	}
+}
+
+// Batch modes: ALL_OR_NOTHING applies every operation of a batch or none,
+// BEST_EFFORT applies each one independently.
+const (
+	BatchAllOrNothing string = "ALL_OR_NOTHING"
+	BatchBestEffort   string = "BEST_EFFORT"
+)
+
+// Wallet lifecycle. Only ACTIVE wallets accept operations; CLOSED is final.
+const (
+	WalletActive string = "ACTIVE"
+	WalletFrozen string = "FROZEN"
+	WalletClosed string = "CLOSED"
+)
+
+// CanTransitionWallet reports whether a wallet may move from one status to
+// another.
+func CanTransitionWallet(from, to string) bool {
+	switch from {
+	case WalletActive:
+		return to == WalletFrozen || to == WalletClosed
+	case WalletFrozen:
+		return to == WalletActive || to == WalletClosed
+	default:
+		return false
+	}
+}
@@ -0,0 +1,14 @@
+package domain
+
+// Webhook delivery lifecycle. A PENDING delivery is retried with backoff
+// until it is DELIVERED or runs out of attempts and becomes DEAD.
+const (
+	DeliveryPending   string = "PENDING"
+	DeliveryDelivered string = "DELIVERED"
+	DeliveryDead      string = "DEAD"
+)
+
+// IsEventType reports whether webhooks can subscribe to t.
+func IsEventType(t string) bool {
+	return t == EventBalanceChanged
+}
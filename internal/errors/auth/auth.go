@@ -0,0 +1,13 @@
+package auth
+
+import "errors"
+
+var (
+	ErrUnauthenticated    = errors.New("missing or invalid credentials")
+	ErrForbidden          = errors.New("credentials lack the required scope")
+	ErrWalletAccessDenied = errors.New("credentials do not allow access to this wallet")
+
+	ErrAPIKeyNotFound = errors.New("api key not found")
+	ErrInvalidScope   = errors.New("unknown scope")
+	ErrInvalidBinding = errors.New("admin keys cannot be bound to wallets or an owner")
+)
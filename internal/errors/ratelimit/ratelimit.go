@@ -0,0 +1,5 @@
+package ratelimit
+
+import "errors"
+
+var ErrRateLimited = errors.New("too many requests, retry later")
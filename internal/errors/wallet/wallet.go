@@ -1,10 +1,67 @@
 package wallet
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+)
 
 var (
 	ErrNotEnoughFunds   = errors.New("not enough funds")
 	ErrWalletNotFound   = errors.New("wallet not found")
 	ErrInvalidOperation = errors.New("invalid operation type")
 	ErrInvalidAmount    = errors.New("amount must be positive")
+	ErrInvalidCursor    = errors.New("invalid cursor")
+	ErrInvalidFilter    = errors.New("invalid history filter")
+	ErrSameWallet       = errors.New("source and destination wallets must differ")
+	ErrInvalidCurrency  = errors.New("unknown currency code")
+	ErrCurrencyMismatch = errors.New("currency does not match the wallet currency")
+
+	ErrWalletFrozen            = errors.New("wallet is frozen")
+	ErrWalletClosed            = errors.New("wallet is closed")
+	ErrWalletNotEmpty          = errors.New("wallet has funds or active holds")
+	ErrInvalidStatus           = errors.New("invalid wallet status")
+	ErrInvalidStatusTransition = errors.New("wallet status transition not allowed")
+	ErrInvalidOwner            = errors.New("invalid owner id")
+	ErrInvalidMetadata         = errors.New("metadata must be a JSON object")
+
+	ErrHoldNotFound       = errors.New("hold not found")
+	ErrHoldNotActive      = errors.New("hold is not active")
+	ErrHoldExpired        = errors.New("hold has expired")
+	ErrInvalidHoldTTL     = errors.New("invalid hold expiry")
+	ErrCaptureExceedsHold = errors.New("capture amount exceeds the held amount")
+
+	ErrTransactionNotFound   = errors.New("transaction not found")
+	ErrNotReversible         = errors.New("transaction cannot be reversed")
+	ErrAlreadyReversed       = errors.New("transaction is already fully reversed")
+	ErrReversalExceedsAmount = errors.New("reversal amount exceeds the amount left to reverse")
+
+	ErrLimitExceeded = errors.New("operation exceeds wallet limits")
+	ErrInvalidLimits = errors.New("invalid wallet limits")
+
+	ErrMalformedRequest     = errors.New("malformed request body")
+	ErrInvalidWalletID      = errors.New("invalid wallet id")
+	ErrInvalidHoldID        = errors.New("invalid hold id")
+	ErrInvalidTransactionID = errors.New("invalid transaction id")
+
+	ErrInvalidIdempotencyKey = errors.New("invalid idempotency key")
+	ErrIdempotencyKeyReused  = errors.New("idempotency key reused with a different request")
+
+	ErrInvalidBatchMode = errors.New("invalid batch mode")
+	ErrEmptyBatch       = errors.New("batch has no operations")
+	ErrBatchTooLarge    = errors.New("batch has too many operations")
 )
+
+// BatchItemError is returned when an all-or-nothing batch is rolled back
+// because of the operation at Index.
+type BatchItemError struct {
+	Index int
+	Err   error
+}
+
+func (e *BatchItemError) Error() string {
+	return fmt.Sprintf("batch operation %d: %v", e.Index, e.Err)
+}
+
+func (e *BatchItemError) Unwrap() error {
+	return e.Err
+}
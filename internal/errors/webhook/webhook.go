@@ -0,0 +1,15 @@
+package webhook
+
+import "errors"
+
+var (
+	ErrSubscriptionNotFound  = errors.New("webhook subscription not found")
+	ErrInvalidURL            = errors.New("webhook url must be an absolute http or https url")
+	ErrInvalidEventType      = errors.New("unknown event type")
+	ErrDeliveryNotFound      = errors.New("webhook delivery not found")
+	ErrInvalidDeliveryFilter = errors.New("invalid delivery filter")
+	ErrDeliveryNotDead       = errors.New("only dead deliveries can be replayed")
+	ErrInvalidDeliveryID     = errors.New("invalid delivery id")
+	ErrInvalidSubscriptionID = errors.New("invalid subscription id")
+	ErrLeaseLost             = errors.New("webhook delivery was claimed again")
+)
@@ -0,0 +1,132 @@
+// Package groupcommit coalesces concurrent operations on the same key into
+// groups that are executed together, typically in one database transaction.
+package groupcommit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Result is the outcome of one operation of a group.
+type Result[R any] struct {
+	Value R
+	Err   error
+}
+
+// FlushFunc executes a group of operations on key and returns one result per
+// operation, in order. ctx is not cancelled when the callers give up: once
+// started, a group always runs to completion.
+type FlushFunc[K comparable, Op, R any] func(ctx context.Context, key K, ops []Op) []Result[R]
+
+type group[Op, R any] struct {
+	ops []Op
+	// withdrawn marks the ops whose callers gave up before the group was
+	// closed; they are left out of the flush.
+	withdrawn []bool
+	results   []Result[R]
+	// full is closed when the group reaches the size limit and must not
+	// wait for the window to end.
+	full chan struct{}
+	done chan struct{}
+}
+
+// Batcher groups the operations on a key that arrive within window of the
+// first one, or until maxOps have arrived, and flushes them together. The
+// caller that opens a group waits for the window and runs the flush; the
+// others wait for its results.
+type Batcher[K comparable, Op, R any] struct {
+	window time.Duration
+	maxOps int
+	flush  FlushFunc[K, Op, R]
+
+	mu     sync.Mutex
+	groups map[K]*group[Op, R]
+}
+
+func New[K comparable, Op, R any](window time.Duration, maxOps int, flush FlushFunc[K, Op, R]) *Batcher[K, Op, R] {
+	return &Batcher[K, Op, R]{
+		window: window,
+		maxOps: maxOps,
+		flush:  flush,
+		groups: make(map[K]*group[Op, R]),
+	}
+}
+
+// Do adds op to the open group of key, or opens one, and returns its result.
+// If ctx is done while the joined group is still open, op is withdrawn from
+// it and Do returns ctx.Err(). Once the group is closed op is applied with
+// it, so Do waits for its result instead.
+func (b *Batcher[K, Op, R]) Do(ctx context.Context, key K, op Op) (R, error) {
+	b.mu.Lock()
+	g, ok := b.groups[key]
+	if !ok {
+		g = &group[Op, R]{full: make(chan struct{}), done: make(chan struct{})}
+		b.groups[key] = g
+	}
+	i := len(g.ops)
+	g.ops = append(g.ops, op)
+	g.withdrawn = append(g.withdrawn, false)
+	if len(g.ops) >= b.maxOps {
+		delete(b.groups, key)
+		close(g.full)
+	}
+	b.mu.Unlock()
+
+	if !ok {
+		b.lead(ctx, key, g)
+		return g.results[i].Value, g.results[i].Err
+	}
+
+	select {
+	case <-g.done:
+	case <-ctx.Done():
+		b.mu.Lock()
+		open := b.groups[key] == g
+		if open {
+			g.withdrawn[i] = true
+		}
+		b.mu.Unlock()
+
+		if open {
+			var zero R
+			return zero, ctx.Err()
+		}
+		<-g.done
+	}
+	return g.results[i].Value, g.results[i].Err
+}
+
+// lead closes the group when the window ends or it is full and flushes it.
+// It does not watch ctx: the other callers depend on the flush.
+func (b *Batcher[K, Op, R]) lead(ctx context.Context, key K, g *group[Op, R]) {
+	timer := time.NewTimer(b.window)
+	select {
+	case <-timer.C:
+	case <-g.full:
+		timer.Stop()
+	}
+
+	b.mu.Lock()
+	if b.groups[key] == g {
+		delete(b.groups, key)
+	}
+	b.mu.Unlock()
+
+	// The group is no longer reachable, so it is safe to read unlocked.
+	ops := make([]Op, 0, len(g.ops))
+	for i, op := range g.ops {
+		if !g.withdrawn[i] {
+			ops = append(ops, op)
+		}
+	}
+	results := b.flush(context.WithoutCancel(ctx), key, ops)
+
+	g.results = make([]Result[R], len(g.ops))
+	for i := range g.ops {
+		if !g.withdrawn[i] {
+			g.results[i], results = results[0], results[1:]
+		}
+	}
+	close(g.done)
+}
@@ -0,0 +1,188 @@
+package groupcommit_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/totorialman/go-test-ac/internal/groupcommit"
+)
+
+var errOdd = errors.New("odd")
+
+// recorder flushes groups of ints by returning a running sum per key and
+// failing odd operations, and remembers every group it saw.
+type recorder struct {
+	mu     sync.Mutex
+	groups map[string][][]int
+}
+
+func newRecorder() *recorder {
+	return &recorder{groups: make(map[string][][]int)}
+}
+
+func (r *recorder) flush(_ context.Context, key string, ops []int) []groupcommit.Result[int] {
+	r.mu.Lock()
+	r.groups[key] = append(r.groups[key], ops)
+	r.mu.Unlock()
+
+	results := make([]groupcommit.Result[int], len(ops))
+	sum := 0
+	for i, op := range ops {
+		if op%2 != 0 {
+			results[i].Err = fmt.Errorf("op %d: %w", op, errOdd)
+			continue
+		}
+		sum += op
+		results[i].Value = sum
+	}
+	return results
+}
+
+func TestBatcher_GroupsConcurrentOperations(t *testing.T) {
+	rec := newRecorder()
+	b := groupcommit.New(50*time.Millisecond, 100, rec.flush)
+
+	type outcome struct {
+		value int
+		err   error
+	}
+	ops := []int{2, 4, 5, 6}
+	outcomes := make([]outcome, len(ops))
+	var wg sync.WaitGroup
+	for i, op := range ops {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			v, err := b.Do(context.Background(), "a", op)
+			outcomes[i] = outcome{v, err}
+		}()
+	}
+	wg.Wait()
+
+	require.Len(t, rec.groups["a"], 1, "all operations arrived within the window")
+	assert.ElementsMatch(t, ops, rec.groups["a"][0])
+
+	// Every caller gets the result of its own operation.
+	sum := 0
+	for _, op := range rec.groups["a"][0] {
+		i := indexOf(ops, op)
+		if op%2 != 0 {
+			assert.ErrorIs(t, outcomes[i].err, errOdd)
+			continue
+		}
+		sum += op
+		assert.NoError(t, outcomes[i].err)
+		assert.Equal(t, sum, outcomes[i].value)
+	}
+}
+
+func TestBatcher_KeysAreIndependent(t *testing.T) {
+	rec := newRecorder()
+	b := groupcommit.New(20*time.Millisecond, 100, rec.flush)
+
+	var wg sync.WaitGroup
+	for _, key := range []string{"a", "b"} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			v, err := b.Do(context.Background(), key, 2)
+			assert.NoError(t, err)
+			assert.Equal(t, 2, v)
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, [][]int{{2}}, rec.groups["a"])
+	assert.Equal(t, [][]int{{2}}, rec.groups["b"])
+}
+
+func TestBatcher_FlushesFullGroupEarly(t *testing.T) {
+	rec := newRecorder()
+	b := groupcommit.New(time.Hour, 2, rec.flush)
+
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := b.Do(context.Background(), "a", 2)
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, [][]int{{2, 2}, {2, 2}}, rec.groups["a"])
+}
+
+func TestBatcher_CallerGivesUpBeforeFlush(t *testing.T) {
+	rec := newRecorder()
+	b := groupcommit.New(100*time.Millisecond, 100, rec.flush)
+
+	leader := make(chan int)
+	go func() {
+		v, _ := b.Do(context.Background(), "a", 2)
+		leader <- v
+	}()
+	time.Sleep(20 * time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := b.Do(ctx, "a", 4)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, 2, <-leader)
+	assert.Equal(t, [][]int{{2}}, rec.groups["a"], "the operation is withdrawn from the group")
+}
+
+func TestBatcher_CallerGivesUpDuringFlush(t *testing.T) {
+	flushing := make(chan struct{})
+	release := make(chan struct{})
+	rec := newRecorder()
+	b := groupcommit.New(100*time.Millisecond, 2, func(ctx context.Context, key string, ops []int) []groupcommit.Result[int] {
+		close(flushing)
+		<-release
+		return rec.flush(ctx, key, ops)
+	})
+
+	leader := make(chan int)
+	go func() {
+		v, _ := b.Do(context.Background(), "a", 2)
+		leader <- v
+	}()
+	time.Sleep(20 * time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	follower := make(chan groupcommit.Result[int])
+	go func() {
+		// The group is full with this operation and starts flushing.
+		v, err := b.Do(ctx, "a", 4)
+		follower <- groupcommit.Result[int]{Value: v, Err: err}
+	}()
+
+	<-flushing
+	cancel()
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+
+	res := <-follower
+	require.NoError(t, res.Err, "the committed operation is not reported as cancelled")
+	assert.Equal(t, 6, res.Value)
+	assert.Equal(t, 2, <-leader)
+	assert.Equal(t, [][]int{{2, 4}}, rec.groups["a"])
+}
+
+func indexOf(ops []int, op int) int {
+	for i, o := range ops {
+		if o == op {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,74 @@
+package interceptor
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/totorialman/go-test-ac/internal/auth"
+	authErrors "github.com/totorialman/go-test-ac/internal/errors/auth"
+	"github.com/totorialman/go-test-ac/internal/grpc/rpcerror"
+	"github.com/totorialman/go-test-ac/internal/handler/response"
+)
+
+var apiKeyKey = strings.ToLower(auth.APIKeyHeader)
+
+// AuthUnary is the gRPC counterpart of auth.Middleware: calls without valid
+// credentials fail with Unauthenticated, the others carry the Principal in
+// their context. The credential is read from the x-api-key or
+// "authorization: Bearer" metadata. It must run after RequestIDUnary.
+func AuthUnary(authn auth.Authenticator, logger *slog.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, err := authenticate(ctx, authn, logger)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+func AuthStream(authn auth.Authenticator, logger *slog.Logger) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := authenticate(ss.Context(), authn, logger)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+func authenticate(ctx context.Context, authn auth.Authenticator, logger *slog.Logger) (context.Context, error) {
+	credential := credentialFromMetadata(ctx)
+	if credential == "" {
+		logger.WarnContext(ctx, "missing credentials")
+		return nil, rpcerror.Status(ctx, authErrors.ErrUnauthenticated)
+	}
+
+	p, err := authn.Authenticate(ctx, credential)
+	if err != nil {
+		logger.Log(ctx, response.LogLevel(err), "authentication error", "error", err)
+		return nil, rpcerror.Status(ctx, err)
+	}
+
+	return auth.WithPrincipal(ctx, p), nil
+}
+
+func credentialFromMetadata(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+	if v := md.Get(apiKeyKey); len(v) > 0 && v[0] != "" {
+		return v[0]
+	}
+	if v := md.Get("authorization"); len(v) > 0 {
+		scheme, token, ok := strings.Cut(v[0], " ")
+		if ok && strings.EqualFold(scheme, "Bearer") {
+			return strings.TrimSpace(token)
+		}
+	}
+	return ""
+}
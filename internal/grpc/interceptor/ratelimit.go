@@ -0,0 +1,88 @@
+package interceptor
+
+import (
+	"context"
+	"net"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/peer"
+
+	"github.com/totorialman/go-test-ac/internal/auth"
+	ratelimitErrors "github.com/totorialman/go-test-ac/internal/errors/ratelimit"
+	"github.com/totorialman/go-test-ac/internal/grpc/rpcerror"
+)
+
+// rateLimiter is satisfied by *ratelimit.Limiter.
+type rateLimiter interface {
+	Allow(ctx context.Context, route, client string, walletIDs ...uuid.UUID) (time.Duration, bool)
+}
+
+// RateLimitUnary is the gRPC counterpart of ratelimit.Limiter.Middleware and
+// takes from the same buckets. The route is the full method name, the client
+// the authenticated principal and the wallet the wallet_id of the request.
+// Rejected calls fail with ResourceExhausted and a RetryInfo detail. It must
+// run after AuthUnary.
+func RateLimitUnary(limiter rateLimiter) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if err := rateLimit(ctx, limiter, info.FullMethod, req); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// RateLimitStream charges a stream when its first request message arrives,
+// since that is where the wallet id is.
+func RateLimitStream(limiter rateLimiter) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &rateLimitedStream{ServerStream: ss, limiter: limiter, method: info.FullMethod})
+	}
+}
+
+type rateLimitedStream struct {
+	grpc.ServerStream
+	limiter rateLimiter
+	method  string
+	charged bool
+}
+
+func (s *rateLimitedStream) RecvMsg(m any) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	if s.charged {
+		return nil
+	}
+	s.charged = true
+	return rateLimit(s.Context(), s.limiter, s.method, m)
+}
+
+func rateLimit(ctx context.Context, limiter rateLimiter, method string, req any) error {
+	var walletIDs []uuid.UUID
+	if r, ok := req.(interface{ GetWalletId() string }); ok {
+		if id, err := uuid.Parse(r.GetWalletId()); err == nil {
+			walletIDs = append(walletIDs, id)
+		}
+	}
+
+	retryAfter, ok := limiter.Allow(ctx, method, clientID(ctx), walletIDs...)
+	if !ok {
+		return rpcerror.RetryStatus(ctx, ratelimitErrors.ErrRateLimited, retryAfter)
+	}
+	return nil
+}
+
+func clientID(ctx context.Context) string {
+	if p, ok := auth.FromContext(ctx); ok {
+		return p.ID
+	}
+	if p, ok := peer.FromContext(ctx); ok {
+		if host, _, err := net.SplitHostPort(p.Addr.String()); err == nil {
+			return host
+		}
+		return p.Addr.String()
+	}
+	return ""
+}
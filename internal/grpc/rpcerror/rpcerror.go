@@ -0,0 +1,74 @@
+// Package rpcerror converts service errors into gRPC statuses for the
+// servers and interceptors of the gRPC API.
+package rpcerror
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	"github.com/totorialman/go-test-ac/internal/handler/response"
+	"github.com/totorialman/go-test-ac/internal/requestid"
+)
+
+// errorDomain identifies the service in google.rpc.ErrorInfo details.
+const errorDomain = "wallet"
+
+// Status converts err into a gRPC status. The sentinel classification is
+// the one used for HTTP responses, so both APIs report the same codes; the
+// machine error code, such as NOT_ENOUGH_FUNDS, is carried as the ErrorInfo
+// reason.
+func Status(ctx context.Context, err error) error {
+	return newStatus(ctx, err)
+}
+
+// RetryStatus is Status with a google.rpc.RetryInfo detail that tells the
+// client how long to wait before retrying, the gRPC counterpart of the HTTP
+// Retry-After header.
+func RetryStatus(ctx context.Context, err error, retryAfter time.Duration) error {
+	return newStatus(ctx, err, &errdetails.RetryInfo{RetryDelay: durationpb.New(retryAfter)})
+}
+
+func newStatus(ctx context.Context, err error, details ...protoadapt.MessageV1) error {
+	httpStatus, code, message := response.Classify(err)
+
+	st := status.New(grpcCode(httpStatus), message)
+	details = append([]protoadapt.MessageV1{&errdetails.ErrorInfo{
+		Reason:   code,
+		Domain:   errorDomain,
+		Metadata: map[string]string{"requestId": requestid.FromContext(ctx)},
+	}}, details...)
+	withDetails, detailErr := st.WithDetails(details...)
+	if detailErr != nil {
+		return st.Err()
+	}
+	return withDetails.Err()
+}
+
+func grpcCode(httpStatus int) codes.Code {
+	switch httpStatus {
+	case http.StatusBadRequest:
+		return codes.InvalidArgument
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusNotFound:
+		return codes.NotFound
+	case http.StatusTooManyRequests:
+		return codes.ResourceExhausted
+	case http.StatusConflict, http.StatusGone, http.StatusLocked,
+		http.StatusUnprocessableEntity:
+		// The request is well-formed but the wallet is not in a state
+		// that allows it.
+		return codes.FailedPrecondition
+	default:
+		return codes.Internal
+	}
+}
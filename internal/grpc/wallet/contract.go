@@ -0,0 +1,21 @@
+//go:generate mockgen -source=contract.go -destination=wallet_usecase_mocks_test.go -package=wallet_test
+package wallet
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/totorialman/go-test-ac/internal/auth"
+	"github.com/totorialman/go-test-ac/internal/usecase/wallet"
+)
+
+type authorizer interface {
+	Authorize(ctx context.Context, walletID uuid.UUID, scope auth.Scope) error
+}
+
+type usecase interface {
+	Operate(ctx context.Context, w wallet.Wallet) (wallet.Balance, error)
+	Get(ctx context.Context, id uuid.UUID) (wallet.WalletInfo, error)
+	History(ctx context.Context, f wallet.HistoryFilter) (wallet.HistoryPage, error)
+}
@@ -0,0 +1,46 @@
+package wallet
+
+import (
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	walletv1 "github.com/totorialman/go-test-ac/api/wallet/v1"
+	"github.com/totorialman/go-test-ac/internal/usecase/wallet"
+)
+
+func toProtoBalance(b wallet.Balance) *walletv1.Balance {
+	return &walletv1.Balance{
+		Ledger:    b.Amount,
+		Available: b.Available,
+		Currency:  b.Currency,
+		Exponent:  int32(b.Exponent),
+	}
+}
+
+func toProtoWallet(info wallet.WalletInfo) *walletv1.BalanceResponse {
+	return &walletv1.BalanceResponse{
+		WalletId:  info.ID.String(),
+		OwnerId:   info.OwnerID,
+		Status:    info.Status,
+		Balance:   toProtoBalance(info.Balance),
+		CreatedAt: timestamppb.New(info.CreatedAt),
+		UpdatedAt: timestamppb.New(info.UpdatedAt),
+	}
+}
+
+func toProtoTransaction(t wallet.Transaction) *walletv1.Transaction {
+	res := &walletv1.Transaction{
+		Id:            t.ID.String(),
+		WalletId:      t.WalletID.String(),
+		OperationType: t.OperationType,
+		Amount:        t.Amount,
+		BalanceAfter:  t.BalanceAfter,
+		CreatedAt:     timestamppb.New(t.CreatedAt),
+	}
+	if t.CounterpartyID.Valid {
+		res.CounterpartyWalletId = t.CounterpartyID.UUID.String()
+	}
+	if t.ReversalOf.Valid {
+		res.ReversalOf = t.ReversalOf.UUID.String()
+	}
+	return res
+}
@@ -0,0 +1,142 @@
+// Package wallet serves the WalletService gRPC API on top of the same
+// usecase as the HTTP handlers.
+package wallet
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/google/uuid"
+
+	walletv1 "github.com/totorialman/go-test-ac/api/wallet/v1"
+	"github.com/totorialman/go-test-ac/internal/auth"
+	"github.com/totorialman/go-test-ac/internal/domain"
+	walletErrors "github.com/totorialman/go-test-ac/internal/errors/wallet"
+	"github.com/totorialman/go-test-ac/internal/grpc/rpcerror"
+	"github.com/totorialman/go-test-ac/internal/handler/response"
+	"github.com/totorialman/go-test-ac/internal/usecase/wallet"
+)
+
+type Server struct {
+	walletv1.UnimplementedWalletServiceServer
+
+	usecase    usecase
+	authorizer authorizer
+	logger     *slog.Logger
+}
+
+func NewServer(usecase usecase, authorizer authorizer, logger *slog.Logger) *Server {
+	return &Server{usecase: usecase, authorizer: authorizer, logger: logger}
+}
+
+func (s *Server) Operate(ctx context.Context, req *walletv1.OperateRequest) (*walletv1.OperateResponse, error) {
+	id, err := uuid.Parse(req.GetWalletId())
+	if err != nil {
+		return nil, rpcerror.Status(ctx, walletErrors.ErrInvalidWalletID)
+	}
+	if req.GetAmount() <= 0 {
+		return nil, rpcerror.Status(ctx, walletErrors.ErrInvalidAmount)
+	}
+	if req.GetOperationType() != domain.Deposit && req.GetOperationType() != domain.Withdraw {
+		return nil, rpcerror.Status(ctx, walletErrors.ErrInvalidOperation)
+	}
+
+	scope := auth.ScopeDeposit
+	if req.GetOperationType() == domain.Withdraw {
+		scope = auth.ScopeWithdraw
+	}
+	if err := s.authorizer.Authorize(ctx, id, scope); err != nil {
+		s.logger.Log(ctx, response.LogLevel(err), "grpc operate not authorized", "error", err)
+		return nil, rpcerror.Status(ctx, err)
+	}
+
+	balance, err := s.usecase.Operate(ctx, wallet.Wallet{
+		ID:             id,
+		OperationType:  req.GetOperationType(),
+		Amount:         req.GetAmount(),
+		Currency:       req.GetCurrency(),
+		IdempotencyKey: req.GetIdempotencyKey(),
+	})
+	if err != nil {
+		s.logger.Log(ctx, response.LogLevel(err), "grpc operate error", "error", err)
+		return nil, rpcerror.Status(ctx, err)
+	}
+
+	return &walletv1.OperateResponse{
+		WalletId: id.String(),
+		Balance:  toProtoBalance(balance),
+	}, nil
+}
+
+func (s *Server) Balance(ctx context.Context, req *walletv1.BalanceRequest) (*walletv1.BalanceResponse, error) {
+	id, err := uuid.Parse(req.GetWalletId())
+	if err != nil {
+		return nil, rpcerror.Status(ctx, walletErrors.ErrInvalidWalletID)
+	}
+
+	if err := s.authorizer.Authorize(ctx, id, auth.ScopeBalanceRead); err != nil {
+		s.logger.Log(ctx, response.LogLevel(err), "grpc balance not authorized", "error", err)
+		return nil, rpcerror.Status(ctx, err)
+	}
+
+	info, err := s.usecase.Get(ctx, id)
+	if err != nil {
+		s.logger.Log(ctx, response.LogLevel(err), "grpc balance error", "error", err)
+		return nil, rpcerror.Status(ctx, err)
+	}
+
+	return toProtoWallet(info), nil
+}
+
+// History walks the usecase pages itself so the client sees a single stream
+// instead of cursors.
+func (s *Server) History(req *walletv1.HistoryRequest, stream walletv1.WalletService_HistoryServer) error {
+	ctx := stream.Context()
+
+	id, err := uuid.Parse(req.GetWalletId())
+	if err != nil {
+		return rpcerror.Status(ctx, walletErrors.ErrInvalidWalletID)
+	}
+
+	if err := s.authorizer.Authorize(ctx, id, auth.ScopeBalanceRead); err != nil {
+		s.logger.Log(ctx, response.LogLevel(err), "grpc history not authorized", "error", err)
+		return rpcerror.Status(ctx, err)
+	}
+
+	f := wallet.HistoryFilter{
+		WalletID:      id,
+		OperationType: req.GetOperationType(),
+		Limit:         wallet.MaxHistoryLimit,
+	}
+	if req.From != nil {
+		if err := req.From.CheckValid(); err != nil {
+			return rpcerror.Status(ctx, walletErrors.ErrInvalidFilter)
+		}
+		f.From = req.From.AsTime()
+	}
+	if req.To != nil {
+		if err := req.To.CheckValid(); err != nil {
+			return rpcerror.Status(ctx, walletErrors.ErrInvalidFilter)
+		}
+		f.To = req.To.AsTime()
+	}
+
+	for {
+		page, err := s.usecase.History(ctx, f)
+		if err != nil {
+			s.logger.Log(ctx, response.LogLevel(err), "grpc history error", "error", err)
+			return rpcerror.Status(ctx, err)
+		}
+
+		for _, t := range page.Transactions {
+			if err := stream.Send(toProtoTransaction(t)); err != nil {
+				return err
+			}
+		}
+
+		if page.NextCursor == "" {
+			return nil
+		}
+		f.Cursor = page.NextCursor
+	}
+}
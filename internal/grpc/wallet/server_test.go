@@ -0,0 +1,469 @@
+package wallet_test
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	walletv1 "github.com/totorialman/go-test-ac/api/wallet/v1"
+	"github.com/totorialman/go-test-ac/internal/auth"
+	"github.com/totorialman/go-test-ac/internal/domain"
+	authErrors "github.com/totorialman/go-test-ac/internal/errors/auth"
+	ratelimitErrors "github.com/totorialman/go-test-ac/internal/errors/ratelimit"
+	walletErrors "github.com/totorialman/go-test-ac/internal/errors/wallet"
+	"github.com/totorialman/go-test-ac/internal/grpc/interceptor"
+	"github.com/totorialman/go-test-ac/internal/grpc/wallet"
+	"github.com/totorialman/go-test-ac/internal/logging"
+	"github.com/totorialman/go-test-ac/internal/ratelimit"
+	walletUsecase "github.com/totorialman/go-test-ac/internal/usecase/wallet"
+)
+
+const (
+	adminKey = "wk_admin"
+	aliceKey = "wk_alice"
+)
+
+// staticKeys authenticates the keys of the tests.
+type staticKeys map[string]auth.Principal
+
+func (k staticKeys) Authenticate(_ context.Context, key string) (auth.Principal, error) {
+	p, ok := k[key]
+	if !ok {
+		return auth.Principal{}, authErrors.ErrUnauthenticated
+	}
+	return p, nil
+}
+
+// owners is the wallet owner lookup behind the authorizer.
+type owners map[uuid.UUID]string
+
+func (o owners) WalletOwner(_ context.Context, walletID uuid.UUID) (string, error) {
+	owner, ok := o[walletID]
+	if !ok {
+		return "", walletErrors.ErrWalletNotFound
+	}
+	return owner, nil
+}
+
+var aliceWallet = uuid.New()
+
+// newClient serves the wallet API with the interceptors of cmd/main.go and
+// returns a client whose calls present credential, unless it is empty.
+// extra is appended to the server options, e.g. to chain more interceptors.
+func newClient(t *testing.T, uc *Mockusecase, credential string, extra ...grpc.ServerOption) walletv1.WalletServiceClient {
+	t.Helper()
+
+	authn := staticKeys{
+		adminKey: {ID: "admin", Scopes: []auth.Scope{auth.ScopeAdmin}},
+		aliceKey: {ID: "alice", Scopes: []auth.Scope{auth.ScopeBalanceRead, auth.ScopeDeposit, auth.ScopeWithdraw}, OwnerID: "alice"},
+	}
+	authorizer := auth.NewAuthorizer(owners{aliceWallet: "alice"})
+
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer(append([]grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(
+			interceptor.RequestIDUnary,
+			interceptor.LoggingUnary(logging.Discard()),
+			interceptor.AuthUnary(authn, logging.Discard()),
+		),
+		grpc.ChainStreamInterceptor(
+			interceptor.RequestIDStream,
+			interceptor.LoggingStream(logging.Discard()),
+			interceptor.AuthStream(authn, logging.Discard()),
+		),
+	}, extra...)...)
+	walletv1.RegisterWalletServiceServer(srv, wallet.NewServer(uc, authorizer, logging.Discard()))
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(srv.Stop)
+
+	opts := []grpc.DialOption{
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	}
+	if credential != "" {
+		opts = append(opts,
+			grpc.WithChainUnaryInterceptor(func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+				return invoker(metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+credential), method, req, reply, cc, opts...)
+			}),
+			grpc.WithChainStreamInterceptor(func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+				return streamer(metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+credential), desc, cc, method, opts...)
+			}),
+		)
+	}
+
+	conn, err := grpc.NewClient("passthrough:///bufnet", opts...)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return walletv1.NewWalletServiceClient(conn)
+}
+
+func errorReason(t *testing.T, err error) string {
+	t.Helper()
+
+	for _, d := range status.Convert(err).Details() {
+		if info, ok := d.(*errdetails.ErrorInfo); ok {
+			return info.Reason
+		}
+	}
+	return ""
+}
+
+func TestServer_Operate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUsecase := NewMockusecase(ctrl)
+	client := newClient(t, mockUsecase, adminKey)
+
+	walletID := uuid.New()
+
+	tests := []struct {
+		name           string
+		req            *walletv1.OperateRequest
+		mockReturn     func()
+		expectedCode   codes.Code
+		expectedErr    string
+		expectedLedger int64
+	}{
+		{
+			name: "deposit",
+			req: &walletv1.OperateRequest{
+				WalletId:       walletID.String(),
+				OperationType:  domain.Deposit,
+				Amount:         1000,
+				Currency:       "RUB",
+				IdempotencyKey: "key-1",
+			},
+			mockReturn: func() {
+				mockUsecase.EXPECT().
+					Operate(gomock.Any(), walletUsecase.Wallet{
+						ID:             walletID,
+						OperationType:  domain.Deposit,
+						Amount:         1000,
+						Currency:       "RUB",
+						IdempotencyKey: "key-1",
+					}).
+					Return(walletUsecase.Balance{Amount: 1000, Available: 1000, Currency: "RUB", Exponent: 2}, nil)
+			},
+			expectedCode:   codes.OK,
+			expectedLedger: 1000,
+		},
+		{
+			name: "not enough funds",
+			req:  &walletv1.OperateRequest{WalletId: walletID.String(), OperationType: domain.Withdraw, Amount: 1000},
+			mockReturn: func() {
+				mockUsecase.EXPECT().
+					Operate(gomock.Any(), gomock.Any()).
+					Return(walletUsecase.Balance{}, walletErrors.ErrNotEnoughFunds)
+			},
+			expectedCode: codes.FailedPrecondition,
+			expectedErr:  "NOT_ENOUGH_FUNDS",
+		},
+		{
+			name: "wallet not found",
+			req:  &walletv1.OperateRequest{WalletId: walletID.String(), OperationType: domain.Withdraw, Amount: 1000},
+			mockReturn: func() {
+				mockUsecase.EXPECT().
+					Operate(gomock.Any(), gomock.Any()).
+					Return(walletUsecase.Balance{}, walletErrors.ErrWalletNotFound)
+			},
+			expectedCode: codes.NotFound,
+			expectedErr:  "WALLET_NOT_FOUND",
+		},
+		{
+			name: "limit exceeded",
+			req:  &walletv1.OperateRequest{WalletId: walletID.String(), OperationType: domain.Withdraw, Amount: 1000},
+			mockReturn: func() {
+				mockUsecase.EXPECT().
+					Operate(gomock.Any(), gomock.Any()).
+					Return(walletUsecase.Balance{}, walletErrors.ErrLimitExceeded)
+			},
+			expectedCode: codes.FailedPrecondition,
+			expectedErr:  "LIMIT_EXCEEDED",
+		},
+		{
+			name: "rate limited",
+			req:  &walletv1.OperateRequest{WalletId: walletID.String(), OperationType: domain.Withdraw, Amount: 1000},
+			mockReturn: func() {
+				mockUsecase.EXPECT().
+					Operate(gomock.Any(), gomock.Any()).
+					Return(walletUsecase.Balance{}, ratelimitErrors.ErrRateLimited)
+			},
+			expectedCode: codes.ResourceExhausted,
+			expectedErr:  "RATE_LIMITED",
+		},
+		{
+			name:         "invalid wallet id",
+			req:          &walletv1.OperateRequest{WalletId: "invalid", OperationType: domain.Deposit, Amount: 1000},
+			mockReturn:   func() {},
+			expectedCode: codes.InvalidArgument,
+			expectedErr:  "INVALID_WALLET_ID",
+		},
+		{
+			name:         "invalid operation",
+			req:          &walletv1.OperateRequest{WalletId: walletID.String(), OperationType: "TRANSFER", Amount: 1000},
+			mockReturn:   func() {},
+			expectedCode: codes.InvalidArgument,
+			expectedErr:  "INVALID_OPERATION",
+		},
+		{
+			name:         "invalid amount",
+			req:          &walletv1.OperateRequest{WalletId: walletID.String(), OperationType: domain.Deposit},
+			mockReturn:   func() {},
+			expectedCode: codes.InvalidArgument,
+			expectedErr:  "INVALID_AMOUNT",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockReturn()
+
+			res, err := client.Operate(context.Background(), tt.req)
+
+			assert.Equal(t, tt.expectedCode, status.Code(err))
+			if tt.expectedCode != codes.OK {
+				assert.Equal(t, tt.expectedErr, errorReason(t, err))
+				return
+			}
+			assert.Equal(t, walletID.String(), res.GetWalletId())
+			assert.Equal(t, tt.expectedLedger, res.GetBalance().GetLedger())
+		})
+	}
+}
+
+func TestServer_BalanceInternalError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUsecase := NewMockusecase(ctrl)
+	client := newClient(t, mockUsecase, adminKey)
+
+	walletID := uuid.New()
+	mockUsecase.EXPECT().
+		Get(gomock.Any(), walletID).
+		Return(walletUsecase.WalletInfo{}, io.ErrUnexpectedEOF)
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "x-request-id", "caller-7")
+	var header metadata.MD
+	_, err := client.Balance(ctx, &walletv1.BalanceRequest{WalletId: walletID.String()}, grpc.Header(&header))
+
+	st := status.Convert(err)
+	assert.Equal(t, codes.Internal, st.Code())
+	assert.Equal(t, "internal server error", st.Message())
+	assert.Equal(t, "INTERNAL_ERROR", errorReason(t, err))
+	assert.Equal(t, []string{"caller-7"}, header.Get("x-request-id"))
+}
+
+func TestServer_History(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUsecase := NewMockusecase(ctrl)
+	client := newClient(t, mockUsecase, adminKey)
+
+	walletID := uuid.New()
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	first, second := uuid.New(), uuid.New()
+
+	gomock.InOrder(
+		mockUsecase.EXPECT().
+			History(gomock.Any(), walletUsecase.HistoryFilter{
+				WalletID: walletID,
+				From:     from,
+				Limit:    walletUsecase.MaxHistoryLimit,
+			}).
+			Return(walletUsecase.HistoryPage{
+				Transactions: []walletUsecase.Transaction{{ID: first, WalletID: walletID, OperationType: domain.Deposit, Amount: 100}},
+				NextCursor:   "next",
+			}, nil),
+		mockUsecase.EXPECT().
+			History(gomock.Any(), walletUsecase.HistoryFilter{
+				WalletID: walletID,
+				From:     from,
+				Cursor:   "next",
+				Limit:    walletUsecase.MaxHistoryLimit,
+			}).
+			Return(walletUsecase.HistoryPage{
+				Transactions: []walletUsecase.Transaction{{ID: second, WalletID: walletID, OperationType: domain.Withdraw, Amount: 50}},
+			}, nil),
+	)
+
+	stream, err := client.History(context.Background(), &walletv1.HistoryRequest{
+		WalletId: walletID.String(),
+		From:     timestamppb.New(from),
+	})
+	require.NoError(t, err)
+
+	var ids []string
+	for {
+		tx, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		ids = append(ids, tx.GetId())
+	}
+
+	assert.Equal(t, []string{first.String(), second.String()}, ids)
+}
+
+func TestServer_HistoryError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUsecase := NewMockusecase(ctrl)
+	client := newClient(t, mockUsecase, adminKey)
+
+	mockUsecase.EXPECT().
+		History(gomock.Any(), gomock.Any()).
+		Return(walletUsecase.HistoryPage{}, walletErrors.ErrInvalidOperation)
+
+	stream, err := client.History(context.Background(), &walletv1.HistoryRequest{
+		WalletId:      uuid.NewString(),
+		OperationType: "UNKNOWN",
+	})
+	require.NoError(t, err)
+
+	_, err = stream.Recv()
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.Equal(t, "INVALID_OPERATION", errorReason(t, err))
+}
+
+func TestServer_RequiresCredentials(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// The usecase must not be reached.
+	mockUsecase := NewMockusecase(ctrl)
+
+	for _, credential := range []string{"", "wk_unknown"} {
+		client := newClient(t, mockUsecase, credential)
+
+		_, err := client.Operate(context.Background(), &walletv1.OperateRequest{
+			WalletId:      aliceWallet.String(),
+			OperationType: domain.Deposit,
+			Amount:        100,
+		})
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+		assert.Equal(t, "UNAUTHENTICATED", errorReason(t, err))
+
+		_, err = client.Balance(context.Background(), &walletv1.BalanceRequest{WalletId: aliceWallet.String()})
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+		stream, err := client.History(context.Background(), &walletv1.HistoryRequest{WalletId: aliceWallet.String()})
+		require.NoError(t, err)
+		_, err = stream.Recv()
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	}
+}
+
+func TestServer_OwnerRestrictedCredential(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUsecase := NewMockusecase(ctrl)
+	client := newClient(t, mockUsecase, aliceKey)
+
+	mockUsecase.EXPECT().
+		Get(gomock.Any(), aliceWallet).
+		Return(walletUsecase.WalletInfo{ID: aliceWallet}, nil)
+
+	_, err := client.Balance(context.Background(), &walletv1.BalanceRequest{WalletId: aliceWallet.String()})
+	require.NoError(t, err, "own wallet")
+
+	otherWallet := uuid.New()
+
+	_, err = client.Balance(context.Background(), &walletv1.BalanceRequest{WalletId: otherWallet.String()})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	assert.Equal(t, "WALLET_ACCESS_DENIED", errorReason(t, err))
+
+	_, err = client.Operate(context.Background(), &walletv1.OperateRequest{
+		WalletId:      otherWallet.String(),
+		OperationType: domain.Withdraw,
+		Amount:        100,
+	})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	assert.Equal(t, "WALLET_ACCESS_DENIED", errorReason(t, err))
+
+	stream, err := client.History(context.Background(), &walletv1.HistoryRequest{WalletId: otherWallet.String()})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+}
+
+func TestServer_RateLimited(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUsecase := NewMockusecase(ctrl)
+
+	// One request per wallet and method, shared by every client.
+	limiter := ratelimit.NewLimiter(ratelimit.NewMemoryStore(),
+		ratelimit.RouteLimits{Wallet: ratelimit.Limit{Rate: 0.001, Burst: 1}},
+		ratelimit.WithLogger(logging.Discard()),
+	)
+	limited := []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(interceptor.RateLimitUnary(limiter)),
+		grpc.ChainStreamInterceptor(interceptor.RateLimitStream(limiter)),
+	}
+	admin := newClient(t, mockUsecase, adminKey, limited...)
+	alice := newClient(t, mockUsecase, aliceKey, limited...)
+
+	mockUsecase.EXPECT().
+		Get(gomock.Any(), aliceWallet).
+		Return(walletUsecase.WalletInfo{ID: aliceWallet}, nil)
+	mockUsecase.EXPECT().
+		History(gomock.Any(), gomock.Any()).
+		Return(walletUsecase.HistoryPage{}, nil)
+
+	assertLimited := func(t *testing.T, err error) {
+		t.Helper()
+
+		st := status.Convert(err)
+		assert.Equal(t, codes.ResourceExhausted, st.Code())
+		assert.Equal(t, "RATE_LIMITED", errorReason(t, err))
+
+		var retryAfter time.Duration
+		for _, d := range st.Details() {
+			if info, ok := d.(*errdetails.RetryInfo); ok {
+				retryAfter = info.GetRetryDelay().AsDuration()
+			}
+		}
+		assert.Positive(t, retryAfter)
+	}
+
+	_, err := admin.Balance(context.Background(), &walletv1.BalanceRequest{WalletId: aliceWallet.String()})
+	require.NoError(t, err)
+	_, err = alice.Balance(context.Background(), &walletv1.BalanceRequest{WalletId: aliceWallet.String()})
+	assertLimited(t, err)
+
+	for i, client := range []walletv1.WalletServiceClient{admin, alice} {
+		stream, err := client.History(context.Background(), &walletv1.HistoryRequest{WalletId: aliceWallet.String()})
+		require.NoError(t, err)
+		_, err = stream.Recv()
+		if i == 0 {
+			assert.Equal(t, io.EOF, err)
+		} else {
+			assertLimited(t, err)
+		}
+	}
+}
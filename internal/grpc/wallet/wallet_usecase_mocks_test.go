@@ -0,0 +1,120 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: contract.go
+
+// Package wallet_test is a generated GoMock package.
+package wallet_test
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	uuid "github.com/google/uuid"
+	auth "github.com/totorialman/go-test-ac/internal/auth"
+	wallet "github.com/totorialman/go-test-ac/internal/usecase/wallet"
+)
+
+// Mockauthorizer is a mock of authorizer interface.
+type Mockauthorizer struct {
+	ctrl     *gomock.Controller
+	recorder *MockauthorizerMockRecorder
+}
+
+// MockauthorizerMockRecorder is the mock recorder for Mockauthorizer.
+type MockauthorizerMockRecorder struct {
+	mock *Mockauthorizer
+}
+
+// NewMockauthorizer creates a new mock instance.
+func NewMockauthorizer(ctrl *gomock.Controller) *Mockauthorizer {
+	mock := &Mockauthorizer{ctrl: ctrl}
+	mock.recorder = &MockauthorizerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *Mockauthorizer) EXPECT() *MockauthorizerMockRecorder {
+	return m.recorder
+}
+
+// Authorize mocks base method.
+func (m *Mockauthorizer) Authorize(ctx context.Context, walletID uuid.UUID, scope auth.Scope) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Authorize", ctx, walletID, scope)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Authorize indicates an expected call of Authorize.
+func (mr *MockauthorizerMockRecorder) Authorize(ctx, walletID, scope interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Authorize", reflect.TypeOf((*Mockauthorizer)(nil).Authorize), ctx, walletID, scope)
+}
+
+// Mockusecase is a mock of usecase interface.
+type Mockusecase struct {
+	ctrl     *gomock.Controller
+	recorder *MockusecaseMockRecorder
+}
+
+// MockusecaseMockRecorder is the mock recorder for Mockusecase.
+type MockusecaseMockRecorder struct {
+	mock *Mockusecase
+}
+
+// NewMockusecase creates a new mock instance.
+func NewMockusecase(ctrl *gomock.Controller) *Mockusecase {
+	mock := &Mockusecase{ctrl: ctrl}
+	mock.recorder = &MockusecaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *Mockusecase) EXPECT() *MockusecaseMockRecorder {
+	return m.recorder
+}
+
+// Get mocks base method.
+func (m *Mockusecase) Get(ctx context.Context, id uuid.UUID) (wallet.WalletInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, id)
+	ret0, _ := ret[0].(wallet.WalletInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockusecaseMockRecorder) Get(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*Mockusecase)(nil).Get), ctx, id)
+}
+
+// History mocks base method.
+func (m *Mockusecase) History(ctx context.Context, f wallet.HistoryFilter) (wallet.HistoryPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "History", ctx, f)
+	ret0, _ := ret[0].(wallet.HistoryPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// History indicates an expected call of History.
+func (mr *MockusecaseMockRecorder) History(ctx, f interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "History", reflect.TypeOf((*Mockusecase)(nil).History), ctx, f)
+}
+
+// Operate mocks base method.
+func (m *Mockusecase) Operate(ctx context.Context, w wallet.Wallet) (wallet.Balance, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Operate", ctx, w)
+	ret0, _ := ret[0].(wallet.Balance)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Operate indicates an expected call of Operate.
+func (mr *MockusecaseMockRecorder) Operate(ctx, w interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Operate", reflect.TypeOf((*Mockusecase)(nil).Operate), ctx, w)
+}
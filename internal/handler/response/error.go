@@ -0,0 +1,136 @@
+// Package response writes the JSON error envelope shared by every HTTP
+// handler. The mapping from sentinel errors to HTTP statuses and
+// machine-readable codes lives here and nowhere else.
+package response
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	authErrors "github.com/totorialman/go-test-ac/internal/errors/auth"
+	ratelimitErrors "github.com/totorialman/go-test-ac/internal/errors/ratelimit"
+	walletErrors "github.com/totorialman/go-test-ac/internal/errors/wallet"
+	webhookErrors "github.com/totorialman/go-test-ac/internal/errors/webhook"
+	"github.com/totorialman/go-test-ac/internal/requestid"
+)
+
+const (
+	CodeInternal    = "INTERNAL_ERROR"
+	internalMessage = "internal server error"
+)
+
+type ErrorBody struct {
+	Code      string `json:"code"`
+	Message   string `json:"message"`
+	RequestID string `json:"requestId,omitempty"`
+}
+
+type ErrorEnvelope struct {
+	Error ErrorBody `json:"error"`
+}
+
+type mapping struct {
+	err    error
+	status int
+	code   string
+}
+
+var mappings = []mapping{
+	{walletErrors.ErrMalformedRequest, http.StatusBadRequest, "MALFORMED_REQUEST"},
+	{walletErrors.ErrInvalidWalletID, http.StatusBadRequest, "INVALID_WALLET_ID"},
+	{walletErrors.ErrInvalidHoldID, http.StatusBadRequest, "INVALID_HOLD_ID"},
+	{walletErrors.ErrInvalidTransactionID, http.StatusBadRequest, "INVALID_TRANSACTION_ID"},
+	{walletErrors.ErrInvalidOperation, http.StatusBadRequest, "INVALID_OPERATION"},
+	{walletErrors.ErrInvalidAmount, http.StatusBadRequest, "INVALID_AMOUNT"},
+	{walletErrors.ErrInvalidCurrency, http.StatusBadRequest, "INVALID_CURRENCY"},
+	{walletErrors.ErrInvalidCursor, http.StatusBadRequest, "INVALID_CURSOR"},
+	{walletErrors.ErrInvalidFilter, http.StatusBadRequest, "INVALID_FILTER"},
+	{walletErrors.ErrSameWallet, http.StatusBadRequest, "SAME_WALLET"},
+	{walletErrors.ErrInvalidStatus, http.StatusBadRequest, "INVALID_STATUS"},
+	{walletErrors.ErrInvalidOwner, http.StatusBadRequest, "INVALID_OWNER"},
+	{walletErrors.ErrInvalidMetadata, http.StatusBadRequest, "INVALID_METADATA"},
+	{walletErrors.ErrInvalidHoldTTL, http.StatusBadRequest, "INVALID_HOLD_TTL"},
+	{walletErrors.ErrInvalidIdempotencyKey, http.StatusBadRequest, "INVALID_IDEMPOTENCY_KEY"},
+	{walletErrors.ErrInvalidLimits, http.StatusBadRequest, "INVALID_LIMITS"},
+	{walletErrors.ErrInvalidBatchMode, http.StatusBadRequest, "INVALID_BATCH_MODE"},
+	{walletErrors.ErrEmptyBatch, http.StatusBadRequest, "EMPTY_BATCH"},
+
+	{authErrors.ErrUnauthenticated, http.StatusUnauthorized, "UNAUTHENTICATED"},
+
+	{authErrors.ErrForbidden, http.StatusForbidden, "FORBIDDEN"},
+	{authErrors.ErrWalletAccessDenied, http.StatusForbidden, "WALLET_ACCESS_DENIED"},
+
+	{walletErrors.ErrWalletNotFound, http.StatusNotFound, "WALLET_NOT_FOUND"},
+	{walletErrors.ErrHoldNotFound, http.StatusNotFound, "HOLD_NOT_FOUND"},
+	{walletErrors.ErrTransactionNotFound, http.StatusNotFound, "TRANSACTION_NOT_FOUND"},
+
+	{walletErrors.ErrNotEnoughFunds, http.StatusConflict, "NOT_ENOUGH_FUNDS"},
+	{walletErrors.ErrWalletNotEmpty, http.StatusConflict, "WALLET_NOT_EMPTY"},
+	{walletErrors.ErrInvalidStatusTransition, http.StatusConflict, "INVALID_STATUS_TRANSITION"},
+	{walletErrors.ErrHoldNotActive, http.StatusConflict, "HOLD_NOT_ACTIVE"},
+	{walletErrors.ErrHoldExpired, http.StatusConflict, "HOLD_EXPIRED"},
+	{walletErrors.ErrAlreadyReversed, http.StatusConflict, "ALREADY_REVERSED"},
+
+	{walletErrors.ErrBatchTooLarge, http.StatusRequestEntityTooLarge, "BATCH_TOO_LARGE"},
+
+	{walletErrors.ErrWalletClosed, http.StatusGone, "WALLET_CLOSED"},
+	{walletErrors.ErrWalletFrozen, http.StatusLocked, "WALLET_FROZEN"},
+
+	{walletErrors.ErrCurrencyMismatch, http.StatusUnprocessableEntity, "CURRENCY_MISMATCH"},
+	{walletErrors.ErrIdempotencyKeyReused, http.StatusUnprocessableEntity, "IDEMPOTENCY_KEY_REUSED"},
+	{walletErrors.ErrCaptureExceedsHold, http.StatusUnprocessableEntity, "CAPTURE_EXCEEDS_HOLD"},
+	{walletErrors.ErrNotReversible, http.StatusUnprocessableEntity, "NOT_REVERSIBLE"},
+	{walletErrors.ErrReversalExceedsAmount, http.StatusUnprocessableEntity, "REVERSAL_EXCEEDS_AMOUNT"},
+	{walletErrors.ErrLimitExceeded, http.StatusUnprocessableEntity, "LIMIT_EXCEEDED"},
+
+	{ratelimitErrors.ErrRateLimited, http.StatusTooManyRequests, "RATE_LIMITED"},
+
+	{webhookErrors.ErrInvalidURL, http.StatusBadRequest, "INVALID_WEBHOOK_URL"},
+	{webhookErrors.ErrInvalidEventType, http.StatusBadRequest, "INVALID_EVENT_TYPE"},
+	{webhookErrors.ErrInvalidSubscriptionID, http.StatusBadRequest, "INVALID_SUBSCRIPTION_ID"},
+	{webhookErrors.ErrInvalidDeliveryID, http.StatusBadRequest, "INVALID_DELIVERY_ID"},
+	{webhookErrors.ErrInvalidDeliveryFilter, http.StatusBadRequest, "INVALID_DELIVERY_FILTER"},
+	{webhookErrors.ErrSubscriptionNotFound, http.StatusNotFound, "SUBSCRIPTION_NOT_FOUND"},
+	{webhookErrors.ErrDeliveryNotFound, http.StatusNotFound, "DELIVERY_NOT_FOUND"},
+	{webhookErrors.ErrDeliveryNotDead, http.StatusConflict, "DELIVERY_NOT_DEAD"},
+}
+
+// Classify returns the HTTP status, code and client-facing message for err.
+// Errors that are not one of the known sentinels are reported as internal
+// errors without leaking their text.
+func Classify(err error) (status int, code, message string) {
+	for _, m := range mappings {
+		if errors.Is(err, m.err) {
+			return m.status, m.code, m.err.Error()
+		}
+	}
+	return http.StatusInternalServerError, CodeInternal, internalMessage
+}
+
+// LogLevel returns the level at which a handler should log err: errors the
+// client caused are warnings, everything else is an error.
+func LogLevel(err error) slog.Level {
+	if _, code, _ := Classify(err); code == CodeInternal {
+		return slog.LevelError
+	}
+	return slog.LevelWarn
+}
+
+// Error writes err as a JSON error envelope carrying the request id.
+func Error(w http.ResponseWriter, r *http.Request, err error) {
+	status, code, message := Classify(err)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	body := ErrorEnvelope{Error: ErrorBody{
+		Code:      code,
+		Message:   message,
+		RequestID: requestid.FromContext(r.Context()),
+	}}
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		slog.ErrorContext(r.Context(), "JSON encode error", "error", err)
+	}
+}
@@ -0,0 +1,83 @@
+package response_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	walletErrors "github.com/totorialman/go-test-ac/internal/errors/wallet"
+	"github.com/totorialman/go-test-ac/internal/handler/response"
+	"github.com/totorialman/go-test-ac/internal/requestid"
+)
+
+func TestError(t *testing.T) {
+	tests := []struct {
+		name            string
+		err             error
+		expectedStatus  int
+		expectedCode    string
+		expectedMessage string
+	}{
+		{
+			name:            "sentinel",
+			err:             walletErrors.ErrNotEnoughFunds,
+			expectedStatus:  http.StatusConflict,
+			expectedCode:    "NOT_ENOUGH_FUNDS",
+			expectedMessage: walletErrors.ErrNotEnoughFunds.Error(),
+		},
+		{
+			name:            "wrapped sentinel",
+			err:             errors.Join(errors.New("lock wallet"), walletErrors.ErrWalletFrozen),
+			expectedStatus:  http.StatusLocked,
+			expectedCode:    "WALLET_FROZEN",
+			expectedMessage: walletErrors.ErrWalletFrozen.Error(),
+		},
+		{
+			name:            "limit is not an access error",
+			err:             fmt.Errorf("%w: balance cap 100", walletErrors.ErrLimitExceeded),
+			expectedStatus:  http.StatusUnprocessableEntity,
+			expectedCode:    "LIMIT_EXCEEDED",
+			expectedMessage: walletErrors.ErrLimitExceeded.Error(),
+		},
+		{
+			name:            "malformed body",
+			err:             walletErrors.ErrMalformedRequest,
+			expectedStatus:  http.StatusBadRequest,
+			expectedCode:    "MALFORMED_REQUEST",
+			expectedMessage: walletErrors.ErrMalformedRequest.Error(),
+		},
+		{
+			name:            "unknown error is not leaked",
+			err:             errors.New("pq: connection refused"),
+			expectedStatus:  http.StatusInternalServerError,
+			expectedCode:    response.CodeInternal,
+			expectedMessage: "internal server error",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req = req.WithContext(requestid.NewContext(req.Context(), "req-1"))
+			w := httptest.NewRecorder()
+
+			response.Error(w, req, tt.err)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+
+			var body response.ErrorEnvelope
+			assert.NoError(t, json.NewDecoder(w.Body).Decode(&body))
+			assert.Equal(t, response.ErrorBody{
+				Code:      tt.expectedCode,
+				Message:   tt.expectedMessage,
+				RequestID: "req-1",
+			}, body.Error)
+		})
+	}
+}
@@ -0,0 +1,266 @@
+package wallet
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+
+	"github.com/google/uuid"
+
+	"github.com/totorialman/go-test-ac/internal/auth"
+	"github.com/totorialman/go-test-ac/internal/domain"
+	walletErrors "github.com/totorialman/go-test-ac/internal/errors/wallet"
+	"github.com/totorialman/go-test-ac/internal/handler/response"
+	"github.com/totorialman/go-test-ac/internal/requestid"
+	"github.com/totorialman/go-test-ac/internal/tracing"
+	"github.com/totorialman/go-test-ac/internal/usecase/wallet"
+)
+
+const (
+	// MaxBatchOperations caps the number of operations in one batch.
+	MaxBatchOperations = 1000
+	// maxBatchBodyBytes bounds a batch request body. It fits
+	// MaxBatchOperations operations with long idempotency keys.
+	maxBatchBodyBytes = 1 << 20
+)
+
+// Batch applies up to MaxBatchOperations deposits and withdrawals. Every
+// operation is validated and authorized as Operate does it. In
+// ALL_OR_NOTHING mode, the default, the first failing operation rolls the
+// whole batch back and its error sets the response status; in BEST_EFFORT
+// mode the response is 200 with the outcome of each operation.
+func (h *Handler) Batch(w http.ResponseWriter, r *http.Request) {
+	ctx, span := tracing.Start(r.Context(), "wallet.Handler.Batch")
+	defer span.End()
+	r = r.WithContext(ctx)
+
+	mode, ops, err := decodeBatch(http.MaxBytesReader(w, r.Body, maxBatchBodyBytes))
+	if err != nil {
+		h.logger.WarnContext(r.Context(), "batch decode error", "error", err)
+		response.Error(w, r, err)
+		return
+	}
+
+	h.logger.DebugContext(r.Context(), "batch request", "mode", mode, "operations", len(ops))
+
+	if h.limiter != nil {
+		var walletIDs []uuid.UUID
+		seen := make(map[uuid.UUID]bool, len(ops))
+		for _, op := range ops {
+			if !seen[op.ID] {
+				seen[op.ID] = true
+				walletIDs = append(walletIDs, op.ID)
+			}
+		}
+		if !h.limiter.AllowWallets(w, r, walletIDs...) {
+			return
+		}
+	}
+
+	itemErrs := make([]error, len(ops))
+	var pending []int
+	for i, op := range ops {
+		if err := h.checkOperation(r.Context(), op.WalletRequest); err != nil {
+			if mode == domain.BatchAllOrNothing {
+				h.writeBatchRollback(w, r, ops, &walletErrors.BatchItemError{Index: i, Err: err})
+				return
+			}
+			itemErrs[i] = err
+			continue
+		}
+		pending = append(pending, i)
+	}
+
+	wallets := make([]wallet.Wallet, len(pending))
+	for j, i := range pending {
+		wallets[j] = wallet.Wallet{
+			ID:             ops[i].ID,
+			OperationType:  ops[i].OperationType,
+			Amount:         ops[i].Amount,
+			Currency:       ops[i].Currency,
+			IdempotencyKey: ops[i].IdempotencyKey,
+		}
+	}
+
+	var results []wallet.BatchResult
+	if len(wallets) > 0 {
+		results, err = h.usecase.Batch(r.Context(), mode, wallets)
+		if err != nil {
+			tracing.RecordError(span, err)
+			var itemErr *walletErrors.BatchItemError
+			if errors.As(err, &itemErr) {
+				itemErr = &walletErrors.BatchItemError{Index: pending[itemErr.Index], Err: itemErr.Err}
+				h.writeBatchRollback(w, r, ops, itemErr)
+				return
+			}
+			h.logger.Log(r.Context(), response.LogLevel(err), "batch error", "error", err)
+			response.Error(w, r, err)
+			return
+		}
+	}
+
+	resp := BatchResponse{Mode: mode, Results: make([]BatchItemResponse, len(ops))}
+	for i, op := range ops {
+		resp.Results[i] = BatchItemResponse{Index: i, WalletID: op.ID}
+		if itemErrs[i] != nil {
+			resp.Results[i].Status = BatchStatusFailed
+			resp.Results[i].Error = batchErrorBody(itemErrs[i])
+			resp.Failed++
+		}
+	}
+	for j, res := range results {
+		item := &resp.Results[pending[j]]
+		if res.Err != nil {
+			if response.LogLevel(res.Err) == slog.LevelError {
+				h.logger.ErrorContext(r.Context(), "batch operation error", "index", item.Index, "error", res.Err)
+			}
+			item.Status = BatchStatusFailed
+			item.Error = batchErrorBody(res.Err)
+			resp.Failed++
+			continue
+		}
+		balance := newWalletResponse(item.WalletID, res.Balance)
+		item.Status = BatchStatusApplied
+		item.Balance = &balance
+		resp.Applied++
+	}
+
+	h.logger.InfoContext(r.Context(), "batch success", "mode", mode, "applied", resp.Applied, "failed", resp.Failed)
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		h.logger.ErrorContext(r.Context(), "JSON encode error", "error", err)
+	}
+}
+
+// checkOperation applies the request checks of Operate to one operation.
+func (h *Handler) checkOperation(ctx context.Context, req WalletRequest) error {
+	if req.Amount <= 0 {
+		return walletErrors.ErrInvalidAmount
+	}
+	if req.OperationType != domain.Deposit && req.OperationType != domain.Withdraw {
+		return walletErrors.ErrInvalidOperation
+	}
+
+	scope := auth.ScopeDeposit
+	if req.OperationType == domain.Withdraw {
+		scope = auth.ScopeWithdraw
+	}
+	return h.authorizer.Authorize(ctx, req.ID, scope)
+}
+
+// writeBatchRollback reports an ALL_OR_NOTHING batch of which nothing was
+// applied because of the operation named by itemErr.
+func (h *Handler) writeBatchRollback(w http.ResponseWriter, r *http.Request, ops []BatchOperation, itemErr *walletErrors.BatchItemError) {
+	h.logger.Log(r.Context(), response.LogLevel(itemErr), "batch rolled back", "error", itemErr)
+
+	status, code, message := response.Classify(itemErr)
+	resp := BatchResponse{
+		Mode:    domain.BatchAllOrNothing,
+		Failed:  1,
+		Results: make([]BatchItemResponse, len(ops)),
+		Error: &response.ErrorBody{
+			Code:      code,
+			Message:   fmt.Sprintf("operation %d: %s", itemErr.Index, message),
+			RequestID: requestid.FromContext(r.Context()),
+		},
+	}
+	for i, op := range ops {
+		resp.Results[i] = BatchItemResponse{Index: i, WalletID: op.ID, Status: BatchStatusNotApplied}
+	}
+	resp.Results[itemErr.Index].Status = BatchStatusFailed
+	resp.Results[itemErr.Index].Error = batchErrorBody(itemErr.Err)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		h.logger.ErrorContext(r.Context(), "JSON encode error", "error", err)
+	}
+}
+
+func batchErrorBody(err error) *response.ErrorBody {
+	_, code, message := response.Classify(err)
+	return &response.ErrorBody{Code: code, Message: message}
+}
+
+// decodeBatch reads {"mode": ..., "operations": [...]} token by token. Each
+// operation is decoded on its own, so the raw array is never buffered, and
+// decoding stops as soon as the array grows past MaxBatchOperations.
+func decodeBatch(body io.Reader) (string, []BatchOperation, error) {
+	dec := json.NewDecoder(body)
+	mode := domain.BatchAllOrNothing
+	var ops []BatchOperation
+
+	if err := expectDelim(dec, '{'); err != nil {
+		return "", nil, err
+	}
+	for dec.More() {
+		key, err := dec.Token()
+		if err != nil {
+			return "", nil, batchDecodeError(err)
+		}
+
+		switch key {
+		case "mode":
+			if err := dec.Decode(&mode); err != nil {
+				return "", nil, batchDecodeError(err)
+			}
+		case "operations":
+			if err := expectDelim(dec, '['); err != nil {
+				return "", nil, err
+			}
+			for dec.More() {
+				if len(ops) == MaxBatchOperations {
+					return "", nil, fmt.Errorf("%w: more than %d", walletErrors.ErrBatchTooLarge, MaxBatchOperations)
+				}
+				var op BatchOperation
+				if err := dec.Decode(&op); err != nil {
+					return "", nil, batchDecodeError(err)
+				}
+				ops = append(ops, op)
+			}
+			if err := expectDelim(dec, ']'); err != nil {
+				return "", nil, err
+			}
+		default:
+			var skip json.RawMessage
+			if err := dec.Decode(&skip); err != nil {
+				return "", nil, batchDecodeError(err)
+			}
+		}
+	}
+	if err := expectDelim(dec, '}'); err != nil {
+		return "", nil, err
+	}
+
+	if mode != domain.BatchAllOrNothing && mode != domain.BatchBestEffort {
+		return "", nil, walletErrors.ErrInvalidBatchMode
+	}
+	if len(ops) == 0 {
+		return "", nil, walletErrors.ErrEmptyBatch
+	}
+	return mode, ops, nil
+}
+
+func expectDelim(dec *json.Decoder, want json.Delim) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return batchDecodeError(err)
+	}
+	if tok != want {
+		return fmt.Errorf("%w: expected %v, got %v", walletErrors.ErrMalformedRequest, want, tok)
+	}
+	return nil
+}
+
+func batchDecodeError(err error) error {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		return fmt.Errorf("%w: body exceeds %d bytes", walletErrors.ErrBatchTooLarge, tooLarge.Limit)
+	}
+	return fmt.Errorf("%w: %v", walletErrors.ErrMalformedRequest, err)
+}
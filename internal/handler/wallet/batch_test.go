@@ -0,0 +1,248 @@
+package wallet_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/totorialman/go-test-ac/internal/auth"
+	"github.com/totorialman/go-test-ac/internal/domain"
+	authErrors "github.com/totorialman/go-test-ac/internal/errors/auth"
+	walletErrors "github.com/totorialman/go-test-ac/internal/errors/wallet"
+	"github.com/totorialman/go-test-ac/internal/handler/wallet"
+	"github.com/totorialman/go-test-ac/internal/logging"
+	"github.com/totorialman/go-test-ac/internal/ratelimit"
+	walletUsecase "github.com/totorialman/go-test-ac/internal/usecase/wallet"
+)
+
+func TestHandler_Batch(t *testing.T) {
+	first, second := uuid.New(), uuid.New()
+	deposit := func(id uuid.UUID, amount int64) string {
+		return fmt.Sprintf(`{"walletId":"%s","operationType":"DEPOSIT","amount":%d}`, id, amount)
+	}
+	withdraw := func(id uuid.UUID, amount int64) string {
+		return fmt.Sprintf(`{"walletId":"%s","operationType":"WITHDRAW","amount":%d,"idempotencyKey":"payroll-1"}`, id, amount)
+	}
+
+	tests := []struct {
+		name           string
+		body           string
+		mockReturn     func(u *MockusecaseMockRecorder, a *MockauthorizerMockRecorder)
+		expectedStatus int
+		expectedBody   []string
+	}{
+		{
+			name: "all or nothing applied",
+			body: `{"operations":[` + deposit(first, 100) + `,` + withdraw(second, 30) + `]}`,
+			mockReturn: func(u *MockusecaseMockRecorder, a *MockauthorizerMockRecorder) {
+				a.Authorize(gomock.Any(), first, auth.ScopeDeposit).Return(nil)
+				a.Authorize(gomock.Any(), second, auth.ScopeWithdraw).Return(nil)
+				u.Batch(gomock.Any(), domain.BatchAllOrNothing, []walletUsecase.Wallet{
+					{ID: first, OperationType: domain.Deposit, Amount: 100},
+					{ID: second, OperationType: domain.Withdraw, Amount: 30, IdempotencyKey: "payroll-1"},
+				}).Return([]walletUsecase.BatchResult{
+					{Balance: walletUsecase.Balance{Amount: 100, Available: 100, Currency: "RUB", Exponent: 2}},
+					{Balance: walletUsecase.Balance{Amount: 70, Available: 70, Currency: "RUB", Exponent: 2}},
+				}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: []string{
+				`"mode":"ALL_OR_NOTHING","applied":2,"failed":0`,
+				`"index":1,"walletId":"` + second.String() + `","status":"APPLIED","balance":{"walletId":"` + second.String() + `","balance":70`,
+			},
+		},
+		{
+			name: "all or nothing rolled back",
+			body: `{"mode":"ALL_OR_NOTHING","operations":[` + deposit(first, 100) + `,` + withdraw(second, 30) + `]}`,
+			mockReturn: func(u *MockusecaseMockRecorder, a *MockauthorizerMockRecorder) {
+				a.Authorize(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(2)
+				u.Batch(gomock.Any(), domain.BatchAllOrNothing, gomock.Any()).
+					Return(nil, &walletErrors.BatchItemError{Index: 1, Err: walletErrors.ErrNotEnoughFunds})
+			},
+			expectedStatus: http.StatusConflict,
+			expectedBody: []string{
+				`"applied":0,"failed":1`,
+				`"index":0,"walletId":"` + first.String() + `","status":"NOT_APPLIED"}`,
+				`"index":1,"walletId":"` + second.String() + `","status":"FAILED","error":{"code":"NOT_ENOUGH_FUNDS","message":"not enough funds"}}`,
+				`"error":{"code":"NOT_ENOUGH_FUNDS","message":"operation 1: not enough funds"}`,
+			},
+		},
+		{
+			name: "all or nothing rejected before the usecase",
+			body: `{"operations":[` + deposit(first, 100) + `,` + deposit(second, 100) + `]}`,
+			mockReturn: func(u *MockusecaseMockRecorder, a *MockauthorizerMockRecorder) {
+				a.Authorize(gomock.Any(), first, auth.ScopeDeposit).Return(nil)
+				a.Authorize(gomock.Any(), second, auth.ScopeDeposit).Return(authErrors.ErrWalletAccessDenied)
+			},
+			expectedStatus: http.StatusForbidden,
+			expectedBody: []string{
+				`"index":1,"walletId":"` + second.String() + `","status":"FAILED","error":{"code":"WALLET_ACCESS_DENIED"`,
+			},
+		},
+		{
+			name: "best effort",
+			body: `{"operations":[` + deposit(first, 0) + `,` + withdraw(second, 30) + `,` + deposit(second, 5) + `],"mode":"BEST_EFFORT"}`,
+			mockReturn: func(u *MockusecaseMockRecorder, a *MockauthorizerMockRecorder) {
+				a.Authorize(gomock.Any(), second, auth.ScopeWithdraw).Return(nil)
+				a.Authorize(gomock.Any(), second, auth.ScopeDeposit).Return(nil)
+				u.Batch(gomock.Any(), domain.BatchBestEffort, []walletUsecase.Wallet{
+					{ID: second, OperationType: domain.Withdraw, Amount: 30, IdempotencyKey: "payroll-1"},
+					{ID: second, OperationType: domain.Deposit, Amount: 5},
+				}).Return([]walletUsecase.BatchResult{
+					{Err: walletErrors.ErrNotEnoughFunds},
+					{Balance: walletUsecase.Balance{Amount: 5, Available: 5, Currency: "RUB", Exponent: 2}},
+				}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: []string{
+				`"mode":"BEST_EFFORT","applied":1,"failed":2`,
+				`"index":0,"walletId":"` + first.String() + `","status":"FAILED","error":{"code":"INVALID_AMOUNT"`,
+				`"index":1,"walletId":"` + second.String() + `","status":"FAILED","error":{"code":"NOT_ENOUGH_FUNDS"`,
+				`"index":2,"walletId":"` + second.String() + `","status":"APPLIED","balance":{`,
+			},
+		},
+		{
+			name:           "invalid mode",
+			body:           `{"mode":"SOMETIMES","operations":[` + deposit(first, 1) + `]}`,
+			mockReturn:     func(u *MockusecaseMockRecorder, a *MockauthorizerMockRecorder) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   []string{`"code":"INVALID_BATCH_MODE"`},
+		},
+		{
+			name:           "empty",
+			body:           `{"mode":"BEST_EFFORT","operations":[]}`,
+			mockReturn:     func(u *MockusecaseMockRecorder, a *MockauthorizerMockRecorder) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   []string{`"code":"EMPTY_BATCH"`},
+		},
+		{
+			name:           "not an object",
+			body:           `[` + deposit(first, 1) + `]`,
+			mockReturn:     func(u *MockusecaseMockRecorder, a *MockauthorizerMockRecorder) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   []string{`"code":"MALFORMED_REQUEST"`},
+		},
+		{
+			name:           "truncated",
+			body:           `{"operations":[` + deposit(first, 1) + `,{"walletId":`,
+			mockReturn:     func(u *MockusecaseMockRecorder, a *MockauthorizerMockRecorder) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   []string{`"code":"MALFORMED_REQUEST"`},
+		},
+		{
+			name:           "too many operations",
+			body:           `{"operations":[` + strings.Repeat(deposit(first, 1)+`,`, wallet.MaxBatchOperations) + deposit(first, 1) + `]}`,
+			mockReturn:     func(u *MockusecaseMockRecorder, a *MockauthorizerMockRecorder) {},
+			expectedStatus: http.StatusRequestEntityTooLarge,
+			expectedBody:   []string{`"code":"BATCH_TOO_LARGE"`},
+		},
+		{
+			name:           "body too large",
+			body:           `{"padding":"` + strings.Repeat("x", 2<<20) + `"}`,
+			mockReturn:     func(u *MockusecaseMockRecorder, a *MockauthorizerMockRecorder) {},
+			expectedStatus: http.StatusRequestEntityTooLarge,
+			expectedBody:   []string{`"code":"BATCH_TOO_LARGE"`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockUsecase := NewMockusecase(ctrl)
+			mockAuthorizer := NewMockauthorizer(ctrl)
+			h := wallet.NewHandler(mockUsecase, mockAuthorizer, logging.Discard())
+			tt.mockReturn(mockUsecase.EXPECT(), mockAuthorizer.EXPECT())
+
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/wallet/batch", strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+
+			h.Batch(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Result().StatusCode)
+			for _, want := range tt.expectedBody {
+				assert.Contains(t, w.Body.String(), want)
+			}
+		})
+	}
+}
+
+func TestHandler_BatchMaxOperations(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUsecase := NewMockusecase(ctrl)
+	mockAuthorizer := NewMockauthorizer(ctrl)
+	h := wallet.NewHandler(mockUsecase, mockAuthorizer, logging.Discard())
+
+	ops := make([]string, wallet.MaxBatchOperations)
+	for i := range ops {
+		ops[i] = fmt.Sprintf(`{"walletId":"%s","operationType":"DEPOSIT","amount":1000,"idempotencyKey":"%s"}`,
+			uuid.New(), strings.Repeat("k", 255))
+	}
+
+	mockAuthorizer.EXPECT().Authorize(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(wallet.MaxBatchOperations)
+	mockUsecase.EXPECT().
+		Batch(gomock.Any(), domain.BatchBestEffort, gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, ops []walletUsecase.Wallet) ([]walletUsecase.BatchResult, error) {
+			return make([]walletUsecase.BatchResult, len(ops)), nil
+		})
+
+	body := `{"mode":"BEST_EFFORT","operations":[` + strings.Join(ops, ",") + `]}`
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/wallet/batch", strings.NewReader(body))
+	w := httptest.NewRecorder()
+
+	h.Batch(w, req)
+
+	require.Equal(t, http.StatusOK, w.Result().StatusCode)
+	var resp wallet.BatchResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+	assert.Equal(t, wallet.MaxBatchOperations, resp.Applied)
+}
+
+func TestHandler_BatchRateLimited(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUsecase := NewMockusecase(ctrl)
+	mockAuthorizer := NewMockauthorizer(ctrl)
+	limiter := ratelimit.NewLimiter(ratelimit.NewMemoryStore(),
+		ratelimit.RouteLimits{Wallet: ratelimit.Limit{Rate: 0.001, Burst: 1}},
+		ratelimit.WithLogger(logging.Discard()),
+	)
+	h := wallet.NewHandler(mockUsecase, mockAuthorizer, logging.Discard(), wallet.WithRateLimiter(limiter))
+
+	walletID := uuid.New()
+	op := fmt.Sprintf(`{"walletId":"%s","operationType":"DEPOSIT","amount":1000}`, walletID)
+	body := `{"mode":"BEST_EFFORT","operations":[` + op + `,` + op + `]}`
+
+	// The two operations of the first batch are on one wallet and take a
+	// single token from its bucket; the second batch finds it empty.
+	mockAuthorizer.EXPECT().Authorize(gomock.Any(), walletID, gomock.Any()).Return(nil).Times(2)
+	mockUsecase.EXPECT().
+		Batch(gomock.Any(), domain.BatchBestEffort, gomock.Any()).
+		Return(make([]walletUsecase.BatchResult, 2), nil)
+
+	for i, expectedStatus := range []int{http.StatusOK, http.StatusTooManyRequests} {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/wallet/batch", strings.NewReader(body))
+		w := httptest.NewRecorder()
+
+		h.Batch(w, req)
+
+		assert.Equal(t, expectedStatus, w.Result().StatusCode, "batch %d", i)
+		if expectedStatus == http.StatusTooManyRequests {
+			assert.Contains(t, w.Body.String(), `"code":"RATE_LIMITED"`)
+			assert.NotEmpty(t, w.Header().Get("Retry-After"))
+		}
+	}
+}
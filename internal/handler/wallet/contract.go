@@ -3,13 +3,35 @@ package wallet
 
 import (
 	"context"
+	"net/http"
 
 	"github.com/google/uuid"
 
+	"github.com/totorialman/go-test-ac/internal/auth"
 	"github.com/totorialman/go-test-ac/internal/usecase/wallet"
 )
 
+type authorizer interface {
+	Authorize(ctx context.Context, walletID uuid.UUID, scope auth.Scope) error
+}
+
+type rateLimiter interface {
+	AllowWallets(w http.ResponseWriter, r *http.Request, walletIDs ...uuid.UUID) bool
+}
+
 type usecase interface {
-	Operate(ctx context.Context, w wallet.Wallet) (int64, error)
-	Balance(ctx context.Context, id uuid.UUID) (int64, error)
+	Operate(ctx context.Context, w wallet.Wallet) (wallet.Balance, error)
+	Batch(ctx context.Context, mode string, ops []wallet.Wallet) ([]wallet.BatchResult, error)
+	Create(ctx context.Context, w wallet.NewWallet) (wallet.WalletInfo, error)
+	Get(ctx context.Context, id uuid.UUID) (wallet.WalletInfo, error)
+	UpdateStatus(ctx context.Context, id uuid.UUID, status string) (wallet.WalletInfo, error)
+	SetHot(ctx context.Context, id uuid.UUID, hot bool) (wallet.WalletInfo, error)
+	GetLimits(ctx context.Context, id uuid.UUID) (wallet.Limits, error)
+	SetLimits(ctx context.Context, id uuid.UUID, l wallet.Limits) (wallet.Limits, error)
+	Transfer(ctx context.Context, t wallet.Transfer) (wallet.TransferResult, error)
+	Authorize(ctx context.Context, a wallet.Authorization) (wallet.Hold, error)
+	Capture(ctx context.Context, holdID uuid.UUID, amount int64) (wallet.Hold, error)
+	Void(ctx context.Context, holdID uuid.UUID) (wallet.Hold, error)
+	Reverse(ctx context.Context, txID uuid.UUID, amount int64) (wallet.Reversal, error)
+	History(ctx context.Context, f wallet.HistoryFilter) (wallet.HistoryPage, error)
 }
@@ -1,15 +1,230 @@
 package wallet
 
-import "github.com/google/uuid"
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/totorialman/go-test-ac/internal/handler/response"
+	"github.com/totorialman/go-test-ac/internal/usecase/wallet"
+)
 
 type WalletRequest struct {
 	ID            uuid.UUID `json:"walletId"`
 	OperationType string    `json:"operationType"`
 	Amount        int64     `json:"amount"`
+	Currency      string    `json:"currency,omitempty"`
 }
 
+// WalletResponse.Balance is kept for existing clients and always equals
+// Ledger.
 type WalletResponse struct {
-	ID      uuid.UUID `json:"walletId"`
-	Balance int64     `json:"balance"`
+	ID        uuid.UUID `json:"walletId"`
+	Balance   int64     `json:"balance"`
+	Ledger    int64     `json:"ledger"`
+	Available int64     `json:"available"`
+	Currency  string    `json:"currency"`
+	Exponent  int       `json:"exponent"`
+}
+
+func newWalletResponse(id uuid.UUID, b wallet.Balance) WalletResponse {
+	return WalletResponse{
+		ID:        id,
+		Balance:   b.Amount,
+		Ledger:    b.Amount,
+		Available: b.Available,
+		Currency:  b.Currency,
+		Exponent:  b.Exponent,
+	}
+}
+
+type TransactionResponse struct {
+	ID             uuid.UUID  `json:"id"`
+	WalletID       uuid.UUID  `json:"walletId"`
+	OperationType  string     `json:"operationType"`
+	Amount         int64      `json:"amount"`
+	BalanceAfter   int64      `json:"balanceAfter"`
+	CounterpartyID *uuid.UUID `json:"counterpartyWalletId,omitempty"`
+	ReversalOf     *uuid.UUID `json:"reversalOf,omitempty"`
+	CreatedAt      time.Time  `json:"createdAt"`
+}
+
+func newTransactionResponse(t wallet.Transaction) TransactionResponse {
+	res := TransactionResponse{
+		ID:            t.ID,
+		WalletID:      t.WalletID,
+		OperationType: t.OperationType,
+		Amount:        t.Amount,
+		BalanceAfter:  t.BalanceAfter,
+		CreatedAt:     t.CreatedAt,
+	}
+	if t.CounterpartyID.Valid {
+		res.CounterpartyID = &t.CounterpartyID.UUID
+	}
+	if t.ReversalOf.Valid {
+		res.ReversalOf = &t.ReversalOf.UUID
+	}
+	return res
+}
+
+type HistoryResponse struct {
+	Transactions []TransactionResponse `json:"transactions"`
+	NextCursor   string                `json:"nextCursor,omitempty"`
+}
+
+type TransferRequest struct {
+	FromID   uuid.UUID `json:"fromWalletId"`
+	ToID     uuid.UUID `json:"toWalletId"`
+	Amount   int64     `json:"amount"`
+	Currency string    `json:"currency,omitempty"`
+}
+
+type TransferResponse struct {
+	From WalletResponse `json:"from"`
+	To   WalletResponse `json:"to"`
+}
+
+type AuthorizeRequest struct {
+	Amount     int64  `json:"amount"`
+	Currency   string `json:"currency,omitempty"`
+	TTLSeconds int64  `json:"ttlSeconds,omitempty"`
+}
+
+type CaptureRequest struct {
+	// Amount is optional; zero captures the whole hold.
+	Amount int64 `json:"amount,omitempty"`
+}
+
+type HoldResponse struct {
+	ID             uuid.UUID      `json:"holdId"`
+	WalletID       uuid.UUID      `json:"walletId"`
+	Amount         int64          `json:"amount"`
+	CapturedAmount int64          `json:"capturedAmount"`
+	Status         string         `json:"status"`
+	ExpiresAt      time.Time      `json:"expiresAt"`
+	CreatedAt      time.Time      `json:"createdAt"`
+	Balance        WalletResponse `json:"balance"`
+}
+
+func newHoldResponse(h wallet.Hold) HoldResponse {
+	return HoldResponse{
+		ID:             h.ID,
+		WalletID:       h.WalletID,
+		Amount:         h.Amount,
+		CapturedAmount: h.CapturedAmount,
+		Status:         h.Status,
+		ExpiresAt:      h.ExpiresAt,
+		CreatedAt:      h.CreatedAt,
+		Balance:        newWalletResponse(h.WalletID, h.Balance),
+	}
+}
+
+type CreateWalletRequest struct {
+	OwnerID  string          `json:"ownerId"`
+	Currency string          `json:"currency,omitempty"`
+	Metadata json.RawMessage `json:"metadata,omitempty"`
 }
 
+type UpdateWalletRequest struct {
+	Status string `json:"status"`
+}
+
+type SetHotRequest struct {
+	// Hot is required; a pointer tells a missing field from false.
+	Hot *bool `json:"hot"`
+}
+
+type WalletDetailsResponse struct {
+	WalletResponse
+	OwnerID   string          `json:"ownerId"`
+	Status    string          `json:"status"`
+	Metadata  json.RawMessage `json:"metadata"`
+	Hot       bool            `json:"hot"`
+	CreatedAt time.Time       `json:"createdAt"`
+	UpdatedAt time.Time       `json:"updatedAt"`
+}
+
+func newWalletDetailsResponse(info wallet.WalletInfo) WalletDetailsResponse {
+	return WalletDetailsResponse{
+		WalletResponse: newWalletResponse(info.ID, info.Balance),
+		OwnerID:        info.OwnerID,
+		Status:         info.Status,
+		Metadata:       info.Metadata,
+		Hot:            info.Hot,
+		CreatedAt:      info.CreatedAt,
+		UpdatedAt:      info.UpdatedAt,
+	}
+}
+
+type ReverseRequest struct {
+	// Amount is optional; zero reverses everything not reversed yet.
+	Amount int64 `json:"amount,omitempty"`
+}
+
+type ReversalResponse struct {
+	Transaction TransactionResponse `json:"transaction"`
+	Balance     WalletResponse      `json:"balance"`
+}
+
+// LimitsRequest replaces a wallet's overrides; an omitted or null field
+// falls back to the wallet's tier.
+type LimitsRequest struct {
+	MaxWithdrawAmount *int64 `json:"maxWithdrawAmount"`
+	MaxDailyWithdraw  *int64 `json:"maxDailyWithdraw"`
+	MaxBalance        *int64 `json:"maxBalance"`
+	MinBalance        *int64 `json:"minBalance"`
+}
+
+// LimitsResponse lists the limits in force; null means no limit.
+type LimitsResponse struct {
+	WalletID          uuid.UUID `json:"walletId"`
+	MaxWithdrawAmount *int64    `json:"maxWithdrawAmount"`
+	MaxDailyWithdraw  *int64    `json:"maxDailyWithdraw"`
+	MaxBalance        *int64    `json:"maxBalance"`
+	MinBalance        *int64    `json:"minBalance"`
+}
+
+func newLimitsResponse(id uuid.UUID, l wallet.Limits) LimitsResponse {
+	return LimitsResponse{
+		WalletID:          id,
+		MaxWithdrawAmount: l.MaxWithdrawAmount,
+		MaxDailyWithdraw:  l.MaxDailyWithdraw,
+		MaxBalance:        l.MaxBalance,
+		MinBalance:        l.MinBalance,
+	}
+}
+
+// BatchOperation is one element of the operations array of a batch
+// request. Operations are decoded one at a time, so the array is never held
+// as raw JSON.
+type BatchOperation struct {
+	WalletRequest
+	IdempotencyKey string `json:"idempotencyKey,omitempty"`
+}
+
+// Statuses of a batch operation. NOT_APPLIED marks the operations of an
+// ALL_OR_NOTHING batch rolled back because another one failed.
+const (
+	BatchStatusApplied    = "APPLIED"
+	BatchStatusFailed     = "FAILED"
+	BatchStatusNotApplied = "NOT_APPLIED"
+)
+
+type BatchItemResponse struct {
+	Index    int                 `json:"index"`
+	WalletID uuid.UUID           `json:"walletId"`
+	Status   string              `json:"status"`
+	Balance  *WalletResponse     `json:"balance,omitempty"`
+	Error    *response.ErrorBody `json:"error,omitempty"`
+}
+
+// BatchResponse.Error is set, as in the error envelope, when an
+// ALL_OR_NOTHING batch is rolled back.
+type BatchResponse struct {
+	Mode    string              `json:"mode"`
+	Applied int                 `json:"applied"`
+	Failed  int                 `json:"failed"`
+	Results []BatchItemResponse `json:"results"`
+	Error   *response.ErrorBody `json:"error,omitempty"`
+}
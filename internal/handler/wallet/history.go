@@ -0,0 +1,84 @@
+package wallet
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+
+	walletErrors "github.com/totorialman/go-test-ac/internal/errors/wallet"
+	"github.com/totorialman/go-test-ac/internal/handler/response"
+	"github.com/totorialman/go-test-ac/internal/usecase/wallet"
+)
+
+func (h *Handler) History(w http.ResponseWriter, r *http.Request) {
+	idStr := mux.Vars(r)["WALLET_UUID"]
+	h.logger.DebugContext(r.Context(), "history request", "wallet_id", idStr, "query", r.URL.RawQuery)
+
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		h.logger.WarnContext(r.Context(), "invalid uuid", "error", err)
+		response.Error(w, r, walletErrors.ErrInvalidWalletID)
+		return
+	}
+
+	filter, err := parseHistoryFilter(r)
+	if err != nil {
+		h.logger.WarnContext(r.Context(), "invalid history filter", "error", err)
+		response.Error(w, r, walletErrors.ErrInvalidFilter)
+		return
+	}
+	filter.WalletID = id
+
+	page, err := h.usecase.History(r.Context(), filter)
+	if err != nil {
+		h.logger.Log(r.Context(), response.LogLevel(err), "history error", "error", err)
+		response.Error(w, r, err)
+		return
+	}
+
+	h.logger.InfoContext(r.Context(), "history success", "wallet_id", id, "count", len(page.Transactions))
+
+	res := HistoryResponse{
+		Transactions: make([]TransactionResponse, 0, len(page.Transactions)),
+		NextCursor:   page.NextCursor,
+	}
+	for _, t := range page.Transactions {
+		res.Transactions = append(res.Transactions, newTransactionResponse(t))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(res); err != nil {
+		h.logger.ErrorContext(r.Context(), "JSON encode error", "error", err)
+	}
+}
+
+func parseHistoryFilter(r *http.Request) (wallet.HistoryFilter, error) {
+	q := r.URL.Query()
+	f := wallet.HistoryFilter{
+		OperationType: q.Get("type"),
+		Cursor:        q.Get("cursor"),
+	}
+
+	var err error
+	if v := q.Get("limit"); v != "" {
+		if f.Limit, err = strconv.Atoi(v); err != nil {
+			return f, err
+		}
+	}
+	if v := q.Get("from"); v != "" {
+		if f.From, err = time.Parse(time.RFC3339, v); err != nil {
+			return f, err
+		}
+	}
+	if v := q.Get("to"); v != "" {
+		if f.To, err = time.Parse(time.RFC3339, v); err != nil {
+			return f, err
+		}
+	}
+
+	return f, nil
+}
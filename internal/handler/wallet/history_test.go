@@ -0,0 +1,110 @@
+package wallet_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/totorialman/go-test-ac/internal/domain"
+	walletErrors "github.com/totorialman/go-test-ac/internal/errors/wallet"
+	"github.com/totorialman/go-test-ac/internal/handler/wallet"
+	"github.com/totorialman/go-test-ac/internal/logging"
+	walletUsecase "github.com/totorialman/go-test-ac/internal/usecase/wallet"
+)
+
+func TestHandler_History(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUsecase := NewMockusecase(ctrl)
+	mockAuthorizer := NewMockauthorizer(ctrl)
+	h := wallet.NewHandler(mockUsecase, mockAuthorizer, logging.Discard())
+
+	walletID := uuid.New()
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		walletID       string
+		query          string
+		mockReturn     func()
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:     "filtered page",
+			walletID: walletID.String(),
+			query:    "type=DEPOSIT&limit=1&from=2026-01-01T00:00:00Z",
+			mockReturn: func() {
+				mockUsecase.EXPECT().
+					History(gomock.Any(), walletUsecase.HistoryFilter{
+						WalletID:      walletID,
+						OperationType: domain.Deposit,
+						From:          from,
+						Limit:         1,
+					}).
+					Return(walletUsecase.HistoryPage{
+						Transactions: []walletUsecase.Transaction{{WalletID: walletID, OperationType: domain.Deposit, Amount: 10, BalanceAfter: 10}},
+						NextCursor:   "next",
+					}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"nextCursor":"next"`,
+		},
+		{
+			name:           "invalid limit",
+			walletID:       walletID.String(),
+			query:          "limit=abc",
+			mockReturn:     func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `"code":"INVALID_FILTER"`,
+		},
+		{
+			name:     "invalid cursor",
+			walletID: walletID.String(),
+			query:    "cursor=abc",
+			mockReturn: func() {
+				mockUsecase.EXPECT().
+					History(gomock.Any(), gomock.Any()).
+					Return(walletUsecase.HistoryPage{}, walletErrors.ErrInvalidCursor)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `"code":"INVALID_CURSOR"`,
+		},
+		{
+			name:     "wallet not found",
+			walletID: walletID.String(),
+			mockReturn: func() {
+				mockUsecase.EXPECT().
+					History(gomock.Any(), gomock.Any()).
+					Return(walletUsecase.HistoryPage{}, walletErrors.ErrWalletNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `"code":"WALLET_NOT_FOUND"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockReturn()
+
+			req := httptest.NewRequest(http.MethodGet, "/transactions?"+tt.query, nil)
+			req = mux.SetURLVars(req, map[string]string{
+				"WALLET_UUID": tt.walletID,
+			})
+			w := httptest.NewRecorder()
+
+			h.History(w, req)
+
+			resp := w.Result()
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+			assert.Contains(t, w.Body.String(), tt.expectedBody)
+		})
+	}
+}
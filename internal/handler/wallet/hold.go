@@ -0,0 +1,109 @@
+package wallet
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+
+	walletErrors "github.com/totorialman/go-test-ac/internal/errors/wallet"
+	"github.com/totorialman/go-test-ac/internal/handler/response"
+	"github.com/totorialman/go-test-ac/internal/usecase/wallet"
+)
+
+func (h *Handler) Authorize(w http.ResponseWriter, r *http.Request) {
+	idStr := mux.Vars(r)["WALLET_UUID"]
+
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		h.logger.WarnContext(r.Context(), "invalid uuid", "error", err)
+		response.Error(w, r, walletErrors.ErrInvalidWalletID)
+		return
+	}
+
+	var req AuthorizeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.WarnContext(r.Context(), "decode error", "error", err)
+		response.Error(w, r, walletErrors.ErrMalformedRequest)
+		return
+	}
+
+	h.logger.DebugContext(r.Context(), "authorize request", "wallet_id", id, "amount", req.Amount, "currency", req.Currency, "ttl_seconds", req.TTLSeconds)
+
+	hold, err := h.usecase.Authorize(r.Context(), wallet.Authorization{
+		WalletID: id,
+		Amount:   req.Amount,
+		Currency: req.Currency,
+		TTL:      time.Duration(req.TTLSeconds) * time.Second,
+	})
+	if err != nil {
+		h.logger.Log(r.Context(), response.LogLevel(err), "authorize error", "error", err)
+		response.Error(w, r, err)
+		return
+	}
+
+	h.logger.InfoContext(r.Context(), "authorize success", "hold_id", hold.ID, "wallet_id", id, "available", hold.Balance.Available)
+	h.writeHold(w, r, http.StatusCreated, hold)
+}
+
+func (h *Handler) Capture(w http.ResponseWriter, r *http.Request) {
+	holdID, err := uuid.Parse(mux.Vars(r)["HOLD_UUID"])
+	if err != nil {
+		h.logger.WarnContext(r.Context(), "invalid uuid", "error", err)
+		response.Error(w, r, walletErrors.ErrInvalidHoldID)
+		return
+	}
+
+	// The body is optional: an empty one captures the whole hold.
+	var req CaptureRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		h.logger.WarnContext(r.Context(), "decode error", "error", err)
+		response.Error(w, r, walletErrors.ErrMalformedRequest)
+		return
+	}
+
+	h.logger.DebugContext(r.Context(), "capture request", "hold_id", holdID, "amount", req.Amount)
+
+	hold, err := h.usecase.Capture(r.Context(), holdID, req.Amount)
+	if err != nil {
+		h.logger.Log(r.Context(), response.LogLevel(err), "capture error", "error", err)
+		response.Error(w, r, err)
+		return
+	}
+
+	h.logger.InfoContext(r.Context(), "capture success", "hold_id", hold.ID, "captured", hold.CapturedAmount, "balance", hold.Balance.Amount)
+	h.writeHold(w, r, http.StatusOK, hold)
+}
+
+func (h *Handler) Void(w http.ResponseWriter, r *http.Request) {
+	holdID, err := uuid.Parse(mux.Vars(r)["HOLD_UUID"])
+	if err != nil {
+		h.logger.WarnContext(r.Context(), "invalid uuid", "error", err)
+		response.Error(w, r, walletErrors.ErrInvalidHoldID)
+		return
+	}
+
+	h.logger.DebugContext(r.Context(), "void request", "hold_id", holdID)
+
+	hold, err := h.usecase.Void(r.Context(), holdID)
+	if err != nil {
+		h.logger.Log(r.Context(), response.LogLevel(err), "void error", "error", err)
+		response.Error(w, r, err)
+		return
+	}
+
+	h.logger.InfoContext(r.Context(), "void success", "hold_id", hold.ID, "available", hold.Balance.Available)
+	h.writeHold(w, r, http.StatusOK, hold)
+}
+
+func (h *Handler) writeHold(w http.ResponseWriter, r *http.Request, status int, hold wallet.Hold) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(newHoldResponse(hold)); err != nil {
+		h.logger.ErrorContext(r.Context(), "JSON encode error", "error", err)
+	}
+}
@@ -0,0 +1,150 @@
+package wallet_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/totorialman/go-test-ac/internal/domain"
+	walletErrors "github.com/totorialman/go-test-ac/internal/errors/wallet"
+	"github.com/totorialman/go-test-ac/internal/handler/wallet"
+	"github.com/totorialman/go-test-ac/internal/logging"
+	walletUsecase "github.com/totorialman/go-test-ac/internal/usecase/wallet"
+)
+
+func TestHandler_Authorize(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUsecase := NewMockusecase(ctrl)
+	mockAuthorizer := NewMockauthorizer(ctrl)
+	h := wallet.NewHandler(mockUsecase, mockAuthorizer, logging.Discard())
+
+	walletID := uuid.New()
+
+	tests := []struct {
+		name           string
+		body           string
+		mockReturn     func()
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name: "hold placed",
+			body: `{"amount":300,"ttlSeconds":60}`,
+			mockReturn: func() {
+				mockUsecase.EXPECT().
+					Authorize(gomock.Any(), walletUsecase.Authorization{WalletID: walletID, Amount: 300, TTL: time.Minute}).
+					Return(walletUsecase.Hold{
+						WalletID: walletID,
+						Amount:   300,
+						Status:   domain.HoldActive,
+						Balance:  walletUsecase.Balance{Amount: 1000, Available: 700, Currency: "RUB", Exponent: 2},
+					}, nil)
+			},
+			expectedStatus: http.StatusCreated,
+			expectedBody:   `"ledger":1000,"available":700`,
+		},
+		{
+			name: "not enough funds",
+			body: `{"amount":300}`,
+			mockReturn: func() {
+				mockUsecase.EXPECT().
+					Authorize(gomock.Any(), gomock.Any()).
+					Return(walletUsecase.Hold{}, walletErrors.ErrNotEnoughFunds)
+			},
+			expectedStatus: http.StatusConflict,
+			expectedBody:   `"code":"NOT_ENOUGH_FUNDS"`,
+		},
+		{
+			name:           "bad json",
+			body:           `{`,
+			mockReturn:     func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `"code":"MALFORMED_REQUEST"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockReturn()
+
+			req := httptest.NewRequest(http.MethodPost, "/holds", strings.NewReader(tt.body))
+			req = mux.SetURLVars(req, map[string]string{"WALLET_UUID": walletID.String()})
+			w := httptest.NewRecorder()
+
+			h.Authorize(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Result().StatusCode)
+			assert.Contains(t, w.Body.String(), tt.expectedBody)
+		})
+	}
+}
+
+func TestHandler_CaptureAndVoid(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUsecase := NewMockusecase(ctrl)
+	mockAuthorizer := NewMockauthorizer(ctrl)
+	h := wallet.NewHandler(mockUsecase, mockAuthorizer, logging.Discard())
+
+	holdID := uuid.New()
+	vars := map[string]string{"HOLD_UUID": holdID.String()}
+
+	t.Run("capture without body settles the whole hold", func(t *testing.T) {
+		mockUsecase.EXPECT().
+			Capture(gomock.Any(), holdID, int64(0)).
+			Return(walletUsecase.Hold{ID: holdID, Amount: 300, CapturedAmount: 300, Status: domain.HoldCaptured}, nil)
+
+		req := mux.SetURLVars(httptest.NewRequest(http.MethodPost, "/capture", nil), vars)
+		w := httptest.NewRecorder()
+		h.Capture(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Result().StatusCode)
+		assert.Contains(t, w.Body.String(), `"capturedAmount":300`)
+	})
+
+	t.Run("capture above hold", func(t *testing.T) {
+		mockUsecase.EXPECT().
+			Capture(gomock.Any(), holdID, int64(500)).
+			Return(walletUsecase.Hold{}, walletErrors.ErrCaptureExceedsHold)
+
+		req := mux.SetURLVars(httptest.NewRequest(http.MethodPost, "/capture", strings.NewReader(`{"amount":500}`)), vars)
+		w := httptest.NewRecorder()
+		h.Capture(w, req)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Result().StatusCode)
+	})
+
+	t.Run("void expired hold", func(t *testing.T) {
+		mockUsecase.EXPECT().
+			Void(gomock.Any(), holdID).
+			Return(walletUsecase.Hold{}, walletErrors.ErrHoldExpired)
+
+		req := mux.SetURLVars(httptest.NewRequest(http.MethodPost, "/void", nil), vars)
+		w := httptest.NewRecorder()
+		h.Void(w, req)
+
+		assert.Equal(t, http.StatusConflict, w.Result().StatusCode)
+	})
+
+	t.Run("unknown hold", func(t *testing.T) {
+		mockUsecase.EXPECT().
+			Void(gomock.Any(), holdID).
+			Return(walletUsecase.Hold{}, walletErrors.ErrHoldNotFound)
+
+		req := mux.SetURLVars(httptest.NewRequest(http.MethodPost, "/void", nil), vars)
+		w := httptest.NewRecorder()
+		h.Void(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Result().StatusCode)
+	})
+}
@@ -0,0 +1,112 @@
+package wallet
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+
+	walletErrors "github.com/totorialman/go-test-ac/internal/errors/wallet"
+	"github.com/totorialman/go-test-ac/internal/handler/response"
+	"github.com/totorialman/go-test-ac/internal/usecase/wallet"
+)
+
+func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
+	var req CreateWalletRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.WarnContext(r.Context(), "decode error", "error", err)
+		response.Error(w, r, walletErrors.ErrMalformedRequest)
+		return
+	}
+
+	h.logger.DebugContext(r.Context(), "create wallet request", "owner_id", req.OwnerID, "currency", req.Currency)
+
+	info, err := h.usecase.Create(r.Context(), wallet.NewWallet{
+		OwnerID:  req.OwnerID,
+		Currency: req.Currency,
+		Metadata: req.Metadata,
+	})
+	if err != nil {
+		h.logger.Log(r.Context(), response.LogLevel(err), "create wallet error", "error", err)
+		response.Error(w, r, err)
+		return
+	}
+
+	h.logger.InfoContext(r.Context(), "create wallet success", "wallet_id", info.ID, "owner_id", info.OwnerID)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Location", "/api/v1/wallets/"+info.ID.String())
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(newWalletDetailsResponse(info)); err != nil {
+		h.logger.ErrorContext(r.Context(), "JSON encode error", "error", err)
+	}
+}
+
+func (h *Handler) UpdateStatus(w http.ResponseWriter, r *http.Request) {
+	idStr := mux.Vars(r)["WALLET_UUID"]
+
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		h.logger.WarnContext(r.Context(), "invalid uuid", "error", err)
+		response.Error(w, r, walletErrors.ErrInvalidWalletID)
+		return
+	}
+
+	var req UpdateWalletRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.WarnContext(r.Context(), "decode error", "error", err)
+		response.Error(w, r, walletErrors.ErrMalformedRequest)
+		return
+	}
+
+	h.logger.DebugContext(r.Context(), "update wallet request", "wallet_id", id, "status", req.Status)
+
+	info, err := h.usecase.UpdateStatus(r.Context(), id, req.Status)
+	if err != nil {
+		h.logger.Log(r.Context(), response.LogLevel(err), "update wallet error", "error", err)
+		response.Error(w, r, err)
+		return
+	}
+
+	h.logger.InfoContext(r.Context(), "update wallet success", "wallet_id", id, "status", info.Status)
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(newWalletDetailsResponse(info)); err != nil {
+		h.logger.ErrorContext(r.Context(), "JSON encode error", "error", err)
+	}
+}
+
+func (h *Handler) SetHot(w http.ResponseWriter, r *http.Request) {
+	idStr := mux.Vars(r)["WALLET_UUID"]
+
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		h.logger.WarnContext(r.Context(), "invalid uuid", "error", err)
+		response.Error(w, r, walletErrors.ErrInvalidWalletID)
+		return
+	}
+
+	var req SetHotRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Hot == nil {
+		h.logger.WarnContext(r.Context(), "decode error", "error", err)
+		response.Error(w, r, walletErrors.ErrMalformedRequest)
+		return
+	}
+
+	h.logger.DebugContext(r.Context(), "set hot request", "wallet_id", id, "hot", *req.Hot)
+
+	info, err := h.usecase.SetHot(r.Context(), id, *req.Hot)
+	if err != nil {
+		h.logger.Log(r.Context(), response.LogLevel(err), "set hot error", "error", err)
+		response.Error(w, r, err)
+		return
+	}
+
+	h.logger.InfoContext(r.Context(), "set hot success", "wallet_id", id, "hot", info.Hot)
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(newWalletDetailsResponse(info)); err != nil {
+		h.logger.ErrorContext(r.Context(), "JSON encode error", "error", err)
+	}
+}
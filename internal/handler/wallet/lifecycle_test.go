@@ -0,0 +1,217 @@
+package wallet_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/totorialman/go-test-ac/internal/domain"
+	walletErrors "github.com/totorialman/go-test-ac/internal/errors/wallet"
+	"github.com/totorialman/go-test-ac/internal/handler/wallet"
+	"github.com/totorialman/go-test-ac/internal/logging"
+	walletUsecase "github.com/totorialman/go-test-ac/internal/usecase/wallet"
+)
+
+func TestHandler_Create(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUsecase := NewMockusecase(ctrl)
+	mockAuthorizer := NewMockauthorizer(ctrl)
+	h := wallet.NewHandler(mockUsecase, mockAuthorizer, logging.Discard())
+
+	walletID := uuid.New()
+
+	tests := []struct {
+		name           string
+		body           string
+		mockReturn     func()
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name: "created",
+			body: `{"ownerId":"owner-1","currency":"USD","metadata":{"tier":"gold"}}`,
+			mockReturn: func() {
+				mockUsecase.EXPECT().
+					Create(gomock.Any(), walletUsecase.NewWallet{
+						OwnerID:  "owner-1",
+						Currency: "USD",
+						Metadata: []byte(`{"tier":"gold"}`),
+					}).
+					Return(walletUsecase.WalletInfo{
+						ID:       walletID,
+						OwnerID:  "owner-1",
+						Status:   domain.WalletActive,
+						Metadata: []byte(`{"tier":"gold"}`),
+						Balance:  walletUsecase.Balance{Currency: "USD", Exponent: 2},
+					}, nil)
+			},
+			expectedStatus: http.StatusCreated,
+			expectedBody:   `"status":"ACTIVE"`,
+		},
+		{
+			name: "missing owner",
+			body: `{}`,
+			mockReturn: func() {
+				mockUsecase.EXPECT().
+					Create(gomock.Any(), gomock.Any()).
+					Return(walletUsecase.WalletInfo{}, walletErrors.ErrInvalidOwner)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `"code":"INVALID_OWNER"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockReturn()
+
+			req := httptest.NewRequest(http.MethodPost, "/wallets", strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+
+			h.Create(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Result().StatusCode)
+			assert.Contains(t, w.Body.String(), tt.expectedBody)
+		})
+	}
+}
+
+func TestHandler_UpdateStatus(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUsecase := NewMockusecase(ctrl)
+	mockAuthorizer := NewMockauthorizer(ctrl)
+	h := wallet.NewHandler(mockUsecase, mockAuthorizer, logging.Discard())
+
+	walletID := uuid.New()
+
+	tests := []struct {
+		name           string
+		body           string
+		mockReturn     func()
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name: "frozen",
+			body: `{"status":"FROZEN"}`,
+			mockReturn: func() {
+				mockUsecase.EXPECT().
+					UpdateStatus(gomock.Any(), walletID, domain.WalletFrozen).
+					Return(walletUsecase.WalletInfo{ID: walletID, Status: domain.WalletFrozen}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"status":"FROZEN"`,
+		},
+		{
+			name: "close with funds",
+			body: `{"status":"CLOSED"}`,
+			mockReturn: func() {
+				mockUsecase.EXPECT().
+					UpdateStatus(gomock.Any(), walletID, domain.WalletClosed).
+					Return(walletUsecase.WalletInfo{}, walletErrors.ErrWalletNotEmpty)
+			},
+			expectedStatus: http.StatusConflict,
+			expectedBody:   `"code":"WALLET_NOT_EMPTY"`,
+		},
+		{
+			name: "unknown status",
+			body: `{"status":"DELETED"}`,
+			mockReturn: func() {
+				mockUsecase.EXPECT().
+					UpdateStatus(gomock.Any(), walletID, "DELETED").
+					Return(walletUsecase.WalletInfo{}, walletErrors.ErrInvalidStatus)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `"code":"INVALID_STATUS"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockReturn()
+
+			req := httptest.NewRequest(http.MethodPatch, "/wallets", strings.NewReader(tt.body))
+			req = mux.SetURLVars(req, map[string]string{"WALLET_UUID": walletID.String()})
+			w := httptest.NewRecorder()
+
+			h.UpdateStatus(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Result().StatusCode)
+			assert.Contains(t, w.Body.String(), tt.expectedBody)
+		})
+	}
+}
+
+func TestHandler_SetHot(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUsecase := NewMockusecase(ctrl)
+	mockAuthorizer := NewMockauthorizer(ctrl)
+	h := wallet.NewHandler(mockUsecase, mockAuthorizer, logging.Discard())
+
+	walletID := uuid.New()
+
+	tests := []struct {
+		name           string
+		body           string
+		mockReturn     func()
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name: "hot",
+			body: `{"hot":true}`,
+			mockReturn: func() {
+				mockUsecase.EXPECT().
+					SetHot(gomock.Any(), walletID, true).
+					Return(walletUsecase.WalletInfo{ID: walletID, Hot: true}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"hot":true`,
+		},
+		{
+			name: "not found",
+			body: `{"hot":false}`,
+			mockReturn: func() {
+				mockUsecase.EXPECT().
+					SetHot(gomock.Any(), walletID, false).
+					Return(walletUsecase.WalletInfo{}, walletErrors.ErrWalletNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `"code":"WALLET_NOT_FOUND"`,
+		},
+		{
+			name:           "missing flag",
+			body:           `{}`,
+			mockReturn:     func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `"code":"MALFORMED_REQUEST"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockReturn()
+
+			req := httptest.NewRequest(http.MethodPut, "/wallets", strings.NewReader(tt.body))
+			req = mux.SetURLVars(req, map[string]string{"WALLET_UUID": walletID.String()})
+			w := httptest.NewRecorder()
+
+			h.SetHot(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Result().StatusCode)
+			assert.Contains(t, w.Body.String(), tt.expectedBody)
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package wallet
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+
+	walletErrors "github.com/totorialman/go-test-ac/internal/errors/wallet"
+	"github.com/totorialman/go-test-ac/internal/handler/response"
+	"github.com/totorialman/go-test-ac/internal/usecase/wallet"
+)
+
+func (h *Handler) GetLimits(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(mux.Vars(r)["WALLET_UUID"])
+	if err != nil {
+		h.logger.WarnContext(r.Context(), "invalid uuid", "error", err)
+		response.Error(w, r, walletErrors.ErrInvalidWalletID)
+		return
+	}
+
+	h.logger.DebugContext(r.Context(), "get limits request", "wallet_id", id)
+
+	limits, err := h.usecase.GetLimits(r.Context(), id)
+	if err != nil {
+		h.logger.Log(r.Context(), response.LogLevel(err), "get limits error", "error", err)
+		response.Error(w, r, err)
+		return
+	}
+
+	h.writeLimits(w, r, id, limits)
+}
+
+func (h *Handler) SetLimits(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(mux.Vars(r)["WALLET_UUID"])
+	if err != nil {
+		h.logger.WarnContext(r.Context(), "invalid uuid", "error", err)
+		response.Error(w, r, walletErrors.ErrInvalidWalletID)
+		return
+	}
+
+	var req LimitsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.WarnContext(r.Context(), "decode error", "error", err)
+		response.Error(w, r, walletErrors.ErrMalformedRequest)
+		return
+	}
+
+	h.logger.DebugContext(r.Context(), "set limits request", "wallet_id", id)
+
+	limits, err := h.usecase.SetLimits(r.Context(), id, wallet.Limits{
+		MaxWithdrawAmount: req.MaxWithdrawAmount,
+		MaxDailyWithdraw:  req.MaxDailyWithdraw,
+		MaxBalance:        req.MaxBalance,
+		MinBalance:        req.MinBalance,
+	})
+	if err != nil {
+		h.logger.Log(r.Context(), response.LogLevel(err), "set limits error", "error", err)
+		response.Error(w, r, err)
+		return
+	}
+
+	h.logger.InfoContext(r.Context(), "set limits success", "wallet_id", id)
+	h.writeLimits(w, r, id, limits)
+}
+
+func (h *Handler) writeLimits(w http.ResponseWriter, r *http.Request, id uuid.UUID, limits wallet.Limits) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(newLimitsResponse(id, limits)); err != nil {
+		h.logger.ErrorContext(r.Context(), "JSON encode error", "error", err)
+	}
+}
@@ -0,0 +1,107 @@
+package wallet_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+
+	walletErrors "github.com/totorialman/go-test-ac/internal/errors/wallet"
+	"github.com/totorialman/go-test-ac/internal/handler/wallet"
+	"github.com/totorialman/go-test-ac/internal/logging"
+	walletUsecase "github.com/totorialman/go-test-ac/internal/usecase/wallet"
+)
+
+func TestHandler_SetLimits(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUsecase := NewMockusecase(ctrl)
+	mockAuthorizer := NewMockauthorizer(ctrl)
+	h := wallet.NewHandler(mockUsecase, mockAuthorizer, logging.Discard())
+
+	walletID := uuid.New()
+	maxWithdraw := int64(500)
+	daily := int64(10000)
+
+	tests := []struct {
+		name           string
+		body           string
+		mockReturn     func()
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name: "overrides stored",
+			body: `{"maxWithdrawAmount":500}`,
+			mockReturn: func() {
+				mockUsecase.EXPECT().
+					SetLimits(gomock.Any(), walletID, walletUsecase.Limits{MaxWithdrawAmount: &maxWithdraw}).
+					Return(walletUsecase.Limits{MaxWithdrawAmount: &maxWithdraw, MaxDailyWithdraw: &daily}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"maxWithdrawAmount":500,"maxDailyWithdraw":10000,"maxBalance":null`,
+		},
+		{
+			name: "invalid limits",
+			body: `{"maxWithdrawAmount":0}`,
+			mockReturn: func() {
+				mockUsecase.EXPECT().
+					SetLimits(gomock.Any(), walletID, gomock.Any()).
+					Return(walletUsecase.Limits{}, walletErrors.ErrInvalidLimits)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `"code":"INVALID_LIMITS"`,
+		},
+		{
+			name:           "bad json",
+			body:           `{`,
+			mockReturn:     func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `"code":"MALFORMED_REQUEST"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockReturn()
+
+			req := httptest.NewRequest(http.MethodPut, "/limits", strings.NewReader(tt.body))
+			req = mux.SetURLVars(req, map[string]string{"WALLET_UUID": walletID.String()})
+			w := httptest.NewRecorder()
+
+			h.SetLimits(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Result().StatusCode)
+			assert.Contains(t, w.Body.String(), tt.expectedBody)
+		})
+	}
+}
+
+func TestHandler_OperateLimitExceeded(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUsecase := NewMockusecase(ctrl)
+	mockAuthorizer := NewMockauthorizer(ctrl)
+	h := wallet.NewHandler(mockUsecase, mockAuthorizer, logging.Discard())
+
+	mockAuthorizer.EXPECT().Authorize(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+	mockUsecase.EXPECT().
+		Operate(gomock.Any(), gomock.Any()).
+		Return(walletUsecase.Balance{}, walletErrors.ErrLimitExceeded)
+
+	body := `{"walletId":"` + uuid.NewString() + `","operationType":"WITHDRAW","amount":100}`
+	req := httptest.NewRequest(http.MethodPost, "/operate", strings.NewReader(body))
+	w := httptest.NewRecorder()
+
+	h.Operate(w, req)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Result().StatusCode)
+	assert.Contains(t, w.Body.String(), `"code":"LIMIT_EXCEEDED"`)
+}
@@ -0,0 +1,58 @@
+package wallet
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+
+	walletErrors "github.com/totorialman/go-test-ac/internal/errors/wallet"
+	"github.com/totorialman/go-test-ac/internal/handler/response"
+)
+
+func (h *Handler) Reverse(w http.ResponseWriter, r *http.Request) {
+	txID, err := uuid.Parse(mux.Vars(r)["TRANSACTION_UUID"])
+	if err != nil {
+		h.logger.WarnContext(r.Context(), "invalid uuid", "error", err)
+		response.Error(w, r, walletErrors.ErrInvalidTransactionID)
+		return
+	}
+
+	// The body is optional: an empty one reverses the whole transaction.
+	var req ReverseRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		h.logger.WarnContext(r.Context(), "decode error", "error", err)
+		response.Error(w, r, walletErrors.ErrMalformedRequest)
+		return
+	}
+
+	h.logger.DebugContext(r.Context(), "reverse request", "transaction_id", txID, "amount", req.Amount)
+
+	res, err := h.usecase.Reverse(r.Context(), txID, req.Amount)
+	if err != nil {
+		h.logger.Log(r.Context(), response.LogLevel(err), "reverse error", "error", err)
+		response.Error(w, r, err)
+		return
+	}
+
+	h.logger.InfoContext(r.Context(), "reverse success",
+		"transaction_id", txID,
+		"reversal_id", res.Transaction.ID,
+		"amount", res.Transaction.Amount,
+		"balance", res.Balance.Amount,
+	)
+
+	resp := ReversalResponse{
+		Transaction: newTransactionResponse(res.Transaction),
+		Balance:     newWalletResponse(res.Transaction.WalletID, res.Balance),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		h.logger.ErrorContext(r.Context(), "JSON encode error", "error", err)
+	}
+}
@@ -0,0 +1,132 @@
+package wallet_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/totorialman/go-test-ac/internal/domain"
+	walletErrors "github.com/totorialman/go-test-ac/internal/errors/wallet"
+	"github.com/totorialman/go-test-ac/internal/handler/wallet"
+	"github.com/totorialman/go-test-ac/internal/logging"
+	walletUsecase "github.com/totorialman/go-test-ac/internal/usecase/wallet"
+)
+
+func TestHandler_Reverse(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUsecase := NewMockusecase(ctrl)
+	mockAuthorizer := NewMockauthorizer(ctrl)
+	h := wallet.NewHandler(mockUsecase, mockAuthorizer, logging.Discard())
+
+	walletID := uuid.New()
+	txID := uuid.New()
+
+	tests := []struct {
+		name           string
+		txID           string
+		body           string
+		mockReturn     func()
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name: "full reversal with empty body",
+			txID: txID.String(),
+			mockReturn: func() {
+				mockUsecase.EXPECT().
+					Reverse(gomock.Any(), txID, int64(0)).
+					Return(walletUsecase.Reversal{
+						Transaction: walletUsecase.Transaction{
+							WalletID:      walletID,
+							OperationType: domain.DepositReversal,
+							Amount:        100,
+							BalanceAfter:  400,
+							ReversalOf:    uuid.NullUUID{UUID: txID, Valid: true},
+						},
+						Balance: walletUsecase.Balance{Amount: 400, Available: 400, Currency: "RUB", Exponent: 2},
+					}, nil)
+			},
+			expectedStatus: http.StatusCreated,
+			expectedBody:   `"reversalOf":"` + txID.String() + `"`,
+		},
+		{
+			name: "partial refund",
+			txID: txID.String(),
+			body: `{"amount":30}`,
+			mockReturn: func() {
+				mockUsecase.EXPECT().
+					Reverse(gomock.Any(), txID, int64(30)).
+					Return(walletUsecase.Reversal{
+						Transaction: walletUsecase.Transaction{WalletID: walletID, OperationType: domain.WithdrawReversal, Amount: 30},
+						Balance:     walletUsecase.Balance{Amount: 530, Available: 530, Currency: "RUB", Exponent: 2},
+					}, nil)
+			},
+			expectedStatus: http.StatusCreated,
+			expectedBody:   `"balance":{"walletId":"` + walletID.String() + `","balance":530`,
+		},
+		{
+			name: "already reversed",
+			txID: txID.String(),
+			mockReturn: func() {
+				mockUsecase.EXPECT().
+					Reverse(gomock.Any(), txID, int64(0)).
+					Return(walletUsecase.Reversal{}, walletErrors.ErrAlreadyReversed)
+			},
+			expectedStatus: http.StatusConflict,
+			expectedBody:   `"code":"ALREADY_REVERSED"`,
+		},
+		{
+			name: "not found",
+			txID: txID.String(),
+			mockReturn: func() {
+				mockUsecase.EXPECT().
+					Reverse(gomock.Any(), txID, int64(0)).
+					Return(walletUsecase.Reversal{}, walletErrors.ErrTransactionNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `"code":"TRANSACTION_NOT_FOUND"`,
+		},
+		{
+			name: "exceeds amount",
+			txID: txID.String(),
+			body: `{"amount":500}`,
+			mockReturn: func() {
+				mockUsecase.EXPECT().
+					Reverse(gomock.Any(), txID, int64(500)).
+					Return(walletUsecase.Reversal{}, walletErrors.ErrReversalExceedsAmount)
+			},
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedBody:   `"code":"REVERSAL_EXCEEDS_AMOUNT"`,
+		},
+		{
+			name:           "invalid uuid",
+			txID:           "invalid",
+			mockReturn:     func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `"code":"INVALID_TRANSACTION_ID"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockReturn()
+
+			req := httptest.NewRequest(http.MethodPost, "/reverse", strings.NewReader(tt.body))
+			req = mux.SetURLVars(req, map[string]string{"TRANSACTION_UUID": tt.txID})
+			w := httptest.NewRecorder()
+
+			h.Reverse(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Result().StatusCode)
+			assert.Contains(t, w.Body.String(), tt.expectedBody)
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package wallet_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/totorialman/go-test-ac/internal/domain"
+	"github.com/totorialman/go-test-ac/internal/handler/wallet"
+	"github.com/totorialman/go-test-ac/internal/logging"
+	walletUsecase "github.com/totorialman/go-test-ac/internal/usecase/wallet"
+)
+
+func TestHandler_OperateTracing(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	otel.SetTracerProvider(tp)
+	defer tp.Shutdown(context.Background())
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUsecase := NewMockusecase(ctrl)
+	mockAuthorizer := NewMockauthorizer(ctrl)
+	h := wallet.NewHandler(mockUsecase, mockAuthorizer, logging.Discard())
+
+	walletID := uuid.New()
+	var usecaseSpan trace.SpanContext
+	mockAuthorizer.EXPECT().Authorize(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+	mockUsecase.EXPECT().
+		Operate(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, _ walletUsecase.Wallet) (walletUsecase.Balance, error) {
+			usecaseSpan = trace.SpanContextFromContext(ctx)
+			return walletUsecase.Balance{Amount: 100, Currency: "RUB"}, nil
+		})
+
+	body := `{"walletId":"` + walletID.String() + `","operationType":"DEPOSIT","amount":100}`
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/wallet", strings.NewReader(body))
+	h.Operate(httptest.NewRecorder(), req)
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 1)
+
+	span := spans[0]
+	assert.Equal(t, "wallet.Handler.Operate", span.Name)
+	assert.Equal(t, span.SpanContext.SpanID(), usecaseSpan.SpanID())
+
+	attrs := map[string]string{}
+	for _, kv := range span.Attributes {
+		attrs[string(kv.Key)] = kv.Value.Emit()
+	}
+	assert.Equal(t, walletID.String(), attrs["wallet.id"])
+	assert.Equal(t, domain.Deposit, attrs["wallet.operation_type"])
+}
@@ -0,0 +1,51 @@
+package wallet
+
+import (
+	"encoding/json"
+	"net/http"
+
+	walletErrors "github.com/totorialman/go-test-ac/internal/errors/wallet"
+	"github.com/totorialman/go-test-ac/internal/handler/response"
+	"github.com/totorialman/go-test-ac/internal/usecase/wallet"
+)
+
+func (h *Handler) Transfer(w http.ResponseWriter, r *http.Request) {
+	var req TransferRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.WarnContext(r.Context(), "decode error", "error", err)
+		response.Error(w, r, walletErrors.ErrMalformedRequest)
+		return
+	}
+
+	h.logger.DebugContext(r.Context(), "transfer request", "from_wallet_id", req.FromID, "to_wallet_id", req.ToID, "amount", req.Amount)
+
+	res, err := h.usecase.Transfer(r.Context(), wallet.Transfer{
+		FromID:   req.FromID,
+		ToID:     req.ToID,
+		Amount:   req.Amount,
+		Currency: req.Currency,
+	})
+	if err != nil {
+		h.logger.Log(r.Context(), response.LogLevel(err), "transfer error", "error", err)
+		response.Error(w, r, err)
+		return
+	}
+
+	h.logger.InfoContext(r.Context(), "transfer success",
+		"from_wallet_id", req.FromID,
+		"from_balance", res.From.Amount,
+		"to_wallet_id", req.ToID,
+		"to_balance", res.To.Amount,
+		"currency", res.From.Currency,
+	)
+
+	resp := TransferResponse{
+		From: newWalletResponse(req.FromID, res.From),
+		To:   newWalletResponse(req.ToID, res.To),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		h.logger.ErrorContext(r.Context(), "JSON encode error", "error", err)
+	}
+}
@@ -0,0 +1,102 @@
+package wallet_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	walletErrors "github.com/totorialman/go-test-ac/internal/errors/wallet"
+	"github.com/totorialman/go-test-ac/internal/handler/wallet"
+	"github.com/totorialman/go-test-ac/internal/logging"
+	walletUsecase "github.com/totorialman/go-test-ac/internal/usecase/wallet"
+)
+
+func TestHandler_Transfer(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUsecase := NewMockusecase(ctrl)
+	mockAuthorizer := NewMockauthorizer(ctrl)
+	h := wallet.NewHandler(mockUsecase, mockAuthorizer, logging.Discard())
+
+	fromID := uuid.New()
+	toID := uuid.New()
+
+	tests := []struct {
+		name           string
+		reqBody        wallet.TransferRequest
+		mockReturn     func()
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:    "valid transfer",
+			reqBody: wallet.TransferRequest{FromID: fromID, ToID: toID, Amount: 100},
+			mockReturn: func() {
+				mockUsecase.EXPECT().
+					Transfer(gomock.Any(), walletUsecase.Transfer{FromID: fromID, ToID: toID, Amount: 100}).
+					Return(walletUsecase.TransferResult{
+						From: walletUsecase.Balance{Amount: 900, Currency: "RUB", Exponent: 2},
+						To:   walletUsecase.Balance{Amount: 100, Currency: "RUB", Exponent: 2},
+					}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"balance":900`,
+		},
+		{
+			name:    "not enough funds",
+			reqBody: wallet.TransferRequest{FromID: fromID, ToID: toID, Amount: 100},
+			mockReturn: func() {
+				mockUsecase.EXPECT().
+					Transfer(gomock.Any(), gomock.Any()).
+					Return(walletUsecase.TransferResult{}, walletErrors.ErrNotEnoughFunds)
+			},
+			expectedStatus: http.StatusConflict,
+			expectedBody:   `"code":"NOT_ENOUGH_FUNDS"`,
+		},
+		{
+			name:    "wallet not found",
+			reqBody: wallet.TransferRequest{FromID: fromID, ToID: toID, Amount: 100},
+			mockReturn: func() {
+				mockUsecase.EXPECT().
+					Transfer(gomock.Any(), gomock.Any()).
+					Return(walletUsecase.TransferResult{}, walletErrors.ErrWalletNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `"code":"WALLET_NOT_FOUND"`,
+		},
+		{
+			name:    "same wallet",
+			reqBody: wallet.TransferRequest{FromID: fromID, ToID: fromID, Amount: 100},
+			mockReturn: func() {
+				mockUsecase.EXPECT().
+					Transfer(gomock.Any(), gomock.Any()).
+					Return(walletUsecase.TransferResult{}, walletErrors.ErrSameWallet)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `"code":"SAME_WALLET"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockReturn()
+
+			bodyBytes, _ := json.Marshal(tt.reqBody)
+			req := httptest.NewRequest(http.MethodPost, "/transfers", bytes.NewReader(bodyBytes))
+			w := httptest.NewRecorder()
+
+			h.Transfer(w, req)
+
+			resp := w.Result()
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+			assert.Contains(t, w.Body.String(), tt.expectedBody)
+		})
+	}
+}
@@ -2,119 +2,147 @@ package wallet
 
 import (
 	"encoding/json"
-	"errors"
-	"log"
+	"log/slog"
 	"net/http"
 
 	"github.com/google/uuid"
 	"github.com/gorilla/mux"
 
+	"github.com/totorialman/go-test-ac/internal/auth"
+	"github.com/totorialman/go-test-ac/internal/domain"
 	walletErrors "github.com/totorialman/go-test-ac/internal/errors/wallet"
-    "github.com/totorialman/go-test-ac/internal/usecase/wallet"
-    "github.com/totorialman/go-test-ac/internal/domain"
+	"github.com/totorialman/go-test-ac/internal/handler/response"
+	"github.com/totorialman/go-test-ac/internal/tracing"
+	"github.com/totorialman/go-test-ac/internal/usecase/wallet"
 )
 
+const IdempotencyKeyHeader = "Idempotency-Key"
+
 type Handler struct {
-	usecase usecase
+	usecase    usecase
+	authorizer authorizer
+	limiter    rateLimiter
+	logger     *slog.Logger
+}
+
+type Option func(*Handler)
+
+// WithRateLimiter charges every wallet of a batch to its rate limit bucket.
+// The limiter middleware cannot see them, since they are inside the
+// operations array.
+func WithRateLimiter(l rateLimiter) Option {
+	return func(h *Handler) {
+		h.limiter = l
+	}
 }
 
-func NewHandler(usecase usecase) *Handler {
-	return &Handler{usecase: usecase}
+func NewHandler(usecase usecase, authorizer authorizer, logger *slog.Logger, opts ...Option) *Handler {
+	h := &Handler{usecase: usecase, authorizer: authorizer, logger: logger}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
 }
 
 func (h *Handler) Operate(w http.ResponseWriter, r *http.Request) {
+	ctx, span := tracing.Start(r.Context(), "wallet.Handler.Operate")
+	defer span.End()
+	r = r.WithContext(ctx)
+
 	var req WalletRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		log.Printf("decode error: %v", err)
-		w.WriteHeader(http.StatusBadRequest)
+		h.logger.WarnContext(r.Context(), "decode error", "error", err)
+		response.Error(w, r, walletErrors.ErrMalformedRequest)
 		return
 	}
 
 	if req.Amount <= 0 {
-		log.Printf("invalid amount: %d", req.Amount)
-        w.WriteHeader(http.StatusBadRequest)
+		h.logger.WarnContext(r.Context(), "invalid amount", "amount", req.Amount)
+		response.Error(w, r, walletErrors.ErrInvalidAmount)
 		return
 	}
 	if req.OperationType != domain.Deposit && req.OperationType != domain.Withdraw {
-		log.Printf("invalid operation type: %s", req.OperationType)
-		w.WriteHeader(http.StatusBadRequest)
+		h.logger.WarnContext(r.Context(), "invalid operation type", "operation_type", req.OperationType)
+		response.Error(w, r, walletErrors.ErrInvalidOperation)
 		return
 	}
 
-	log.Printf("operate request: id=%s type=%s amount=%d",
-		req.ID, req.OperationType, req.Amount,
+	span.SetAttributes(tracing.WalletID(req.ID), tracing.OperationType(req.OperationType))
+
+	h.logger.DebugContext(r.Context(), "operate request",
+		"wallet_id", req.ID,
+		"operation_type", req.OperationType,
+		"amount", req.Amount,
+		"currency", req.Currency,
 	)
 
+	scope := auth.ScopeDeposit
+	if req.OperationType == domain.Withdraw {
+		scope = auth.ScopeWithdraw
+	}
+	if err := h.authorizer.Authorize(r.Context(), req.ID, scope); err != nil {
+		h.logger.Log(r.Context(), response.LogLevel(err), "operate not authorized", "error", err)
+		tracing.RecordError(span, err)
+		response.Error(w, r, err)
+		return
+	}
+
 	wallet := wallet.Wallet{
-		ID:            req.ID,
-		OperationType: req.OperationType,
-		Amount:        req.Amount,
+		ID:             req.ID,
+		OperationType:  req.OperationType,
+		Amount:         req.Amount,
+		Currency:       req.Currency,
+		IdempotencyKey: r.Header.Get(IdempotencyKeyHeader),
 	}
 
 	newBalance, err := h.usecase.Operate(r.Context(), wallet)
 	if err != nil {
-		log.Printf("operate error: %v", err)
-
-		switch {
-		case errors.Is(err, walletErrors.ErrNotEnoughFunds):
-            w.WriteHeader(http.StatusConflict)
-		case errors.Is(err, walletErrors.ErrWalletNotFound):
-            w.WriteHeader(http.StatusNotFound)
-		case errors.Is(err, walletErrors.ErrInvalidAmount),
-			errors.Is(err, walletErrors.ErrInvalidOperation):
-            w.WriteHeader(http.StatusBadRequest)
-		default:
-            w.WriteHeader(http.StatusInternalServerError)
-		}
+		h.logger.Log(r.Context(), response.LogLevel(err), "operate error", "error", err)
+		tracing.RecordError(span, err)
+		response.Error(w, r, err)
 		return
 	}
 
-	log.Printf("operate success: id=%s new_balance=%d", wallet.ID, newBalance)
+	h.logger.InfoContext(r.Context(), "operate success", "wallet_id", wallet.ID, "balance", newBalance.Amount, "currency", newBalance.Currency)
 
-	res := WalletResponse{
-		ID:      wallet.ID,
-		Balance: newBalance,
-	}
+	res := newWalletResponse(wallet.ID, newBalance)
 
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(res); err != nil {
-		log.Printf("JSON encode error: %v", err)
+		h.logger.ErrorContext(r.Context(), "JSON encode error", "error", err)
 	}
 }
 
 func (h *Handler) Balance(w http.ResponseWriter, r *http.Request) {
 	idStr := mux.Vars(r)["WALLET_UUID"]
-	log.Printf("balance request: id=%s", idStr)
+	h.logger.DebugContext(r.Context(), "balance request", "wallet_id", idStr)
 
 	id, err := uuid.Parse(idStr)
 	if err != nil {
-		log.Printf("invalid uuid: %v", err)
-        w.WriteHeader(http.StatusBadRequest)
+		h.logger.WarnContext(r.Context(), "invalid uuid", "error", err)
+		response.Error(w, r, walletErrors.ErrInvalidWalletID)
 		return
 	}
 
-	balance, err := h.usecase.Balance(r.Context(), id)
-	if err != nil {
-		log.Printf("balance error: %v", err)
+	if err := h.authorizer.Authorize(r.Context(), id, auth.ScopeBalanceRead); err != nil {
+		h.logger.Log(r.Context(), response.LogLevel(err), "balance not authorized", "error", err)
+		response.Error(w, r, err)
+		return
+	}
 
-		if errors.Is(err, walletErrors.ErrWalletNotFound) {
-            w.WriteHeader(http.StatusNotFound)
-			return
-		}
-        w.WriteHeader(http.StatusInternalServerError)
+	info, err := h.usecase.Get(r.Context(), id)
+	if err != nil {
+		h.logger.Log(r.Context(), response.LogLevel(err), "balance error", "error", err)
+		response.Error(w, r, err)
 		return
 	}
 
-	log.Printf("balance success: id=%s balance=%d", id, balance)
+	h.logger.InfoContext(r.Context(), "balance success", "wallet_id", id, "status", info.Status, "balance", info.Balance.Amount, "currency", info.Balance.Currency)
 
-	res := WalletResponse{
-		ID:      id,
-		Balance: balance,
-	}
+	res := newWalletDetailsResponse(info)
 
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(res); err != nil {
-		log.Printf("JSON encode error: %v", err)
+		h.logger.ErrorContext(r.Context(), "JSON encode error", "error", err)
 	}
 }
-
@@ -13,9 +13,13 @@ import (
 	"github.com/gorilla/mux"
 	"github.com/stretchr/testify/assert"
 
+	"github.com/totorialman/go-test-ac/internal/auth"
 	"github.com/totorialman/go-test-ac/internal/domain"
+	authErrors "github.com/totorialman/go-test-ac/internal/errors/auth"
 	walletErrors "github.com/totorialman/go-test-ac/internal/errors/wallet"
 	"github.com/totorialman/go-test-ac/internal/handler/wallet"
+	"github.com/totorialman/go-test-ac/internal/logging"
+	walletUsecase "github.com/totorialman/go-test-ac/internal/usecase/wallet"
 )
 
 func TestHandler_Operate(t *testing.T) {
@@ -23,7 +27,8 @@ func TestHandler_Operate(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockUsecase := NewMockusecase(ctrl)
-	h := wallet.NewHandler(mockUsecase)
+	mockAuthorizer := NewMockauthorizer(ctrl)
+	h := wallet.NewHandler(mockUsecase, mockAuthorizer, logging.Discard())
 
 	walletID := uuid.New()
 
@@ -42,9 +47,10 @@ func TestHandler_Operate(t *testing.T) {
 				Amount:        500,
 			},
 			mockReturn: func() {
+				mockAuthorizer.EXPECT().Authorize(gomock.Any(), walletID, auth.ScopeDeposit).Return(nil)
 				mockUsecase.EXPECT().
 					Operate(gomock.Any(), gomock.Any()).
-					Return(int64(1500), nil)
+					Return(walletUsecase.Balance{Amount: 1500, Currency: "RUB", Exponent: 2}, nil)
 			},
 			expectedStatus: http.StatusOK,
 			expectedBody:   `"balance":1500`,
@@ -56,10 +62,106 @@ func TestHandler_Operate(t *testing.T) {
 				OperationType: domain.Deposit,
 				Amount:        -10,
 			},
-			mockReturn:     func() {}, 
+			mockReturn:     func() {},
 			expectedStatus: http.StatusBadRequest,
 			expectedBody:   walletErrors.ErrInvalidAmount.Error(),
 		},
+		{
+			name: "idempotency key reused",
+			reqBody: wallet.WalletRequest{
+				ID:            walletID,
+				OperationType: domain.Deposit,
+				Amount:        500,
+			},
+			mockReturn: func() {
+				mockAuthorizer.EXPECT().Authorize(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+				mockUsecase.EXPECT().
+					Operate(gomock.Any(), walletUsecase.Wallet{
+						ID:             walletID,
+						OperationType:  domain.Deposit,
+						Amount:         500,
+						IdempotencyKey: "key-1",
+					}).
+					Return(walletUsecase.Balance{}, walletErrors.ErrIdempotencyKeyReused)
+			},
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedBody:   `"code":"IDEMPOTENCY_KEY_REUSED"`,
+		},
+		{
+			name: "currency mismatch",
+			reqBody: wallet.WalletRequest{
+				ID:            walletID,
+				OperationType: domain.Deposit,
+				Amount:        500,
+				Currency:      "EUR",
+			},
+			mockReturn: func() {
+				mockAuthorizer.EXPECT().Authorize(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+				mockUsecase.EXPECT().
+					Operate(gomock.Any(), gomock.Any()).
+					Return(walletUsecase.Balance{}, walletErrors.ErrCurrencyMismatch)
+			},
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedBody:   `"code":"CURRENCY_MISMATCH"`,
+		},
+		{
+			name: "frozen wallet",
+			reqBody: wallet.WalletRequest{
+				ID:            walletID,
+				OperationType: domain.Withdraw,
+				Amount:        100,
+			},
+			mockReturn: func() {
+				mockAuthorizer.EXPECT().Authorize(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+				mockUsecase.EXPECT().
+					Operate(gomock.Any(), gomock.Any()).
+					Return(walletUsecase.Balance{}, walletErrors.ErrWalletFrozen)
+			},
+			expectedStatus: http.StatusLocked,
+			expectedBody:   `"code":"WALLET_FROZEN"`,
+		},
+		{
+			name: "closed wallet",
+			reqBody: wallet.WalletRequest{
+				ID:            walletID,
+				OperationType: domain.Deposit,
+				Amount:        100,
+			},
+			mockReturn: func() {
+				mockAuthorizer.EXPECT().Authorize(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+				mockUsecase.EXPECT().
+					Operate(gomock.Any(), gomock.Any()).
+					Return(walletUsecase.Balance{}, walletErrors.ErrWalletClosed)
+			},
+			expectedStatus: http.StatusGone,
+			expectedBody:   `"code":"WALLET_CLOSED"`,
+		},
+		{
+			name: "withdraw without scope",
+			reqBody: wallet.WalletRequest{
+				ID:            walletID,
+				OperationType: domain.Withdraw,
+				Amount:        100,
+			},
+			mockReturn: func() {
+				mockAuthorizer.EXPECT().Authorize(gomock.Any(), walletID, auth.ScopeWithdraw).Return(authErrors.ErrForbidden)
+			},
+			expectedStatus: http.StatusForbidden,
+			expectedBody:   `"code":"FORBIDDEN"`,
+		},
+		{
+			name: "wallet of another owner",
+			reqBody: wallet.WalletRequest{
+				ID:            walletID,
+				OperationType: domain.Deposit,
+				Amount:        100,
+			},
+			mockReturn: func() {
+				mockAuthorizer.EXPECT().Authorize(gomock.Any(), walletID, auth.ScopeDeposit).Return(authErrors.ErrWalletAccessDenied)
+			},
+			expectedStatus: http.StatusForbidden,
+			expectedBody:   `"code":"WALLET_ACCESS_DENIED"`,
+		},
 		{
 			name: "not enough funds",
 			reqBody: wallet.WalletRequest{
@@ -68,9 +170,10 @@ func TestHandler_Operate(t *testing.T) {
 				Amount:        1000,
 			},
 			mockReturn: func() {
+				mockAuthorizer.EXPECT().Authorize(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
 				mockUsecase.EXPECT().
 					Operate(gomock.Any(), gomock.Any()).
-					Return(int64(0), walletErrors.ErrNotEnoughFunds)
+					Return(walletUsecase.Balance{}, walletErrors.ErrNotEnoughFunds)
 			},
 			expectedStatus: http.StatusConflict,
 			expectedBody:   walletErrors.ErrNotEnoughFunds.Error(),
@@ -83,6 +186,7 @@ func TestHandler_Operate(t *testing.T) {
 
 			bodyBytes, _ := json.Marshal(tt.reqBody)
 			req := httptest.NewRequest(http.MethodPost, "/operate", bytes.NewReader(bodyBytes))
+			req.Header.Set(wallet.IdempotencyKeyHeader, "key-1")
 			w := httptest.NewRecorder()
 
 			h.Operate(w, req)
@@ -102,7 +206,8 @@ func TestHandler_Balance(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockUsecase := NewMockusecase(ctrl)
-	h := wallet.NewHandler(mockUsecase)
+	mockAuthorizer := NewMockauthorizer(ctrl)
+	h := wallet.NewHandler(mockUsecase, mockAuthorizer, logging.Discard())
 
 	validID := uuid.New()
 	invalidID := "not-a-uuid"
@@ -118,27 +223,60 @@ func TestHandler_Balance(t *testing.T) {
 			name:     "valid wallet",
 			walletID: validID.String(),
 			mockReturn: func() {
+				mockAuthorizer.EXPECT().Authorize(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
 				mockUsecase.EXPECT().
-					Balance(gomock.Any(), validID).
-					Return(int64(2000), nil)
+					Get(gomock.Any(), validID).
+					Return(walletUsecase.WalletInfo{
+						ID:      validID,
+						OwnerID: "owner-1",
+						Status:  domain.WalletActive,
+						Balance: walletUsecase.Balance{Amount: 2000, Available: 1500, Currency: "USD", Exponent: 2},
+					}, nil)
 			},
 			expectedStatus: http.StatusOK,
-			expectedBody:   `"balance":2000`,
+			expectedBody:   `"balance":2000,"ledger":2000,"available":1500,"currency":"USD","exponent":2`,
 		},
 		{
 			name:           "invalid uuid",
 			walletID:       invalidID,
-			mockReturn:     func() {}, 
+			mockReturn:     func() {},
 			expectedStatus: http.StatusBadRequest,
 			expectedBody:   "invalid wallet id",
 		},
+		{
+			name:     "wallet details",
+			walletID: validID.String(),
+			mockReturn: func() {
+				mockAuthorizer.EXPECT().Authorize(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+				mockUsecase.EXPECT().
+					Get(gomock.Any(), validID).
+					Return(walletUsecase.WalletInfo{
+						ID:       validID,
+						OwnerID:  "owner-1",
+						Status:   domain.WalletFrozen,
+						Metadata: []byte(`{"tier":"gold"}`),
+					}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"ownerId":"owner-1","status":"FROZEN","metadata":{"tier":"gold"}`,
+		},
+		{
+			name:     "balance without scope",
+			walletID: validID.String(),
+			mockReturn: func() {
+				mockAuthorizer.EXPECT().Authorize(gomock.Any(), validID, auth.ScopeBalanceRead).Return(authErrors.ErrForbidden)
+			},
+			expectedStatus: http.StatusForbidden,
+			expectedBody:   `"code":"FORBIDDEN"`,
+		},
 		{
 			name:     "wallet not found",
 			walletID: validID.String(),
 			mockReturn: func() {
+				mockAuthorizer.EXPECT().Authorize(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
 				mockUsecase.EXPECT().
-					Balance(gomock.Any(), validID).
-					Return(int64(0), walletErrors.ErrWalletNotFound)
+					Get(gomock.Any(), validID).
+					Return(walletUsecase.WalletInfo{}, walletErrors.ErrWalletNotFound)
 			},
 			expectedStatus: http.StatusNotFound,
 			expectedBody:   walletErrors.ErrWalletNotFound.Error(),
@@ -147,9 +285,10 @@ func TestHandler_Balance(t *testing.T) {
 			name:     "internal server error",
 			walletID: validID.String(),
 			mockReturn: func() {
+				mockAuthorizer.EXPECT().Authorize(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
 				mockUsecase.EXPECT().
-					Balance(gomock.Any(), validID).
-					Return(int64(0), errors.New("some internal error"))
+					Get(gomock.Any(), validID).
+					Return(walletUsecase.WalletInfo{}, errors.New("some internal error"))
 			},
 			expectedStatus: http.StatusInternalServerError,
 			expectedBody:   "internal server error",
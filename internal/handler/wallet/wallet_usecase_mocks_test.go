@@ -6,13 +6,94 @@ package wallet_test
 
 import (
 	context "context"
+	http "net/http"
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
 	uuid "github.com/google/uuid"
+	auth "github.com/totorialman/go-test-ac/internal/auth"
 	wallet "github.com/totorialman/go-test-ac/internal/usecase/wallet"
 )
 
+// Mockauthorizer is a mock of authorizer interface.
+type Mockauthorizer struct {
+	ctrl     *gomock.Controller
+	recorder *MockauthorizerMockRecorder
+}
+
+// MockauthorizerMockRecorder is the mock recorder for Mockauthorizer.
+type MockauthorizerMockRecorder struct {
+	mock *Mockauthorizer
+}
+
+// NewMockauthorizer creates a new mock instance.
+func NewMockauthorizer(ctrl *gomock.Controller) *Mockauthorizer {
+	mock := &Mockauthorizer{ctrl: ctrl}
+	mock.recorder = &MockauthorizerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *Mockauthorizer) EXPECT() *MockauthorizerMockRecorder {
+	return m.recorder
+}
+
+// Authorize mocks base method.
+func (m *Mockauthorizer) Authorize(ctx context.Context, walletID uuid.UUID, scope auth.Scope) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Authorize", ctx, walletID, scope)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Authorize indicates an expected call of Authorize.
+func (mr *MockauthorizerMockRecorder) Authorize(ctx, walletID, scope interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Authorize", reflect.TypeOf((*Mockauthorizer)(nil).Authorize), ctx, walletID, scope)
+}
+
+// MockrateLimiter is a mock of rateLimiter interface.
+type MockrateLimiter struct {
+	ctrl     *gomock.Controller
+	recorder *MockrateLimiterMockRecorder
+}
+
+// MockrateLimiterMockRecorder is the mock recorder for MockrateLimiter.
+type MockrateLimiterMockRecorder struct {
+	mock *MockrateLimiter
+}
+
+// NewMockrateLimiter creates a new mock instance.
+func NewMockrateLimiter(ctrl *gomock.Controller) *MockrateLimiter {
+	mock := &MockrateLimiter{ctrl: ctrl}
+	mock.recorder = &MockrateLimiterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockrateLimiter) EXPECT() *MockrateLimiterMockRecorder {
+	return m.recorder
+}
+
+// AllowWallets mocks base method.
+func (m *MockrateLimiter) AllowWallets(w http.ResponseWriter, r *http.Request, walletIDs ...uuid.UUID) bool {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{w, r}
+	for _, a := range walletIDs {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "AllowWallets", varargs...)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// AllowWallets indicates an expected call of AllowWallets.
+func (mr *MockrateLimiterMockRecorder) AllowWallets(w, r interface{}, walletIDs ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{w, r}, walletIDs...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AllowWallets", reflect.TypeOf((*MockrateLimiter)(nil).AllowWallets), varargs...)
+}
+
 // Mockusecase is a mock of usecase interface.
 type Mockusecase struct {
 	ctrl     *gomock.Controller
@@ -36,26 +117,116 @@ func (m *Mockusecase) EXPECT() *MockusecaseMockRecorder {
 	return m.recorder
 }
 
-// Balance mocks base method.
-func (m *Mockusecase) Balance(ctx context.Context, id uuid.UUID) (int64, error) {
+// Authorize mocks base method.
+func (m *Mockusecase) Authorize(ctx context.Context, a wallet.Authorization) (wallet.Hold, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Authorize", ctx, a)
+	ret0, _ := ret[0].(wallet.Hold)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Authorize indicates an expected call of Authorize.
+func (mr *MockusecaseMockRecorder) Authorize(ctx, a interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Authorize", reflect.TypeOf((*Mockusecase)(nil).Authorize), ctx, a)
+}
+
+// Batch mocks base method.
+func (m *Mockusecase) Batch(ctx context.Context, mode string, ops []wallet.Wallet) ([]wallet.BatchResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Batch", ctx, mode, ops)
+	ret0, _ := ret[0].([]wallet.BatchResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Batch indicates an expected call of Batch.
+func (mr *MockusecaseMockRecorder) Batch(ctx, mode, ops interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Batch", reflect.TypeOf((*Mockusecase)(nil).Batch), ctx, mode, ops)
+}
+
+// Capture mocks base method.
+func (m *Mockusecase) Capture(ctx context.Context, holdID uuid.UUID, amount int64) (wallet.Hold, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Capture", ctx, holdID, amount)
+	ret0, _ := ret[0].(wallet.Hold)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Capture indicates an expected call of Capture.
+func (mr *MockusecaseMockRecorder) Capture(ctx, holdID, amount interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Capture", reflect.TypeOf((*Mockusecase)(nil).Capture), ctx, holdID, amount)
+}
+
+// Create mocks base method.
+func (m *Mockusecase) Create(ctx context.Context, w wallet.NewWallet) (wallet.WalletInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, w)
+	ret0, _ := ret[0].(wallet.WalletInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockusecaseMockRecorder) Create(ctx, w interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*Mockusecase)(nil).Create), ctx, w)
+}
+
+// Get mocks base method.
+func (m *Mockusecase) Get(ctx context.Context, id uuid.UUID) (wallet.WalletInfo, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Balance", ctx, id)
-	ret0, _ := ret[0].(int64)
+	ret := m.ctrl.Call(m, "Get", ctx, id)
+	ret0, _ := ret[0].(wallet.WalletInfo)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// Balance indicates an expected call of Balance.
-func (mr *MockusecaseMockRecorder) Balance(ctx, id interface{}) *gomock.Call {
+// Get indicates an expected call of Get.
+func (mr *MockusecaseMockRecorder) Get(ctx, id interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Balance", reflect.TypeOf((*Mockusecase)(nil).Balance), ctx, id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*Mockusecase)(nil).Get), ctx, id)
+}
+
+// GetLimits mocks base method.
+func (m *Mockusecase) GetLimits(ctx context.Context, id uuid.UUID) (wallet.Limits, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLimits", ctx, id)
+	ret0, _ := ret[0].(wallet.Limits)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLimits indicates an expected call of GetLimits.
+func (mr *MockusecaseMockRecorder) GetLimits(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLimits", reflect.TypeOf((*Mockusecase)(nil).GetLimits), ctx, id)
+}
+
+// History mocks base method.
+func (m *Mockusecase) History(ctx context.Context, f wallet.HistoryFilter) (wallet.HistoryPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "History", ctx, f)
+	ret0, _ := ret[0].(wallet.HistoryPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// History indicates an expected call of History.
+func (mr *MockusecaseMockRecorder) History(ctx, f interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "History", reflect.TypeOf((*Mockusecase)(nil).History), ctx, f)
 }
 
 // Operate mocks base method.
-func (m *Mockusecase) Operate(ctx context.Context, w wallet.Wallet) (int64, error) {
+func (m *Mockusecase) Operate(ctx context.Context, w wallet.Wallet) (wallet.Balance, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "Operate", ctx, w)
-	ret0, _ := ret[0].(int64)
+	ret0, _ := ret[0].(wallet.Balance)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -65,3 +236,93 @@ func (mr *MockusecaseMockRecorder) Operate(ctx, w interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Operate", reflect.TypeOf((*Mockusecase)(nil).Operate), ctx, w)
 }
+
+// Reverse mocks base method.
+func (m *Mockusecase) Reverse(ctx context.Context, txID uuid.UUID, amount int64) (wallet.Reversal, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Reverse", ctx, txID, amount)
+	ret0, _ := ret[0].(wallet.Reversal)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Reverse indicates an expected call of Reverse.
+func (mr *MockusecaseMockRecorder) Reverse(ctx, txID, amount interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reverse", reflect.TypeOf((*Mockusecase)(nil).Reverse), ctx, txID, amount)
+}
+
+// SetHot mocks base method.
+func (m *Mockusecase) SetHot(ctx context.Context, id uuid.UUID, hot bool) (wallet.WalletInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetHot", ctx, id, hot)
+	ret0, _ := ret[0].(wallet.WalletInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetHot indicates an expected call of SetHot.
+func (mr *MockusecaseMockRecorder) SetHot(ctx, id, hot interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetHot", reflect.TypeOf((*Mockusecase)(nil).SetHot), ctx, id, hot)
+}
+
+// SetLimits mocks base method.
+func (m *Mockusecase) SetLimits(ctx context.Context, id uuid.UUID, l wallet.Limits) (wallet.Limits, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetLimits", ctx, id, l)
+	ret0, _ := ret[0].(wallet.Limits)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetLimits indicates an expected call of SetLimits.
+func (mr *MockusecaseMockRecorder) SetLimits(ctx, id, l interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetLimits", reflect.TypeOf((*Mockusecase)(nil).SetLimits), ctx, id, l)
+}
+
+// Transfer mocks base method.
+func (m *Mockusecase) Transfer(ctx context.Context, t wallet.Transfer) (wallet.TransferResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Transfer", ctx, t)
+	ret0, _ := ret[0].(wallet.TransferResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Transfer indicates an expected call of Transfer.
+func (mr *MockusecaseMockRecorder) Transfer(ctx, t interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Transfer", reflect.TypeOf((*Mockusecase)(nil).Transfer), ctx, t)
+}
+
+// UpdateStatus mocks base method.
+func (m *Mockusecase) UpdateStatus(ctx context.Context, id uuid.UUID, status string) (wallet.WalletInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateStatus", ctx, id, status)
+	ret0, _ := ret[0].(wallet.WalletInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateStatus indicates an expected call of UpdateStatus.
+func (mr *MockusecaseMockRecorder) UpdateStatus(ctx, id, status interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateStatus", reflect.TypeOf((*Mockusecase)(nil).UpdateStatus), ctx, id, status)
+}
+
+// Void mocks base method.
+func (m *Mockusecase) Void(ctx context.Context, holdID uuid.UUID) (wallet.Hold, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Void", ctx, holdID)
+	ret0, _ := ret[0].(wallet.Hold)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Void indicates an expected call of Void.
+func (mr *MockusecaseMockRecorder) Void(ctx, holdID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Void", reflect.TypeOf((*Mockusecase)(nil).Void), ctx, holdID)
+}
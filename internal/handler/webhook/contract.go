@@ -0,0 +1,18 @@
+//go:generate mockgen -source=contract.go -destination=webhook_usecase_mocks_test.go -package=webhook_test
+package webhook
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/totorialman/go-test-ac/internal/usecase/webhook"
+)
+
+type usecase interface {
+	Register(ctx context.Context, s webhook.NewSubscription) (webhook.Subscription, error)
+	ListSubscriptions(ctx context.Context) ([]webhook.Subscription, error)
+	DeleteSubscription(ctx context.Context, id uuid.UUID) error
+	ListDeliveries(ctx context.Context, f webhook.DeliveryFilter) ([]webhook.Delivery, error)
+	Replay(ctx context.Context, id uuid.UUID) (webhook.Delivery, error)
+}
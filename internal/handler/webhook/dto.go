@@ -0,0 +1,78 @@
+package webhook
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/totorialman/go-test-ac/internal/usecase/webhook"
+)
+
+type SubscriptionRequest struct {
+	URL       string     `json:"url"`
+	WalletID  *uuid.UUID `json:"walletId,omitempty"`
+	EventType string     `json:"eventType,omitempty"`
+}
+
+type SubscriptionResponse struct {
+	ID        uuid.UUID  `json:"id"`
+	URL       string     `json:"url"`
+	WalletID  *uuid.UUID `json:"walletId,omitempty"`
+	EventType string     `json:"eventType,omitempty"`
+	CreatedAt time.Time  `json:"createdAt"`
+	// Secret is only present in the response to the creating request.
+	Secret string `json:"secret,omitempty"`
+}
+
+type SubscriptionsResponse struct {
+	Subscriptions []SubscriptionResponse `json:"subscriptions"`
+}
+
+type DeliveryResponse struct {
+	ID             uuid.UUID       `json:"id"`
+	SubscriptionID uuid.UUID       `json:"subscriptionId"`
+	EventID        uuid.UUID       `json:"eventId"`
+	EventType      string          `json:"eventType"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	NextAttemptAt  time.Time       `json:"nextAttemptAt"`
+	LastError      string          `json:"lastError,omitempty"`
+	CreatedAt      time.Time       `json:"createdAt"`
+	UpdatedAt      time.Time       `json:"updatedAt"`
+}
+
+type DeliveriesResponse struct {
+	Deliveries []DeliveryResponse `json:"deliveries"`
+}
+
+func newSubscriptionResponse(s webhook.Subscription) SubscriptionResponse {
+	res := SubscriptionResponse{
+		ID:        s.ID,
+		URL:       s.URL,
+		EventType: s.EventType,
+		CreatedAt: s.CreatedAt,
+		Secret:    s.Secret,
+	}
+	if s.WalletID.Valid {
+		res.WalletID = &s.WalletID.UUID
+	}
+	return res
+}
+
+func newDeliveryResponse(d webhook.Delivery) DeliveryResponse {
+	return DeliveryResponse{
+		ID:             d.ID,
+		SubscriptionID: d.SubscriptionID,
+		EventID:        d.EventID,
+		EventType:      d.EventType,
+		Payload:        d.Payload,
+		Status:         d.Status,
+		Attempts:       d.Attempts,
+		NextAttemptAt:  d.NextAttemptAt,
+		LastError:      d.LastError,
+		CreatedAt:      d.CreatedAt,
+		UpdatedAt:      d.UpdatedAt,
+	}
+}
@@ -0,0 +1,140 @@
+package webhook
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+
+	walletErrors "github.com/totorialman/go-test-ac/internal/errors/wallet"
+	webhookErrors "github.com/totorialman/go-test-ac/internal/errors/webhook"
+	"github.com/totorialman/go-test-ac/internal/handler/response"
+	"github.com/totorialman/go-test-ac/internal/usecase/webhook"
+)
+
+type Handler struct {
+	usecase usecase
+	logger  *slog.Logger
+}
+
+func NewHandler(usecase usecase, logger *slog.Logger) *Handler {
+	return &Handler{usecase: usecase, logger: logger}
+}
+
+func (h *Handler) Register(w http.ResponseWriter, r *http.Request) {
+	var req SubscriptionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.WarnContext(r.Context(), "decode error", "error", err)
+		response.Error(w, r, walletErrors.ErrMalformedRequest)
+		return
+	}
+
+	h.logger.DebugContext(r.Context(), "register webhook request", "url", req.URL, "event", req.EventType)
+
+	s := webhook.NewSubscription{URL: req.URL, EventType: req.EventType}
+	if req.WalletID != nil {
+		s.WalletID = uuid.NullUUID{UUID: *req.WalletID, Valid: true}
+	}
+
+	sub, err := h.usecase.Register(r.Context(), s)
+	if err != nil {
+		h.logger.Log(r.Context(), response.LogLevel(err), "register webhook error", "error", err)
+		response.Error(w, r, err)
+		return
+	}
+
+	h.logger.InfoContext(r.Context(), "register webhook success", "wallet_id", sub.ID)
+	h.writeJSON(w, r, http.StatusCreated, newSubscriptionResponse(sub))
+}
+
+func (h *Handler) ListSubscriptions(w http.ResponseWriter, r *http.Request) {
+	subs, err := h.usecase.ListSubscriptions(r.Context())
+	if err != nil {
+		h.logger.Log(r.Context(), response.LogLevel(err), "list webhooks error", "error", err)
+		response.Error(w, r, err)
+		return
+	}
+
+	res := SubscriptionsResponse{Subscriptions: make([]SubscriptionResponse, 0, len(subs))}
+	for _, s := range subs {
+		res.Subscriptions = append(res.Subscriptions, newSubscriptionResponse(s))
+	}
+	h.writeJSON(w, r, http.StatusOK, res)
+}
+
+func (h *Handler) DeleteSubscription(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(mux.Vars(r)["WEBHOOK_UUID"])
+	if err != nil {
+		h.logger.WarnContext(r.Context(), "invalid uuid", "error", err)
+		response.Error(w, r, webhookErrors.ErrInvalidSubscriptionID)
+		return
+	}
+
+	if err := h.usecase.DeleteSubscription(r.Context(), id); err != nil {
+		h.logger.Log(r.Context(), response.LogLevel(err), "delete webhook error", "error", err)
+		response.Error(w, r, err)
+		return
+	}
+
+	h.logger.InfoContext(r.Context(), "delete webhook success", "wallet_id", id)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) ListDeliveries(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	f := webhook.DeliveryFilter{Status: q.Get("status")}
+	if v := q.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil {
+			h.logger.WarnContext(r.Context(), "invalid limit", "error", err)
+			response.Error(w, r, webhookErrors.ErrInvalidDeliveryFilter)
+			return
+		}
+		f.Limit = limit
+	}
+
+	deliveries, err := h.usecase.ListDeliveries(r.Context(), f)
+	if err != nil {
+		h.logger.Log(r.Context(), response.LogLevel(err), "list deliveries error", "error", err)
+		response.Error(w, r, err)
+		return
+	}
+
+	res := DeliveriesResponse{Deliveries: make([]DeliveryResponse, 0, len(deliveries))}
+	for _, d := range deliveries {
+		res.Deliveries = append(res.Deliveries, newDeliveryResponse(d))
+	}
+	h.writeJSON(w, r, http.StatusOK, res)
+}
+
+func (h *Handler) Replay(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(mux.Vars(r)["DELIVERY_UUID"])
+	if err != nil {
+		h.logger.WarnContext(r.Context(), "invalid uuid", "error", err)
+		response.Error(w, r, webhookErrors.ErrInvalidDeliveryID)
+		return
+	}
+
+	h.logger.DebugContext(r.Context(), "replay delivery request", "wallet_id", id)
+
+	d, err := h.usecase.Replay(r.Context(), id)
+	if err != nil {
+		h.logger.Log(r.Context(), response.LogLevel(err), "replay delivery error", "error", err)
+		response.Error(w, r, err)
+		return
+	}
+
+	h.logger.InfoContext(r.Context(), "replay delivery success", "wallet_id", id)
+	h.writeJSON(w, r, http.StatusOK, newDeliveryResponse(d))
+}
+
+func (h *Handler) writeJSON(w http.ResponseWriter, r *http.Request, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		h.logger.ErrorContext(r.Context(), "JSON encode error", "error", err)
+	}
+}
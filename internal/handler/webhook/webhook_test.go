@@ -0,0 +1,168 @@
+package webhook_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/totorialman/go-test-ac/internal/domain"
+	webhookErrors "github.com/totorialman/go-test-ac/internal/errors/webhook"
+	"github.com/totorialman/go-test-ac/internal/handler/webhook"
+	"github.com/totorialman/go-test-ac/internal/logging"
+	webhookUsecase "github.com/totorialman/go-test-ac/internal/usecase/webhook"
+)
+
+func TestHandler_Register(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUsecase := NewMockusecase(ctrl)
+	h := webhook.NewHandler(mockUsecase, logging.Discard())
+
+	walletID := uuid.New()
+	subID := uuid.New()
+
+	tests := []struct {
+		name           string
+		body           string
+		mockReturn     func()
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name: "registered",
+			body: `{"url":"https://example.com/hook","walletId":"` + walletID.String() + `"}`,
+			mockReturn: func() {
+				mockUsecase.EXPECT().
+					Register(gomock.Any(), webhookUsecase.NewSubscription{
+						URL:      "https://example.com/hook",
+						WalletID: uuid.NullUUID{UUID: walletID, Valid: true},
+					}).
+					Return(webhookUsecase.Subscription{ID: subID, URL: "https://example.com/hook", Secret: "s3cret"}, nil)
+			},
+			expectedStatus: http.StatusCreated,
+			expectedBody:   `"secret":"s3cret"`,
+		},
+		{
+			name: "invalid url",
+			body: `{"url":"nope"}`,
+			mockReturn: func() {
+				mockUsecase.EXPECT().
+					Register(gomock.Any(), gomock.Any()).
+					Return(webhookUsecase.Subscription{}, webhookErrors.ErrInvalidURL)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `"code":"INVALID_WEBHOOK_URL"`,
+		},
+		{
+			name:           "bad json",
+			body:           `{`,
+			mockReturn:     func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `"code":"MALFORMED_REQUEST"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockReturn()
+
+			req := httptest.NewRequest(http.MethodPost, "/webhooks", strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+
+			h.Register(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Result().StatusCode)
+			assert.Contains(t, w.Body.String(), tt.expectedBody)
+		})
+	}
+}
+
+func TestHandler_Replay(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUsecase := NewMockusecase(ctrl)
+	h := webhook.NewHandler(mockUsecase, logging.Discard())
+
+	deliveryID := uuid.New()
+
+	tests := []struct {
+		name           string
+		deliveryID     string
+		mockReturn     func()
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:       "replayed",
+			deliveryID: deliveryID.String(),
+			mockReturn: func() {
+				mockUsecase.EXPECT().
+					Replay(gomock.Any(), deliveryID).
+					Return(webhookUsecase.Delivery{ID: deliveryID, Status: domain.DeliveryPending, Payload: []byte(`{}`)}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"status":"PENDING"`,
+		},
+		{
+			name:       "not dead",
+			deliveryID: deliveryID.String(),
+			mockReturn: func() {
+				mockUsecase.EXPECT().
+					Replay(gomock.Any(), deliveryID).
+					Return(webhookUsecase.Delivery{}, webhookErrors.ErrDeliveryNotDead)
+			},
+			expectedStatus: http.StatusConflict,
+			expectedBody:   `"code":"DELIVERY_NOT_DEAD"`,
+		},
+		{
+			name:           "invalid uuid",
+			deliveryID:     "invalid",
+			mockReturn:     func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `"code":"INVALID_DELIVERY_ID"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockReturn()
+
+			req := httptest.NewRequest(http.MethodPost, "/replay", nil)
+			req = mux.SetURLVars(req, map[string]string{"DELIVERY_UUID": tt.deliveryID})
+			w := httptest.NewRecorder()
+
+			h.Replay(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Result().StatusCode)
+			assert.Contains(t, w.Body.String(), tt.expectedBody)
+		})
+	}
+}
+
+func TestHandler_ListDeliveries(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUsecase := NewMockusecase(ctrl)
+	h := webhook.NewHandler(mockUsecase, logging.Discard())
+
+	mockUsecase.EXPECT().
+		ListDeliveries(gomock.Any(), webhookUsecase.DeliveryFilter{Status: domain.DeliveryDead, Limit: 5}).
+		Return([]webhookUsecase.Delivery{{ID: uuid.New(), Status: domain.DeliveryDead, Attempts: 8, LastError: "receiver responded 500", Payload: []byte(`{}`)}}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/webhook-deliveries?status=DEAD&limit=5", nil)
+	w := httptest.NewRecorder()
+
+	h.ListDeliveries(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Result().StatusCode)
+	assert.Contains(t, w.Body.String(), `"lastError":"receiver responded 500"`)
+}
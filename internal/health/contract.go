@@ -0,0 +1,12 @@
+//go:generate mockgen -source=contract.go -destination=health_mocks_test.go -package=health_test
+package health
+
+import "context"
+
+type pinger interface {
+	Ping(ctx context.Context) error
+}
+
+type versioner interface {
+	Version(ctx context.Context) (int64, error)
+}
@@ -0,0 +1,122 @@
+// Package health serves the liveness and readiness probes.
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultTimeout bounds all readiness checks of one probe together.
+const DefaultTimeout = 2 * time.Second
+
+const statusOK = "ok"
+
+var ErrDraining = errors.New("server is shutting down")
+
+type Check func(ctx context.Context) error
+
+type namedCheck struct {
+	name  string
+	check Check
+}
+
+type Checker struct {
+	checks   []namedCheck
+	draining atomic.Bool
+	timeout  time.Duration
+	logger   *slog.Logger
+}
+
+type Response struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks,omitempty"`
+}
+
+func NewChecker(logger *slog.Logger) *Checker {
+	return &Checker{timeout: DefaultTimeout, logger: logger}
+}
+
+// Add registers a readiness check. Checks run in the order they were added.
+func (c *Checker) Add(name string, check Check) {
+	c.checks = append(c.checks, namedCheck{name: name, check: check})
+}
+
+// SetDraining makes readiness fail from now on, so that the load balancer
+// stops routing new requests while in-flight ones finish.
+func (c *Checker) SetDraining() {
+	c.draining.Store(true)
+}
+
+// Liveness reports that the process is up and serving HTTP.
+func (c *Checker) Liveness(w http.ResponseWriter, r *http.Request) {
+	c.write(w, r, http.StatusOK, Response{Status: statusOK})
+}
+
+// Readiness reports whether the instance should receive traffic: it is not
+// draining and every check passes.
+func (c *Checker) Readiness(w http.ResponseWriter, r *http.Request) {
+	res := Response{Status: statusOK, Checks: map[string]string{}}
+
+	if c.draining.Load() {
+		res.Checks["draining"] = ErrDraining.Error()
+		res.Status = "unavailable"
+	} else {
+		res.Checks["draining"] = statusOK
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), c.timeout)
+	defer cancel()
+
+	for _, nc := range c.checks {
+		if err := nc.check(ctx); err != nil {
+			c.logger.WarnContext(ctx, "readiness check failed", "check", nc.name, "error", err)
+			res.Checks[nc.name] = err.Error()
+			res.Status = "unavailable"
+			continue
+		}
+		res.Checks[nc.name] = statusOK
+	}
+
+	status := http.StatusOK
+	if res.Status != statusOK {
+		status = http.StatusServiceUnavailable
+	}
+	c.write(w, r, status, res)
+}
+
+func (c *Checker) write(w http.ResponseWriter, r *http.Request, status int, res Response) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(res); err != nil {
+		c.logger.ErrorContext(r.Context(), "JSON encode error", "error", err)
+	}
+}
+
+// Database checks that a pooled connection to Postgres can be used.
+func Database(db pinger) Check {
+	return func(ctx context.Context) error {
+		return db.Ping(ctx)
+	}
+}
+
+// Migrations checks that the database schema is at the version this binary
+// was built for.
+func Migrations(schema versioner, expected int64) Check {
+	return func(ctx context.Context) error {
+		v, err := schema.Version(ctx)
+		if err != nil {
+			return err
+		}
+		if v != expected {
+			return fmt.Errorf("schema version %d, expected %d", v, expected)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,87 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: contract.go
+
+// Package health_test is a generated GoMock package.
+package health_test
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// Mockpinger is a mock of pinger interface.
+type Mockpinger struct {
+	ctrl     *gomock.Controller
+	recorder *MockpingerMockRecorder
+}
+
+// MockpingerMockRecorder is the mock recorder for Mockpinger.
+type MockpingerMockRecorder struct {
+	mock *Mockpinger
+}
+
+// NewMockpinger creates a new mock instance.
+func NewMockpinger(ctrl *gomock.Controller) *Mockpinger {
+	mock := &Mockpinger{ctrl: ctrl}
+	mock.recorder = &MockpingerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *Mockpinger) EXPECT() *MockpingerMockRecorder {
+	return m.recorder
+}
+
+// Ping mocks base method.
+func (m *Mockpinger) Ping(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Ping", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Ping indicates an expected call of Ping.
+func (mr *MockpingerMockRecorder) Ping(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ping", reflect.TypeOf((*Mockpinger)(nil).Ping), ctx)
+}
+
+// Mockversioner is a mock of versioner interface.
+type Mockversioner struct {
+	ctrl     *gomock.Controller
+	recorder *MockversionerMockRecorder
+}
+
+// MockversionerMockRecorder is the mock recorder for Mockversioner.
+type MockversionerMockRecorder struct {
+	mock *Mockversioner
+}
+
+// NewMockversioner creates a new mock instance.
+func NewMockversioner(ctrl *gomock.Controller) *Mockversioner {
+	mock := &Mockversioner{ctrl: ctrl}
+	mock.recorder = &MockversionerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *Mockversioner) EXPECT() *MockversionerMockRecorder {
+	return m.recorder
+}
+
+// Version mocks base method.
+func (m *Mockversioner) Version(ctx context.Context) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Version", ctx)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Version indicates an expected call of Version.
+func (mr *MockversionerMockRecorder) Version(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Version", reflect.TypeOf((*Mockversioner)(nil).Version), ctx)
+}
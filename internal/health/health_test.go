@@ -0,0 +1,100 @@
+package health_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/totorialman/go-test-ac/internal/health"
+	"github.com/totorialman/go-test-ac/internal/logging"
+)
+
+func TestChecker_Liveness(t *testing.T) {
+	c := health.NewChecker(logging.Discard())
+	c.Add("database", func(_ context.Context) error { return errors.New("down") })
+	c.SetDraining()
+
+	w := httptest.NewRecorder()
+	c.Liveness(w, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"status":"ok"}`, w.Body.String())
+}
+
+func TestChecker_Readiness(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	db := NewMockpinger(ctrl)
+	schema := NewMockversioner(ctrl)
+
+	tests := []struct {
+		name           string
+		draining       bool
+		mockReturn     func()
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name: "ready",
+			mockReturn: func() {
+				db.EXPECT().Ping(gomock.Any()).Return(nil)
+				schema.EXPECT().Version(gomock.Any()).Return(int64(20260415120000), nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"status":"ok","checks":{"database":"ok","migrations":"ok","draining":"ok"}}`,
+		},
+		{
+			name: "database down",
+			mockReturn: func() {
+				db.EXPECT().Ping(gomock.Any()).Return(errors.New("connection refused"))
+				schema.EXPECT().Version(gomock.Any()).Return(int64(0), errors.New("connection refused"))
+			},
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedBody:   `{"status":"unavailable","checks":{"database":"connection refused","migrations":"connection refused","draining":"ok"}}`,
+		},
+		{
+			name: "schema behind",
+			mockReturn: func() {
+				db.EXPECT().Ping(gomock.Any()).Return(nil)
+				schema.EXPECT().Version(gomock.Any()).Return(int64(20260410120000), nil)
+			},
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedBody:   `{"status":"unavailable","checks":{"database":"ok","migrations":"schema version 20260410120000, expected 20260415120000","draining":"ok"}}`,
+		},
+		{
+			name:     "draining",
+			draining: true,
+			mockReturn: func() {
+				db.EXPECT().Ping(gomock.Any()).Return(nil)
+				schema.EXPECT().Version(gomock.Any()).Return(int64(20260415120000), nil)
+			},
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedBody:   `{"status":"unavailable","checks":{"database":"ok","migrations":"ok","draining":"server is shutting down"}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockReturn()
+
+			c := health.NewChecker(logging.Discard())
+			c.Add("database", health.Database(db))
+			c.Add("migrations", health.Migrations(schema, 20260415120000))
+			if tt.draining {
+				c.SetDraining()
+			}
+
+			w := httptest.NewRecorder()
+			c.Readiness(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.JSONEq(t, tt.expectedBody, w.Body.String())
+		})
+	}
+}
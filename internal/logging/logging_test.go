@@ -0,0 +1,97 @@
+package logging_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/totorialman/go-test-ac/internal/logging"
+	"github.com/totorialman/go-test-ac/internal/requestid"
+)
+
+func decodeLines(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	t.Helper()
+
+	var lines []map[string]any
+	for _, raw := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var line map[string]any
+		require.NoError(t, json.Unmarshal([]byte(raw), &line))
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func TestNew_AddsContextAttributes(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := logging.New(&buf, slog.LevelInfo, logging.FormatJSON)
+	require.NoError(t, err)
+
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: traceID,
+		SpanID:  spanID,
+	}))
+	ctx = requestid.NewContext(ctx, "req-1")
+
+	logger.With("component", "test").InfoContext(ctx, "operate success", "amount", 100)
+	logger.DebugContext(ctx, "filtered out")
+	logger.Info("no context")
+
+	lines := decodeLines(t, &buf)
+	require.Len(t, lines, 2)
+
+	assert.Equal(t, "operate success", lines[0]["msg"])
+	assert.Equal(t, "req-1", lines[0]["request_id"])
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", lines[0]["trace_id"])
+	assert.Equal(t, "00f067aa0ba902b7", lines[0]["span_id"])
+	assert.Equal(t, "test", lines[0]["component"])
+	assert.EqualValues(t, 100, lines[0]["amount"])
+
+	assert.NotContains(t, lines[1], "request_id")
+}
+
+func TestNew_Formats(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := logging.New(&buf, slog.LevelInfo, logging.FormatText)
+	require.NoError(t, err)
+
+	logger.InfoContext(requestid.NewContext(context.Background(), "req-2"), "hello")
+	assert.Contains(t, buf.String(), "msg=hello")
+	assert.Contains(t, buf.String(), "request_id=req-2")
+
+	_, err = logging.New(&buf, slog.LevelInfo, "xml")
+	assert.Error(t, err)
+}
+
+func TestMiddleware(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := logging.New(&buf, slog.LevelInfo, logging.FormatJSON)
+	require.NoError(t, err)
+
+	h := requestid.Middleware(logging.Middleware(logger)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})))
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/wallet", nil)
+	req.Header.Set(requestid.Header, "gateway-42")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	lines := decodeLines(t, &buf)
+	require.Len(t, lines, 1)
+	assert.Equal(t, "request completed", lines[0]["msg"])
+	assert.Equal(t, "gateway-42", lines[0]["request_id"])
+	assert.Equal(t, "/api/v1/wallet", lines[0]["path"])
+	assert.EqualValues(t, http.StatusTeapot, lines[0]["status"])
+	assert.Equal(t, "gateway-42", rec.Header().Get(requestid.Header))
+}
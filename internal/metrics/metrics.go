@@ -0,0 +1,101 @@
+// Package metrics exposes the service's Prometheus metrics: HTTP traffic,
+// outcomes of wallet operations and database pool usage.
+package metrics
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus"
+
+	walletErrors "github.com/totorialman/go-test-ac/internal/errors/wallet"
+	"github.com/totorialman/go-test-ac/internal/handler/response"
+	"github.com/totorialman/go-test-ac/internal/statuswriter"
+)
+
+const namespace = "wallet"
+
+// Operation outcomes. Rejected covers every other client error, such as a
+// frozen wallet or an exceeded limit; error means the service failed.
+const (
+	OutcomeSuccess           = "success"
+	OutcomeNotFound          = "not_found"
+	OutcomeInsufficientFunds = "insufficient_funds"
+	OutcomeRejected          = "rejected"
+	OutcomeError             = "error"
+)
+
+type Metrics struct {
+	requests   *prometheus.CounterVec
+	duration   *prometheus.HistogramVec
+	operations *prometheus.CounterVec
+}
+
+func New(reg prometheus.Registerer) *Metrics {
+	m := &Metrics{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_requests_total",
+			Help:      "HTTP requests by route, method and status.",
+		}, []string{"route", "method", "status"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "HTTP request latency by route, method and status.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"route", "method", "status"}),
+		operations: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "operations_total",
+			Help:      "Balance-changing operations by type and outcome.",
+		}, []string{"type", "outcome"}),
+	}
+	reg.MustRegister(m.requests, m.duration, m.operations)
+	return m
+}
+
+// Middleware records every request routed by mux. The route label is the
+// path template, so wallet ids do not end up as label values.
+func (m *Metrics) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		sw := statuswriter.New(w)
+
+		next.ServeHTTP(sw, r)
+
+		route := "unknown"
+		if cur := mux.CurrentRoute(r); cur != nil {
+			if tmpl, err := cur.GetPathTemplate(); err == nil {
+				route = tmpl
+			}
+		}
+		status := strconv.Itoa(sw.Status())
+
+		m.requests.WithLabelValues(route, r.Method, status).Inc()
+		m.duration.WithLabelValues(route, r.Method, status).Observe(time.Since(start).Seconds())
+	})
+}
+
+// ObserveOperation implements the wallet usecase OperationObserver.
+func (m *Metrics) ObserveOperation(opType string, err error) {
+	m.operations.WithLabelValues(opType, Outcome(err)).Inc()
+}
+
+func Outcome(err error) string {
+	switch {
+	case err == nil:
+		return OutcomeSuccess
+	case errors.Is(err, walletErrors.ErrWalletNotFound):
+		return OutcomeNotFound
+	case errors.Is(err, walletErrors.ErrNotEnoughFunds):
+		return OutcomeInsufficientFunds
+	}
+
+	if _, code, _ := response.Classify(err); code == response.CodeInternal {
+		return OutcomeError
+	}
+	return OutcomeRejected
+}
@@ -0,0 +1,24 @@
+//go:generate mockgen -source=contract.go -destination=outbox_mocks_test.go -package=outbox_test
+package outbox
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/totorialman/go-test-ac/internal/repository/outbox"
+)
+
+type store interface {
+	Claim(ctx context.Context, limit int, lease time.Duration) ([]outbox.EventDB, error)
+	MarkPublished(ctx context.Context, id uuid.UUID) error
+	MarkFailed(ctx context.Context, id uuid.UUID, reason string) error
+}
+
+// Publisher delivers an event downstream. Delivery is at least once, so the
+// same event may be published more than once; consumers deduplicate by
+// Event.ID.
+type Publisher interface {
+	Publish(ctx context.Context, e Event) error
+}
@@ -0,0 +1,31 @@
+package outbox
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/totorialman/go-test-ac/internal/repository/outbox"
+)
+
+type Event struct {
+	ID          uuid.UUID       `json:"id"`
+	Type        string          `json:"type"`
+	AggregateID uuid.UUID       `json:"aggregateId"`
+	Payload     json.RawMessage `json:"payload"`
+	CreatedAt   time.Time       `json:"createdAt"`
+	// Attempts counts delivery attempts including the current one.
+	Attempts int `json:"-"`
+}
+
+func toEvent(e outbox.EventDB) Event {
+	return Event{
+		ID:          e.ID,
+		Type:        e.Type,
+		AggregateID: e.AggregateID,
+		Payload:     e.Payload,
+		CreatedAt:   e.CreatedAt,
+		Attempts:    e.Attempts,
+	}
+}
@@ -0,0 +1,40 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"sync"
+)
+
+// WriterPublisher writes each event as a JSON line to w. It is meant for
+// local development, with w set to stdout or a file.
+type WriterPublisher struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+func NewWriterPublisher(w io.Writer) *WriterPublisher {
+	return &WriterPublisher{enc: json.NewEncoder(w)}
+}
+
+func (p *WriterPublisher) Publish(_ context.Context, e Event) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.enc.Encode(e)
+}
+
+// MultiPublisher publishes each event to every publisher in turn. It fails
+// if any of them fails, so the event is retried for all of them; publishers
+// must therefore tolerate duplicates.
+type MultiPublisher []Publisher
+
+func (m MultiPublisher) Publish(ctx context.Context, e Event) error {
+	for _, p := range m {
+		if err := p.Publish(ctx, e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package outbox_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/totorialman/go-test-ac/internal/outbox"
+)
+
+func TestWriterPublisher(t *testing.T) {
+	var buf bytes.Buffer
+	p := outbox.NewWriterPublisher(&buf)
+
+	id := uuid.New()
+	walletID := uuid.New()
+	createdAt := time.Date(2026, 4, 10, 12, 0, 0, 0, time.UTC)
+
+	for i := 0; i < 2; i++ {
+		err := p.Publish(context.Background(), outbox.Event{
+			ID:          id,
+			Type:        "wallet.balance_changed",
+			AggregateID: walletID,
+			Payload:     []byte(`{"amount":100}`),
+			CreatedAt:   createdAt,
+		})
+		assert.NoError(t, err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Len(t, lines, 2)
+	assert.JSONEq(t, `{
+		"id":"`+id.String()+`",
+		"type":"wallet.balance_changed",
+		"aggregateId":"`+walletID.String()+`",
+		"payload":{"amount":100},
+		"createdAt":"2026-04-10T12:00:00Z"
+	}`, lines[0])
+}
@@ -0,0 +1,131 @@
+// Package outbox delivers events written to the outbox table by the
+// repositories to a Publisher.
+package outbox
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	DefaultBatchSize    = 100
+	DefaultPollInterval = time.Second
+	DefaultLease        = 30 * time.Second
+)
+
+type Relay struct {
+	store        store
+	publisher    Publisher
+	batchSize    int
+	pollInterval time.Duration
+	lease        time.Duration
+	logger       *slog.Logger
+}
+
+type Option func(*Relay)
+
+// WithBatchSize sets how many events are claimed at once.
+func WithBatchSize(n int) Option {
+	return func(r *Relay) {
+		r.batchSize = n
+	}
+}
+
+// WithPollInterval sets how long the relay sleeps when the outbox is empty.
+func WithPollInterval(d time.Duration) Option {
+	return func(r *Relay) {
+		r.pollInterval = d
+	}
+}
+
+// WithLease sets how long a claimed event is reserved for this relay. It is
+// also the delay before a failed event is retried.
+func WithLease(d time.Duration) Option {
+	return func(r *Relay) {
+		r.lease = d
+	}
+}
+
+// WithLogger sets the logger for publish failures.
+func WithLogger(l *slog.Logger) Option {
+	return func(r *Relay) {
+		r.logger = l
+	}
+}
+
+func NewRelay(store store, publisher Publisher, opts ...Option) *Relay {
+	r := &Relay{
+		store:        store,
+		publisher:    publisher,
+		batchSize:    DefaultBatchSize,
+		pollInterval: DefaultPollInterval,
+		lease:        DefaultLease,
+		logger:       slog.Default(),
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Run relays events until ctx is cancelled. A full batch is followed
+// immediately by the next one; otherwise the relay waits for the poll
+// interval.
+func (r *Relay) Run(ctx context.Context) {
+	for {
+		n, err := r.RelayOnce(ctx)
+		if err != nil {
+			r.logger.ErrorContext(ctx, "outbox relay error", "error", err)
+		}
+		if err == nil && n == r.batchSize {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(r.pollInterval):
+		}
+	}
+}
+
+// RelayOnce claims one batch and publishes it, returning how many events
+// were claimed. An event is marked published only after the publisher
+// accepted it. Once an event of a wallet fails, its later events in the
+// batch are left for the retry so they are not delivered out of order.
+func (r *Relay) RelayOnce(ctx context.Context) (int, error) {
+	events, err := r.store.Claim(ctx, r.batchSize, r.lease)
+	if err != nil {
+		return 0, err
+	}
+
+	failed := make(map[uuid.UUID]bool)
+	for _, e := range events {
+		if failed[e.AggregateID] {
+			continue
+		}
+
+		if err := r.publisher.Publish(ctx, toEvent(e)); err != nil {
+			r.logger.WarnContext(ctx, "outbox publish error",
+				"event_id", e.ID,
+				"event_type", e.Type,
+				"attempt", e.Attempts,
+				"error", err,
+			)
+			failed[e.AggregateID] = true
+			if err := r.store.MarkFailed(ctx, e.ID, err.Error()); err != nil {
+				return len(events), err
+			}
+			continue
+		}
+
+		if err := r.store.MarkPublished(ctx, e.ID); err != nil {
+			return len(events), err
+		}
+	}
+
+	return len(events), nil
+}
@@ -0,0 +1,126 @@
+package outbox_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/totorialman/go-test-ac/internal/outbox"
+	repo "github.com/totorialman/go-test-ac/internal/repository/outbox"
+)
+
+func TestRelay_RelayOnce(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStore := NewMockstore(ctrl)
+	mockPublisher := NewMockPublisher(ctrl)
+	relay := outbox.NewRelay(mockStore, mockPublisher, outbox.WithBatchSize(10), outbox.WithLease(time.Minute))
+
+	walletA := uuid.New()
+	walletB := uuid.New()
+	first := repo.EventDB{ID: uuid.New(), Type: "wallet.balance_changed", AggregateID: walletA, Payload: []byte(`{}`), Attempts: 1}
+	second := repo.EventDB{ID: uuid.New(), Type: "wallet.balance_changed", AggregateID: walletA, Payload: []byte(`{}`), Attempts: 1}
+	other := repo.EventDB{ID: uuid.New(), Type: "wallet.balance_changed", AggregateID: walletB, Payload: []byte(`{}`), Attempts: 1}
+
+	tests := []struct {
+		name      string
+		mockSetup func()
+		wantN     int
+		wantErr   bool
+	}{
+		{
+			name: "Empty outbox",
+			mockSetup: func() {
+				mockStore.EXPECT().Claim(gomock.Any(), 10, time.Minute).Return(nil, nil)
+			},
+		},
+		{
+			name: "All published",
+			mockSetup: func() {
+				mockStore.EXPECT().Claim(gomock.Any(), 10, time.Minute).Return([]repo.EventDB{first, other}, nil)
+				gomock.InOrder(
+					mockPublisher.EXPECT().Publish(gomock.Any(), gomock.Any()).
+						DoAndReturn(func(_ context.Context, e outbox.Event) error {
+							assert.Equal(t, first.ID, e.ID)
+							assert.JSONEq(t, `{}`, string(e.Payload))
+							return nil
+						}),
+					mockStore.EXPECT().MarkPublished(gomock.Any(), first.ID).Return(nil),
+					mockPublisher.EXPECT().Publish(gomock.Any(), gomock.Any()).Return(nil),
+					mockStore.EXPECT().MarkPublished(gomock.Any(), other.ID).Return(nil),
+				)
+			},
+			wantN: 2,
+		},
+		{
+			name: "Failure holds back later events of the same wallet",
+			mockSetup: func() {
+				mockStore.EXPECT().Claim(gomock.Any(), 10, time.Minute).Return([]repo.EventDB{first, second, other}, nil)
+				gomock.InOrder(
+					mockPublisher.EXPECT().Publish(gomock.Any(), gomock.Any()).Return(errors.New("broker down")),
+					mockStore.EXPECT().MarkFailed(gomock.Any(), first.ID, "broker down").Return(nil),
+					mockPublisher.EXPECT().Publish(gomock.Any(), gomock.Any()).
+						DoAndReturn(func(_ context.Context, e outbox.Event) error {
+							assert.Equal(t, other.ID, e.ID)
+							return nil
+						}),
+					mockStore.EXPECT().MarkPublished(gomock.Any(), other.ID).Return(nil),
+				)
+			},
+			wantN: 3,
+		},
+		{
+			name: "Claim error",
+			mockSetup: func() {
+				mockStore.EXPECT().Claim(gomock.Any(), 10, time.Minute).Return(nil, errors.New("db down"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+			n, err := relay.RelayOnce(context.Background())
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantN, n)
+		})
+	}
+}
+
+func TestRelay_RunStopsOnCancel(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStore := NewMockstore(ctrl)
+	relay := outbox.NewRelay(mockStore, NewMockPublisher(ctrl), outbox.WithPollInterval(time.Millisecond))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	mockStore.EXPECT().Claim(gomock.Any(), outbox.DefaultBatchSize, outbox.DefaultLease).
+		DoAndReturn(func(context.Context, int, time.Duration) ([]repo.EventDB, error) {
+			cancel()
+			return nil, nil
+		})
+
+	done := make(chan struct{})
+	go func() {
+		relay.Run(ctx)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("relay did not stop")
+	}
+}
@@ -0,0 +1,16 @@
+//go:generate mockgen -source=contract.go -destination=ratelimit_mocks_test.go -package=ratelimit_test
+package ratelimit
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+type store interface {
+	Take(ctx context.Context, key string, l Limit) (Result, error)
+}
+
+type walletAccess interface {
+	AuthorizeWallet(ctx context.Context, walletID uuid.UUID) error
+}
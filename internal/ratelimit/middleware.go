@@ -0,0 +1,284 @@
+package ratelimit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"math"
+	"net"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+
+	"github.com/totorialman/go-test-ac/internal/auth"
+	ratelimitErrors "github.com/totorialman/go-test-ac/internal/errors/ratelimit"
+	"github.com/totorialman/go-test-ac/internal/handler/response"
+)
+
+// maxPeek bounds how much of a request body is read to find its wallet id.
+const maxPeek = 64 << 10
+
+// RouteLimits are the buckets a request to one route takes from: one per
+// API client and one per wallet.
+type RouteLimits struct {
+	Client Limit
+	Wallet Limit
+}
+
+// WalletLookup returns the wallet that something named in a request path,
+// such as a hold, belongs to.
+type WalletLookup func(ctx context.Context, id uuid.UUID) (uuid.UUID, error)
+
+type Limiter struct {
+	store    store
+	defaults RouteLimits
+	routes   map[string]RouteLimits
+	access   walletAccess
+	lookups  map[string]WalletLookup
+	logger   *slog.Logger
+}
+
+type Option func(*Limiter)
+
+// WithRoute overrides the default limits for a route, given as the method
+// and the path template, e.g. "POST /api/v1/wallet", or as the full gRPC
+// method name, e.g. "/wallet.v1.WalletService/Operate".
+func WithRoute(route string, limits RouteLimits) Option {
+	return func(l *Limiter) {
+		l.routes[route] = limits
+	}
+}
+
+// WithWalletAccess charges the wallet bucket only for callers that access
+// allows to act on the wallet, so that nobody can drain the bucket of a
+// wallet they have no access to. Without it every request is charged.
+func WithWalletAccess(access walletAccess) Option {
+	return func(l *Limiter) {
+		l.access = access
+	}
+}
+
+// WithWalletLookup charges requests whose path variable pathVar names
+// something other than a wallet, e.g. HOLD_UUID, to the bucket of the wallet
+// lookup returns for it.
+func WithWalletLookup(pathVar string, lookup WalletLookup) Option {
+	return func(l *Limiter) {
+		l.lookups[pathVar] = lookup
+	}
+}
+
+// WithLogger sets the logger for rejected requests and store failures.
+func WithLogger(logger *slog.Logger) Option {
+	return func(l *Limiter) {
+		l.logger = logger
+	}
+}
+
+func NewLimiter(store store, defaults RouteLimits, opts ...Option) *Limiter {
+	l := &Limiter{
+		store:    store,
+		defaults: defaults,
+		routes:   make(map[string]RouteLimits),
+		lookups:  make(map[string]WalletLookup),
+		logger:   slog.Default(),
+	}
+	for _, opt := range opts {
+		opt(l)
+	}
+	return l
+}
+
+// Middleware limits requests routed by mux per API client and per wallet.
+// A request that moves money between wallets is charged to each of them.
+// It must run after authentication; unauthenticated requests are keyed by
+// the remote address. Rejected requests get 429 with Retry-After. When the
+// store fails the request is let through: an outage of the limiter should
+// not take the API down with it.
+func (l *Limiter) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route := routeName(r)
+
+		var walletIDs []uuid.UUID
+		if l.limits(route).Wallet.Enabled() {
+			walletIDs = l.walletIDs(r)
+		}
+		if retryAfter, ok := l.Allow(r.Context(), route, clientID(r), walletIDs...); !ok {
+			reject(w, r, retryAfter)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// AllowWallets charges r to the buckets of walletIDs. When one of them is
+// empty it responds 429 with Retry-After and returns false. It is for
+// handlers that learn the wallets only from the decoded body, such as
+// batches; Middleware has charged the client bucket already.
+func (l *Limiter) AllowWallets(w http.ResponseWriter, r *http.Request, walletIDs ...uuid.UUID) bool {
+	retryAfter, ok := l.allowWallets(r.Context(), routeName(r), walletIDs)
+	if !ok {
+		reject(w, r, retryAfter)
+	}
+	return ok
+}
+
+// Allow charges a call to route made by client to the client's bucket and
+// to the buckets of walletIDs the caller may act on. When one of them is
+// empty it returns false and how long to wait before retrying. Middleware
+// uses it for HTTP requests; other transports call it directly.
+func (l *Limiter) Allow(ctx context.Context, route, client string, walletIDs ...uuid.UUID) (time.Duration, bool) {
+	if limits := l.limits(route); limits.Client.Enabled() {
+		if retryAfter, ok := l.take(ctx, "client:"+client+":"+route, limits.Client); !ok {
+			return retryAfter, false
+		}
+	}
+	return l.allowWallets(ctx, route, walletIDs)
+}
+
+func (l *Limiter) allowWallets(ctx context.Context, route string, walletIDs []uuid.UUID) (time.Duration, bool) {
+	limits := l.limits(route)
+	if !limits.Wallet.Enabled() {
+		return 0, true
+	}
+	for _, id := range walletIDs {
+		if !l.mayAccess(ctx, id) {
+			continue
+		}
+		if retryAfter, ok := l.take(ctx, "wallet:"+id.String()+":"+route, limits.Wallet); !ok {
+			return retryAfter, false
+		}
+	}
+	return 0, true
+}
+
+func reject(w http.ResponseWriter, r *http.Request, retryAfter time.Duration) {
+	w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+	response.Error(w, r, ratelimitErrors.ErrRateLimited)
+}
+
+func (l *Limiter) limits(route string) RouteLimits {
+	if limits, ok := l.routes[route]; ok {
+		return limits
+	}
+	return l.defaults
+}
+
+func (l *Limiter) take(ctx context.Context, key string, limit Limit) (time.Duration, bool) {
+	res, err := l.store.Take(ctx, key, limit)
+	if err != nil {
+		l.logger.ErrorContext(ctx, "rate limit store error", "key", key, "error", err)
+		return 0, true
+	}
+	if res.Allowed {
+		return 0, true
+	}
+
+	l.logger.WarnContext(ctx, "rate limited", "key", key, "retry_after", res.RetryAfter)
+	return res.RetryAfter, false
+}
+
+// mayAccess reports whether the caller may act on the wallet. Requests that
+// may not are left to the handler to reject.
+func (l *Limiter) mayAccess(ctx context.Context, id uuid.UUID) bool {
+	if l.access == nil {
+		return true
+	}
+	if err := l.access.AuthorizeWallet(ctx, id); err != nil {
+		l.logger.DebugContext(ctx, "wallet bucket skipped", "wallet_id", id, "error", err)
+		return false
+	}
+	return true
+}
+
+func routeName(r *http.Request) string {
+	if cur := mux.CurrentRoute(r); cur != nil {
+		if tmpl, err := cur.GetPathTemplate(); err == nil {
+			return r.Method + " " + tmpl
+		}
+	}
+	return r.Method + " " + r.URL.Path
+}
+
+func clientID(r *http.Request) string {
+	if p, ok := auth.FromContext(r.Context()); ok {
+		return p.ID
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// walletIDs finds the wallets a request is about: the WALLET_UUID path
+// variable, the wallet behind another path variable with a WalletLookup,
+// or the walletId, fromWalletId and toWalletId fields of a JSON body.
+// Requests whose wallet cannot be found are left to the handler to reject.
+func (l *Limiter) walletIDs(r *http.Request) []uuid.UUID {
+	vars := mux.Vars(r)
+	if v, ok := vars["WALLET_UUID"]; ok {
+		id, err := uuid.Parse(v)
+		if err != nil {
+			return nil
+		}
+		return []uuid.UUID{id}
+	}
+	for pathVar, lookup := range l.lookups {
+		v, ok := vars[pathVar]
+		if !ok {
+			continue
+		}
+		id, err := uuid.Parse(v)
+		if err != nil {
+			return nil
+		}
+		walletID, err := lookup(r.Context(), id)
+		if err != nil {
+			l.logger.Log(r.Context(), response.LogLevel(err), "wallet lookup error", pathVar, id, "error", err)
+			return nil
+		}
+		return []uuid.UUID{walletID}
+	}
+	return bodyWalletIDs(r)
+}
+
+// bodyWalletIDs reads the wallet ids of a JSON body. The body is put back
+// for the handler.
+func bodyWalletIDs(r *http.Request) []uuid.UUID {
+	if r.Body == nil || r.Method == http.MethodGet {
+		return nil
+	}
+
+	peeked, err := io.ReadAll(io.LimitReader(r.Body, maxPeek))
+	r.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(peeked), r.Body), r.Body}
+	if err != nil {
+		return nil
+	}
+
+	var body struct {
+		WalletID     uuid.UUID `json:"walletId"`
+		FromWalletID uuid.UUID `json:"fromWalletId"`
+		ToWalletID   uuid.UUID `json:"toWalletId"`
+	}
+	if err := json.Unmarshal(peeked, &body); err != nil {
+		return nil
+	}
+
+	var ids []uuid.UUID
+	for _, id := range []uuid.UUID{body.WalletID, body.FromWalletID, body.ToWalletID} {
+		if id != uuid.Nil && !slices.Contains(ids, id) {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
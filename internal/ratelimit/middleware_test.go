@@ -0,0 +1,259 @@
+package ratelimit_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/totorialman/go-test-ac/internal/auth"
+	authErrors "github.com/totorialman/go-test-ac/internal/errors/auth"
+	walletErrors "github.com/totorialman/go-test-ac/internal/errors/wallet"
+	"github.com/totorialman/go-test-ac/internal/logging"
+	"github.com/totorialman/go-test-ac/internal/ratelimit"
+)
+
+func TestLimiter_Middleware(t *testing.T) {
+	walletID := uuid.New()
+	client := ratelimit.Limit{Rate: 10, Burst: 20}
+	wallet := ratelimit.Limit{Rate: 1, Burst: 5}
+	operateBody := `{"walletId":"` + walletID.String() + `","operationType":"DEPOSIT","amount":100}`
+	toWalletID, holdID := uuid.New(), uuid.New()
+	transferBody := `{"fromWalletId":"` + walletID.String() + `","toWalletId":"` + toWalletID.String() + `","amount":100}`
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		body           string
+		principal      string
+		mockReturn     func(m *MockstoreMockRecorder)
+		expectedStatus int
+		expectedBody   string
+		retryAfter     string
+	}{
+		{
+			name:      "allowed by both buckets",
+			method:    http.MethodPost,
+			path:      "/api/v1/wallet",
+			body:      operateBody,
+			principal: "key-1",
+			mockReturn: func(m *MockstoreMockRecorder) {
+				m.Take(gomock.Any(), "client:key-1:POST /api/v1/wallet", client).
+					Return(ratelimit.Result{Allowed: true}, nil)
+				m.Take(gomock.Any(), "wallet:"+walletID.String()+":POST /api/v1/wallet", wallet).
+					Return(ratelimit.Result{Allowed: true}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   operateBody,
+		},
+		{
+			name:   "client limited by remote address",
+			method: http.MethodGet,
+			path:   "/api/v1/wallets/" + walletID.String(),
+			mockReturn: func(m *MockstoreMockRecorder) {
+				m.Take(gomock.Any(), "client:192.0.2.1:GET /api/v1/wallets/{WALLET_UUID}", client).
+					Return(ratelimit.Result{RetryAfter: 1500 * time.Millisecond}, nil)
+			},
+			expectedStatus: http.StatusTooManyRequests,
+			expectedBody:   `"code":"RATE_LIMITED"`,
+			retryAfter:     "2",
+		},
+		{
+			name:      "wallet limited from path",
+			method:    http.MethodGet,
+			path:      "/api/v1/wallets/" + walletID.String(),
+			principal: "key-1",
+			mockReturn: func(m *MockstoreMockRecorder) {
+				m.Take(gomock.Any(), "client:key-1:GET /api/v1/wallets/{WALLET_UUID}", client).
+					Return(ratelimit.Result{Allowed: true}, nil)
+				m.Take(gomock.Any(), "wallet:"+walletID.String()+":GET /api/v1/wallets/{WALLET_UUID}", wallet).
+					Return(ratelimit.Result{RetryAfter: 200 * time.Millisecond}, nil)
+			},
+			expectedStatus: http.StatusTooManyRequests,
+			expectedBody:   `"code":"RATE_LIMITED"`,
+			retryAfter:     "1",
+		},
+		{
+			name:      "transfer charged to both wallets",
+			method:    http.MethodPost,
+			path:      "/api/v1/transfers",
+			body:      transferBody,
+			principal: "key-1",
+			mockReturn: func(m *MockstoreMockRecorder) {
+				m.Take(gomock.Any(), "client:key-1:POST /api/v1/transfers", client).
+					Return(ratelimit.Result{Allowed: true}, nil)
+				m.Take(gomock.Any(), "wallet:"+walletID.String()+":POST /api/v1/transfers", wallet).
+					Return(ratelimit.Result{Allowed: true}, nil)
+				m.Take(gomock.Any(), "wallet:"+toWalletID.String()+":POST /api/v1/transfers", wallet).
+					Return(ratelimit.Result{RetryAfter: time.Second}, nil)
+			},
+			expectedStatus: http.StatusTooManyRequests,
+			expectedBody:   `"code":"RATE_LIMITED"`,
+			retryAfter:     "1",
+		},
+		{
+			name:      "hold charged to its wallet",
+			method:    http.MethodPost,
+			path:      "/api/v1/holds/" + holdID.String() + "/capture",
+			body:      `{}`,
+			principal: "key-1",
+			mockReturn: func(m *MockstoreMockRecorder) {
+				m.Take(gomock.Any(), "client:key-1:POST /api/v1/holds/{HOLD_UUID}/capture", client).
+					Return(ratelimit.Result{Allowed: true}, nil)
+				m.Take(gomock.Any(), "wallet:"+walletID.String()+":POST /api/v1/holds/{HOLD_UUID}/capture", wallet).
+					Return(ratelimit.Result{RetryAfter: time.Second}, nil)
+			},
+			expectedStatus: http.StatusTooManyRequests,
+			expectedBody:   `"code":"RATE_LIMITED"`,
+			retryAfter:     "1",
+		},
+		{
+			name:      "unknown hold",
+			method:    http.MethodPost,
+			path:      "/api/v1/holds/" + uuid.NewString() + "/capture",
+			body:      `{}`,
+			principal: "key-1",
+			mockReturn: func(m *MockstoreMockRecorder) {
+				m.Take(gomock.Any(), "client:key-1:POST /api/v1/holds/{HOLD_UUID}/capture", client).
+					Return(ratelimit.Result{Allowed: true}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{}`,
+		},
+		{
+			name:      "body without wallet",
+			method:    http.MethodPost,
+			path:      "/api/v1/wallet",
+			body:      `{`,
+			principal: "key-1",
+			mockReturn: func(m *MockstoreMockRecorder) {
+				m.Take(gomock.Any(), "client:key-1:POST /api/v1/wallet", client).
+					Return(ratelimit.Result{Allowed: true}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{`,
+		},
+		{
+			name:      "route override",
+			method:    http.MethodPost,
+			path:      "/api/v1/wallets",
+			body:      `{}`,
+			principal: "key-1",
+			mockReturn: func(m *MockstoreMockRecorder) {
+				m.Take(gomock.Any(), "client:key-1:POST /api/v1/wallets", ratelimit.Limit{Rate: 1, Burst: 1}).
+					Return(ratelimit.Result{Allowed: true}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{}`,
+		},
+		{
+			name:      "store error fails open",
+			method:    http.MethodPost,
+			path:      "/api/v1/wallet",
+			body:      operateBody,
+			principal: "key-1",
+			mockReturn: func(m *MockstoreMockRecorder) {
+				m.Take(gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(ratelimit.Result{}, errors.New("connection refused")).Times(2)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   operateBody,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockStore := NewMockstore(ctrl)
+			tt.mockReturn(mockStore.EXPECT())
+
+			limiter := ratelimit.NewLimiter(mockStore,
+				ratelimit.RouteLimits{Client: client, Wallet: wallet},
+				ratelimit.WithRoute("POST /api/v1/wallets", ratelimit.RouteLimits{Client: ratelimit.Limit{Rate: 1, Burst: 1}}),
+				ratelimit.WithWalletLookup("HOLD_UUID", func(_ context.Context, id uuid.UUID) (uuid.UUID, error) {
+					if id != holdID {
+						return uuid.Nil, walletErrors.ErrHoldNotFound
+					}
+					return walletID, nil
+				}),
+				ratelimit.WithLogger(logging.Discard()),
+			)
+
+			echo := func(w http.ResponseWriter, r *http.Request) {
+				_, _ = io.Copy(w, r.Body)
+			}
+			r := mux.NewRouter()
+			r.Use(limiter.Middleware)
+			r.HandleFunc("/api/v1/wallet", echo).Methods(http.MethodPost)
+			r.HandleFunc("/api/v1/wallets", echo).Methods(http.MethodPost)
+			r.HandleFunc("/api/v1/wallets/{WALLET_UUID}", echo).Methods(http.MethodGet)
+			r.HandleFunc("/api/v1/transfers", echo).Methods(http.MethodPost)
+			r.HandleFunc("/api/v1/holds/{HOLD_UUID}/capture", echo).Methods(http.MethodPost)
+
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			req.RemoteAddr = "192.0.2.1:54321"
+			if tt.principal != "" {
+				req = req.WithContext(auth.WithPrincipal(req.Context(), auth.Principal{ID: tt.principal}))
+			}
+			w := httptest.NewRecorder()
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Result().StatusCode)
+			assert.Contains(t, w.Body.String(), tt.expectedBody)
+			assert.Equal(t, tt.retryAfter, w.Header().Get("Retry-After"))
+		})
+	}
+}
+
+func TestLimiter_WalletBucketNeedsAccess(t *testing.T) {
+	own, other := uuid.New(), uuid.New()
+	client := ratelimit.Limit{Rate: 10, Burst: 20}
+	wallet := ratelimit.Limit{Rate: 1, Burst: 5}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStore := NewMockstore(ctrl)
+	mockAccess := NewMockwalletAccess(ctrl)
+
+	mockAccess.EXPECT().AuthorizeWallet(gomock.Any(), own).Return(nil)
+	mockAccess.EXPECT().AuthorizeWallet(gomock.Any(), other).Return(authErrors.ErrWalletAccessDenied)
+	mockStore.EXPECT().Take(gomock.Any(), "client:key-1:GET /api/v1/wallets/{WALLET_UUID}", client).
+		Return(ratelimit.Result{Allowed: true}, nil).Times(2)
+	mockStore.EXPECT().Take(gomock.Any(), "wallet:"+own.String()+":GET /api/v1/wallets/{WALLET_UUID}", wallet).
+		Return(ratelimit.Result{Allowed: true}, nil)
+	// No Take for the other wallet: its bucket is not charged.
+
+	limiter := ratelimit.NewLimiter(mockStore,
+		ratelimit.RouteLimits{Client: client, Wallet: wallet},
+		ratelimit.WithWalletAccess(mockAccess),
+		ratelimit.WithLogger(logging.Discard()),
+	)
+
+	r := mux.NewRouter()
+	r.Use(limiter.Middleware)
+	r.HandleFunc("/api/v1/wallets/{WALLET_UUID}", func(w http.ResponseWriter, _ *http.Request) {}).Methods(http.MethodGet)
+
+	for _, id := range []uuid.UUID{own, other} {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/wallets/"+id.String(), nil)
+		req = req.WithContext(auth.WithPrincipal(req.Context(), auth.Principal{ID: "key-1", OwnerID: "alice"}))
+		w := httptest.NewRecorder()
+
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Result().StatusCode)
+	}
+}
@@ -0,0 +1,116 @@
+// Package ratelimit throttles API calls with token buckets kept in memory or
+// in Postgres.
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// Limit is a token bucket: Rate tokens per second refill a bucket of Burst
+// tokens, and every request takes one. A zero Rate disables the limit.
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+func (l Limit) Enabled() bool {
+	return l.Rate > 0
+}
+
+// Result of taking a token.
+type Result struct {
+	Allowed bool
+	// Remaining is the number of whole tokens left after the request.
+	Remaining int
+	// RetryAfter is how long until a token is available when the request
+	// was not allowed.
+	RetryAfter time.Duration
+}
+
+// take applies the token bucket to a bucket holding tokens elapsed ago and
+// returns the tokens left and the result.
+func take(tokens float64, elapsed time.Duration, l Limit) (float64, Result) {
+	tokens = math.Min(float64(l.Burst), tokens+elapsed.Seconds()*l.Rate)
+	if tokens < 1 {
+		return tokens, Result{RetryAfter: l.RetryAfter(tokens)}
+	}
+	tokens--
+	return tokens, Result{Allowed: true, Remaining: int(tokens)}
+}
+
+// RetryAfter returns how long a bucket holding tokens takes to refill to one
+// token.
+func (l Limit) RetryAfter(tokens float64) time.Duration {
+	return time.Duration((1 - tokens) / l.Rate * float64(time.Second))
+}
+
+// sweepInterval is how often the memory store drops buckets that have
+// refilled, which are indistinguishable from missing ones.
+const sweepInterval = time.Minute
+
+type bucket struct {
+	tokens    float64
+	updatedAt time.Time
+	limit     Limit
+}
+
+// MemoryStore keeps buckets in process memory. Each replica limits on its
+// own, so with N replicas a client gets up to N times the limit.
+type MemoryStore struct {
+	now func() time.Time
+
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	sweptAt time.Time
+}
+
+type MemoryOption func(*MemoryStore)
+
+// WithClock replaces time.Now as the time source of the buckets.
+func WithClock(now func() time.Time) MemoryOption {
+	return func(s *MemoryStore) {
+		s.now = now
+	}
+}
+
+func NewMemoryStore(opts ...MemoryOption) *MemoryStore {
+	s := &MemoryStore{now: time.Now, buckets: make(map[string]*bucket)}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+func (s *MemoryStore) Take(_ context.Context, key string, l Limit) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if now.Sub(s.sweptAt) >= sweepInterval {
+		s.sweep(now)
+	}
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(l.Burst), updatedAt: now}
+		s.buckets[key] = b
+	}
+
+	var res Result
+	b.tokens, res = take(b.tokens, now.Sub(b.updatedAt), l)
+	b.updatedAt = now
+	b.limit = l
+	return res, nil
+}
+
+func (s *MemoryStore) sweep(now time.Time) {
+	for key, b := range s.buckets {
+		if b.tokens+now.Sub(b.updatedAt).Seconds()*b.limit.Rate >= float64(b.limit.Burst) {
+			delete(s.buckets, key)
+		}
+	}
+	s.sweptAt = now
+}
@@ -0,0 +1,89 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: contract.go
+
+// Package ratelimit_test is a generated GoMock package.
+package ratelimit_test
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	uuid "github.com/google/uuid"
+	ratelimit "github.com/totorialman/go-test-ac/internal/ratelimit"
+)
+
+// Mockstore is a mock of store interface.
+type Mockstore struct {
+	ctrl     *gomock.Controller
+	recorder *MockstoreMockRecorder
+}
+
+// MockstoreMockRecorder is the mock recorder for Mockstore.
+type MockstoreMockRecorder struct {
+	mock *Mockstore
+}
+
+// NewMockstore creates a new mock instance.
+func NewMockstore(ctrl *gomock.Controller) *Mockstore {
+	mock := &Mockstore{ctrl: ctrl}
+	mock.recorder = &MockstoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *Mockstore) EXPECT() *MockstoreMockRecorder {
+	return m.recorder
+}
+
+// Take mocks base method.
+func (m *Mockstore) Take(ctx context.Context, key string, l ratelimit.Limit) (ratelimit.Result, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Take", ctx, key, l)
+	ret0, _ := ret[0].(ratelimit.Result)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Take indicates an expected call of Take.
+func (mr *MockstoreMockRecorder) Take(ctx, key, l interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Take", reflect.TypeOf((*Mockstore)(nil).Take), ctx, key, l)
+}
+
+// MockwalletAccess is a mock of walletAccess interface.
+type MockwalletAccess struct {
+	ctrl     *gomock.Controller
+	recorder *MockwalletAccessMockRecorder
+}
+
+// MockwalletAccessMockRecorder is the mock recorder for MockwalletAccess.
+type MockwalletAccessMockRecorder struct {
+	mock *MockwalletAccess
+}
+
+// NewMockwalletAccess creates a new mock instance.
+func NewMockwalletAccess(ctrl *gomock.Controller) *MockwalletAccess {
+	mock := &MockwalletAccess{ctrl: ctrl}
+	mock.recorder = &MockwalletAccessMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockwalletAccess) EXPECT() *MockwalletAccessMockRecorder {
+	return m.recorder
+}
+
+// AuthorizeWallet mocks base method.
+func (m *MockwalletAccess) AuthorizeWallet(ctx context.Context, walletID uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AuthorizeWallet", ctx, walletID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AuthorizeWallet indicates an expected call of AuthorizeWallet.
+func (mr *MockwalletAccessMockRecorder) AuthorizeWallet(ctx, walletID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AuthorizeWallet", reflect.TypeOf((*MockwalletAccess)(nil).AuthorizeWallet), ctx, walletID)
+}
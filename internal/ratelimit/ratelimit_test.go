@@ -0,0 +1,46 @@
+package ratelimit_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/totorialman/go-test-ac/internal/ratelimit"
+)
+
+func TestMemoryStore_Take(t *testing.T) {
+	now := time.Date(2026, 4, 25, 12, 0, 0, 0, time.UTC)
+	store := ratelimit.NewMemoryStore(ratelimit.WithClock(func() time.Time { return now }))
+	limit := ratelimit.Limit{Rate: 2, Burst: 3}
+	ctx := context.Background()
+
+	for want := 2; want >= 0; want-- {
+		res, err := store.Take(ctx, "a", limit)
+		require.NoError(t, err)
+		assert.True(t, res.Allowed)
+		assert.Equal(t, want, res.Remaining)
+	}
+
+	res, err := store.Take(ctx, "a", limit)
+	require.NoError(t, err)
+	assert.False(t, res.Allowed)
+	assert.Equal(t, 500*time.Millisecond, res.RetryAfter)
+
+	res, err = store.Take(ctx, "b", limit)
+	require.NoError(t, err)
+	assert.True(t, res.Allowed, "buckets are independent")
+
+	now = now.Add(500 * time.Millisecond)
+	res, err = store.Take(ctx, "a", limit)
+	require.NoError(t, err)
+	assert.True(t, res.Allowed, "one token refilled")
+
+	now = now.Add(time.Hour)
+	res, err = store.Take(ctx, "a", limit)
+	require.NoError(t, err)
+	assert.True(t, res.Allowed)
+	assert.Equal(t, 2, res.Remaining, "refill is capped at the burst")
+}
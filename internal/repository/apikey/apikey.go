@@ -0,0 +1,109 @@
+package apikey
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/totorialman/go-test-ac/internal/errors/auth"
+	"github.com/totorialman/go-test-ac/internal/errors/wallet"
+)
+
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+func NewRepository(db *pgxpool.Pool) *Repository {
+	return &Repository{db: db}
+}
+
+func (r *Repository) Create(ctx context.Context, k APIKeyDB) (APIKeyDB, error) {
+	err := r.db.QueryRow(ctx, `
+		INSERT INTO api_keys (name, prefix, key_hash, scopes, wallet_ids, owner_id, expires_at)
+		VALUES ($1, $2, $3, $4, COALESCE($5::uuid[], '{}'), NULLIF($6, ''), $7)
+		RETURNING id, created_at
+	`, k.Name, k.Prefix, k.KeyHash, k.Scopes, k.WalletIDs, k.OwnerID, k.ExpiresAt).Scan(&k.ID, &k.CreatedAt)
+	if err != nil {
+		return APIKeyDB{}, err
+	}
+
+	return k, nil
+}
+
+// FindActive returns the key with the given hash unless it is revoked or
+// expired.
+func (r *Repository) FindActive(ctx context.Context, keyHash []byte) (APIKeyDB, error) {
+	var k APIKeyDB
+	err := r.db.QueryRow(ctx, `
+		SELECT id, name, prefix, scopes, wallet_ids, COALESCE(owner_id, ''), created_at, expires_at
+		FROM api_keys
+		WHERE key_hash = $1
+		  AND revoked_at IS NULL
+		  AND (expires_at IS NULL OR expires_at > now())
+	`, keyHash).Scan(&k.ID, &k.Name, &k.Prefix, &k.Scopes, &k.WalletIDs, &k.OwnerID, &k.CreatedAt, &k.ExpiresAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return APIKeyDB{}, auth.ErrAPIKeyNotFound
+	}
+	if err != nil {
+		return APIKeyDB{}, err
+	}
+
+	return k, nil
+}
+
+func (r *Repository) List(ctx context.Context) ([]APIKeyDB, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT id, name, prefix, scopes, wallet_ids, COALESCE(owner_id, ''), created_at, expires_at, revoked_at
+		FROM api_keys
+		ORDER BY created_at, id
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var res []APIKeyDB
+	for rows.Next() {
+		var k APIKeyDB
+		if err := rows.Scan(&k.ID, &k.Name, &k.Prefix, &k.Scopes, &k.WalletIDs, &k.OwnerID, &k.CreatedAt, &k.ExpiresAt, &k.RevokedAt); err != nil {
+			return nil, err
+		}
+		res = append(res, k)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func (r *Repository) Revoke(ctx context.Context, id uuid.UUID) error {
+	tag, err := r.db.Exec(ctx, `
+		UPDATE api_keys SET revoked_at = now() WHERE id = $1 AND revoked_at IS NULL
+	`, id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return auth.ErrAPIKeyNotFound
+	}
+
+	return nil
+}
+
+// WalletOwner returns the owner of the wallet, empty if it has none.
+func (r *Repository) WalletOwner(ctx context.Context, walletID uuid.UUID) (string, error) {
+	var owner string
+	err := r.db.QueryRow(ctx, `SELECT COALESCE(owner_id, '') FROM wallets WHERE id = $1`, walletID).Scan(&owner)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", wallet.ErrWalletNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return owner, nil
+}
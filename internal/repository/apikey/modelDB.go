@@ -0,0 +1,22 @@
+package apikey
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type APIKeyDB struct {
+	ID      uuid.UUID
+	Name    string
+	Prefix  string
+	KeyHash []byte
+	Scopes  []string
+	// WalletIDs and OwnerID restrict the key; both empty allow every
+	// wallet.
+	WalletIDs []uuid.UUID
+	OwnerID   string
+	CreatedAt time.Time
+	ExpiresAt *time.Time
+	RevokedAt *time.Time
+}
@@ -0,0 +1,16 @@
+package outbox
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type EventDB struct {
+	ID          uuid.UUID
+	Type        string
+	AggregateID uuid.UUID
+	Payload     []byte
+	CreatedAt   time.Time
+	Attempts    int
+}
@@ -0,0 +1,86 @@
+package outbox
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+func NewRepository(db *pgxpool.Pool) *Repository {
+	return &Repository{db: db}
+}
+
+// Claim leases up to limit pending events, oldest first. SKIP LOCKED lets
+// several relays claim disjoint batches; an event whose lease runs out
+// without being published is claimed again.
+func (r *Repository) Claim(ctx context.Context, limit int, lease time.Duration) ([]EventDB, error) {
+	rows, err := r.db.Query(ctx, `
+		WITH claimed AS (
+			UPDATE outbox_events
+			SET locked_until = now() + $2::interval, attempts = attempts + 1
+			WHERE id IN (
+				SELECT id FROM outbox_events
+				WHERE published_at IS NULL AND (locked_until IS NULL OR locked_until < now())
+				ORDER BY created_at
+				LIMIT $1
+				FOR UPDATE SKIP LOCKED
+			)
+			RETURNING id, event_type, aggregate_id, payload, created_at, attempts
+		)
+		SELECT id, event_type, aggregate_id, payload, created_at, attempts
+		FROM claimed
+		ORDER BY created_at, id
+	`, limit, lease)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var res []EventDB
+	for rows.Next() {
+		var e EventDB
+		if err := rows.Scan(&e.ID, &e.Type, &e.AggregateID, &e.Payload, &e.CreatedAt, &e.Attempts); err != nil {
+			return nil, err
+		}
+		res = append(res, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func (r *Repository) MarkPublished(ctx context.Context, id uuid.UUID) error {
+	_, err := r.db.Exec(ctx, `
+		UPDATE outbox_events SET published_at = now(), locked_until = NULL, last_error = NULL
+		WHERE id = $1
+	`, id)
+	return err
+}
+
+// MarkFailed records why delivery failed. The lease is kept, so the event
+// is retried once it expires.
+func (r *Repository) MarkFailed(ctx context.Context, id uuid.UUID, reason string) error {
+	_, err := r.db.Exec(ctx, `UPDATE outbox_events SET last_error = $2 WHERE id = $1`, id, reason)
+	return err
+}
+
+// DeletePublished removes events published more than retention ago.
+func (r *Repository) DeletePublished(ctx context.Context, retention time.Duration) (int64, error) {
+	tag, err := r.db.Exec(ctx, `
+		DELETE FROM outbox_events
+		WHERE published_at IS NOT NULL AND published_at < now() - $1::interval
+	`, retention)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
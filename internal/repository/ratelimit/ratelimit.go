@@ -0,0 +1,67 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/totorialman/go-test-ac/internal/ratelimit"
+)
+
+// Repository keeps token buckets in Postgres so that all replicas share
+// them. Refill is computed from the database clock.
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+func NewRepository(db *pgxpool.Pool) *Repository {
+	return &Repository{db: db}
+}
+
+// Take takes a token from the bucket in a single statement. The update is
+// skipped when the bucket is empty, in which case the tokens it holds are
+// read to tell the caller when to retry.
+func (r *Repository) Take(ctx context.Context, key string, l ratelimit.Limit) (ratelimit.Result, error) {
+	var tokens float64
+	err := r.db.QueryRow(ctx, `
+		INSERT INTO rate_limit_buckets AS b (key, tokens, updated_at)
+		VALUES ($1, $3::float8 - 1, now())
+		ON CONFLICT (key) DO UPDATE
+		SET tokens = LEAST($3::float8, b.tokens + EXTRACT(EPOCH FROM now() - b.updated_at)::float8 * $2::float8) - 1,
+		    updated_at = now()
+		WHERE LEAST($3::float8, b.tokens + EXTRACT(EPOCH FROM now() - b.updated_at)::float8 * $2::float8) >= 1
+		RETURNING tokens
+	`, key, l.Rate, l.Burst).Scan(&tokens)
+	if err == nil {
+		return ratelimit.Result{Allowed: true, Remaining: int(tokens)}, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return ratelimit.Result{}, err
+	}
+
+	err = r.db.QueryRow(ctx, `
+		SELECT LEAST($3::float8, tokens + EXTRACT(EPOCH FROM now() - updated_at)::float8 * $2::float8)
+		FROM rate_limit_buckets
+		WHERE key = $1
+	`, key, l.Rate, l.Burst).Scan(&tokens)
+	if err != nil {
+		return ratelimit.Result{}, err
+	}
+
+	return ratelimit.Result{RetryAfter: l.RetryAfter(tokens)}, nil
+}
+
+// DeleteIdle removes buckets untouched for longer than idle. Any bucket
+// idle for longer than it takes to refill is equivalent to a missing one.
+func (r *Repository) DeleteIdle(ctx context.Context, idle time.Duration) (int64, error) {
+	tag, err := r.db.Exec(ctx, `
+		DELETE FROM rate_limit_buckets WHERE updated_at < now() - $1::interval
+	`, idle)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
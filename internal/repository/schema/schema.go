@@ -0,0 +1,55 @@
+package schema
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+func NewRepository(db *pgxpool.Pool) *Repository {
+	return &Repository{db: db}
+}
+
+// Version returns the schema version recorded by goose. Rolling a migration
+// back adds a row with is_applied = false, so the current version is the
+// newest applied one that was not rolled back afterwards.
+func (r *Repository) Version(ctx context.Context) (int64, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT version_id, is_applied
+		FROM goose_db_version
+		ORDER BY id DESC
+	`)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	rolledBack := map[int64]bool{}
+	for rows.Next() {
+		var (
+			version int64
+			applied bool
+		)
+		if err := rows.Scan(&version, &applied); err != nil {
+			return 0, err
+		}
+		if !applied {
+			rolledBack[version] = true
+			continue
+		}
+		if rolledBack[version] {
+			delete(rolledBack, version)
+			continue
+		}
+		return version, nil
+	}
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	return 0, nil
+}
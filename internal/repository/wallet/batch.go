@@ -0,0 +1,66 @@
+package wallet
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/totorialman/go-test-ac/internal/domain"
+	"github.com/totorialman/go-test-ac/internal/errors/wallet"
+	"github.com/totorialman/go-test-ac/internal/tracing"
+)
+
+// Batch applies ops in order in one transaction and returns the balance
+// after each of them. If any operation fails nothing is applied and the
+// error is a *wallet.BatchItemError naming it.
+func (r *Repository) Batch(ctx context.Context, ops []OperationDB) ([]BalanceDB, error) {
+	ctx, span := tracing.StartStatement(ctx, "wallet.Repository.Batch")
+	defer span.End()
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	// Every wallet is locked up front in id order, as in Transfer, so two
+	// batches touching the same wallets in a different order cannot
+	// deadlock. Wallets that do not exist yet are left to deposit.
+	ids := make([]uuid.UUID, 0, len(ops))
+	for _, op := range ops {
+		ids = append(ids, op.Wallet.ID)
+	}
+
+	_, err = tx.Exec(ctx, `
+		SELECT id FROM wallets
+		WHERE id = ANY($1)
+		ORDER BY id
+		FOR UPDATE
+	`, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	balances := make([]BalanceDB, len(ops))
+	for i, op := range ops {
+		var b BalanceDB
+		switch op.OperationType {
+		case domain.Deposit:
+			b, err = r.deposit(ctx, tx, op.Wallet)
+		case domain.Withdraw:
+			b, err = r.withdraw(ctx, tx, op.Wallet)
+		default:
+			err = wallet.ErrInvalidOperation
+		}
+		if err != nil {
+			return nil, &wallet.BatchItemError{Index: i, Err: err}
+		}
+		balances[i] = b
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return balances, nil
+}
@@ -0,0 +1,64 @@
+package wallet
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/totorialman/go-test-ac/internal/domain"
+	"github.com/totorialman/go-test-ac/internal/groupcommit"
+	"github.com/totorialman/go-test-ac/internal/tracing"
+)
+
+type groupCommitter = groupcommit.Batcher[uuid.UUID, OperationDB, BalanceDB]
+
+// WithGroupCommit makes deposits and withdrawals to the same wallet that
+// arrive within window of each other, up to maxOps of them, share one
+// transaction and one commit. Each operation still succeeds or fails on its
+// own. A zero window, the default, disables grouping.
+func WithGroupCommit(window time.Duration, maxOps int) Option {
+	return func(r *Repository) {
+		if window > 0 {
+			r.groups = groupcommit.New(window, maxOps, r.commitGroup)
+		}
+	}
+}
+
+// commitGroup applies ops to wallet id in order in one transaction. Every
+// operation runs in a savepoint, so a rejected one is rolled back alone and
+// the next sees the balance left by those applied before it. If the
+// connection breaks the commit fails too, and every operation fails with it.
+func (r *Repository) commitGroup(ctx context.Context, id uuid.UUID, ops []OperationDB) []groupcommit.Result[BalanceDB] {
+	ctx, span := tracing.StartStatement(ctx, "wallet.Repository.commitGroup", tracing.WalletID(id), attribute.Int("wallet.group_size", len(ops)))
+	defer span.End()
+
+	results := make([]groupcommit.Result[BalanceDB], len(ops))
+	err := pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		for i, op := range ops {
+			err := pgx.BeginFunc(ctx, tx, func(sp pgx.Tx) error {
+				var err error
+				if op.OperationType == domain.Withdraw {
+					results[i].Value, err = r.withdraw(ctx, sp, op.Wallet)
+				} else {
+					results[i].Value, err = r.deposit(ctx, sp, op.Wallet)
+				}
+				return err
+			})
+			if err != nil {
+				results[i] = groupcommit.Result[BalanceDB]{Err: err}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		tracing.RecordError(span, err)
+		for i := range results {
+			results[i] = groupcommit.Result[BalanceDB]{Err: err}
+		}
+	}
+
+	return results
+}
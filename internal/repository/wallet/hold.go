@@ -0,0 +1,283 @@
+package wallet
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/totorialman/go-test-ac/internal/domain"
+	"github.com/totorialman/go-test-ac/internal/errors/wallet"
+	"github.com/totorialman/go-test-ac/internal/tracing"
+)
+
+type querier interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// heldAmount returns the funds reserved by active holds that have not
+// expired yet. Callers that go on to change the balance must hold the
+// wallet row lock, since holds are only placed under that lock.
+func heldAmount(ctx context.Context, q querier, walletID uuid.UUID) (int64, error) {
+	var held int64
+	err := q.QueryRow(ctx, `
+		SELECT COALESCE(SUM(amount), 0)::BIGINT
+		FROM wallet_holds
+		WHERE wallet_id = $1 AND status = 'ACTIVE' AND expires_at > now()
+	`, walletID).Scan(&held)
+	return held, err
+}
+
+func (r *Repository) Authorize(ctx context.Context, a AuthorizeDB) (HoldDB, BalanceDB, error) {
+	ctx, span := tracing.StartStatement(ctx, "wallet.Repository.Authorize", tracing.WalletID(a.WalletID))
+	defer span.End()
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return HoldDB{}, BalanceDB{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	current, err := r.lockWallet(ctx, tx, a.WalletID)
+	if err != nil {
+		return HoldDB{}, BalanceDB{}, err
+	}
+
+	if a.Currency != "" && a.Currency != current.Currency {
+		return HoldDB{}, BalanceDB{}, wallet.ErrCurrencyMismatch
+	}
+
+	held, err := heldAmount(ctx, tx, a.WalletID)
+	if err != nil {
+		return HoldDB{}, BalanceDB{}, err
+	}
+	current.Available = current.Balance - held
+	if current.Available < a.Amount {
+		return HoldDB{}, BalanceDB{}, wallet.ErrNotEnoughFunds
+	}
+
+	// A hold is checked as the withdrawal it will become, so that it can
+	// be captured later instead of reserving funds until it expires.
+	if err := checkLimits(ctx, tx, a.CheckLimits, a.WalletID, domain.Withdraw, a.Amount, current); err != nil {
+		return HoldDB{}, BalanceDB{}, err
+	}
+
+	h := HoldDB{
+		WalletID: a.WalletID,
+		Amount:   a.Amount,
+		Status:   domain.HoldActive,
+	}
+	err = tx.QueryRow(ctx, `
+		INSERT INTO wallet_holds (wallet_id, amount, status, expires_at)
+		VALUES ($1, $2, $3, now() + $4::interval)
+		RETURNING id, expires_at, created_at
+	`, h.WalletID, h.Amount, h.Status, a.TTL).Scan(&h.ID, &h.ExpiresAt, &h.CreatedAt)
+	if err != nil {
+		return HoldDB{}, BalanceDB{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return HoldDB{}, BalanceDB{}, err
+	}
+
+	current.Available -= a.Amount
+	return h, current, nil
+}
+
+func (r *Repository) Capture(ctx context.Context, c CaptureDB) (HoldDB, BalanceDB, error) {
+	ctx, span := tracing.StartStatement(ctx, "wallet.Repository.Capture")
+	defer span.End()
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return HoldDB{}, BalanceDB{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	h, current, err := r.lockHold(ctx, tx, c.HoldID, true)
+	if err != nil {
+		return HoldDB{}, BalanceDB{}, err
+	}
+	if err := checkHoldActive(h); err != nil {
+		return HoldDB{}, BalanceDB{}, err
+	}
+	if c.Amount == 0 {
+		c.Amount = h.Amount
+	}
+	if c.Amount > h.Amount {
+		return HoldDB{}, BalanceDB{}, wallet.ErrCaptureExceedsHold
+	}
+
+	held, err := heldAmount(ctx, tx, h.WalletID)
+	if err != nil {
+		return HoldDB{}, BalanceDB{}, err
+	}
+	current.Available = current.Balance - held
+	if err := checkLimits(ctx, tx, c.CheckLimits, h.WalletID, domain.Capture, c.Amount, current); err != nil {
+		return HoldDB{}, BalanceDB{}, err
+	}
+
+	// The hold itself guarantees the funds: balance never drops below the
+	// sum of active holds, so no further balance check is needed.
+	_, err = tx.Exec(ctx, `
+		UPDATE wallet_holds SET status = $2, captured_amount = $3, updated_at = now()
+		WHERE id = $1
+	`, h.ID, domain.HoldCaptured, c.Amount)
+	if err != nil {
+		return HoldDB{}, BalanceDB{}, err
+	}
+	h.Status = domain.HoldCaptured
+	h.CapturedAmount = c.Amount
+
+	current.Balance -= c.Amount
+	if _, err := tx.Exec(ctx, `UPDATE wallets SET balance = balance - $2 WHERE id = $1`, h.WalletID, c.Amount); err != nil {
+		return HoldDB{}, BalanceDB{}, err
+	}
+
+	_, err = insertTransaction(ctx, tx, TransactionDB{
+		WalletID:      h.WalletID,
+		OperationType: domain.Capture,
+		Amount:        c.Amount,
+		BalanceAfter:  current.Balance,
+	}, current.Currency)
+	if err != nil {
+		return HoldDB{}, BalanceDB{}, err
+	}
+
+	held, err = heldAmount(ctx, tx, h.WalletID)
+	if err != nil {
+		return HoldDB{}, BalanceDB{}, err
+	}
+	current.Available = current.Balance - held
+
+	if err := tx.Commit(ctx); err != nil {
+		return HoldDB{}, BalanceDB{}, err
+	}
+
+	return h, current, nil
+}
+
+func (r *Repository) Void(ctx context.Context, holdID uuid.UUID) (HoldDB, BalanceDB, error) {
+	ctx, span := tracing.StartStatement(ctx, "wallet.Repository.Void")
+	defer span.End()
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return HoldDB{}, BalanceDB{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	h, current, err := r.lockHold(ctx, tx, holdID, false)
+	if err != nil {
+		return HoldDB{}, BalanceDB{}, err
+	}
+	if err := checkHoldActive(h); err != nil {
+		return HoldDB{}, BalanceDB{}, err
+	}
+
+	_, err = tx.Exec(ctx, `UPDATE wallet_holds SET status = $2, updated_at = now() WHERE id = $1`, h.ID, domain.HoldVoided)
+	if err != nil {
+		return HoldDB{}, BalanceDB{}, err
+	}
+	h.Status = domain.HoldVoided
+
+	held, err := heldAmount(ctx, tx, h.WalletID)
+	if err != nil {
+		return HoldDB{}, BalanceDB{}, err
+	}
+	current.Available = current.Balance - held
+
+	if err := tx.Commit(ctx); err != nil {
+		return HoldDB{}, BalanceDB{}, err
+	}
+
+	return h, current, nil
+}
+
+// ExpireHolds marks every active hold past its expiry as EXPIRED. Expired
+// holds already stop reserving funds on their own; this only brings the
+// stored status in line.
+func (r *Repository) ExpireHolds(ctx context.Context) (int64, error) {
+	ctx, span := tracing.StartStatement(ctx, "wallet.Repository.ExpireHolds")
+	defer span.End()
+
+	tag, err := r.db.Exec(ctx, `
+		UPDATE wallet_holds SET status = $1, updated_at = now()
+		WHERE status = 'ACTIVE' AND expires_at <= now()
+	`, domain.HoldExpired)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+// HoldWalletID returns the wallet the hold was placed on.
+func (r *Repository) HoldWalletID(ctx context.Context, holdID uuid.UUID) (uuid.UUID, error) {
+	var walletID uuid.UUID
+	err := r.db.QueryRow(ctx, `SELECT wallet_id FROM wallet_holds WHERE id = $1`, holdID).Scan(&walletID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return uuid.Nil, wallet.ErrHoldNotFound
+	}
+	return walletID, err
+}
+
+// lockHold locks the wallet first and the hold second, the same order
+// Authorize uses, so concurrent hold operations cannot deadlock. Voiding only
+// releases funds, so it is allowed on a frozen wallet.
+func (r *Repository) lockHold(ctx context.Context, tx pgx.Tx, holdID uuid.UUID, requireActive bool) (HoldDB, BalanceDB, error) {
+	var walletID uuid.UUID
+	err := tx.QueryRow(ctx, `SELECT wallet_id FROM wallet_holds WHERE id = $1`, holdID).Scan(&walletID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return HoldDB{}, BalanceDB{}, wallet.ErrHoldNotFound
+		}
+		return HoldDB{}, BalanceDB{}, err
+	}
+
+	var b BalanceDB
+	var status string
+	var hot bool
+	err = tx.QueryRow(ctx, `SELECT balance, currency, status, hot FROM wallets WHERE id = $1 FOR UPDATE`, walletID).Scan(&b.Balance, &b.Currency, &status, &hot)
+	if err != nil {
+		return HoldDB{}, BalanceDB{}, err
+	}
+	b.Balance, err = r.lockedBalance(ctx, tx, walletID, b.Balance, hot)
+	if err != nil {
+		return HoldDB{}, BalanceDB{}, err
+	}
+	if requireActive {
+		if err := checkWalletActive(status); err != nil {
+			return HoldDB{}, BalanceDB{}, err
+		}
+	}
+
+	// An active hold past its expiry is reported as EXPIRED even before
+	// ExpireHolds gets to it. Expiry is judged by the database clock, as in
+	// heldAmount and ExpireHolds, so all three agree on it.
+	var h HoldDB
+	err = tx.QueryRow(ctx, `
+		SELECT id, wallet_id, amount, captured_amount,
+			CASE WHEN status = $2 AND expires_at <= now() THEN $3 ELSE status END,
+			expires_at, created_at
+		FROM wallet_holds
+		WHERE id = $1
+		FOR UPDATE
+	`, holdID, domain.HoldActive, domain.HoldExpired).Scan(&h.ID, &h.WalletID, &h.Amount, &h.CapturedAmount, &h.Status, &h.ExpiresAt, &h.CreatedAt)
+	if err != nil {
+		return HoldDB{}, BalanceDB{}, err
+	}
+
+	return h, b, nil
+}
+
+func checkHoldActive(h HoldDB) error {
+	switch h.Status {
+	case domain.HoldActive:
+		return nil
+	case domain.HoldExpired:
+		return wallet.ErrHoldExpired
+	default:
+		return wallet.ErrHoldNotActive
+	}
+}
@@ -0,0 +1,123 @@
+package wallet_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/totorialman/go-test-ac/internal/domain"
+	wErr "github.com/totorialman/go-test-ac/internal/errors/wallet"
+	"github.com/totorialman/go-test-ac/internal/logging"
+	"github.com/totorialman/go-test-ac/internal/repository/wallet"
+)
+
+// expire moves the hold's expiry into the past, as if its TTL had run out.
+func expire(tb testing.TB, pool *pgxpool.Pool, holdID uuid.UUID) {
+	_, err := pool.Exec(context.Background(), `UPDATE wallet_holds SET expires_at = now() - interval '1 second' WHERE id = $1`, holdID)
+	require.NoError(tb, err)
+}
+
+func TestHold_PartialCapture(t *testing.T) {
+	pool := testPool(t)
+	repo := wallet.NewRepository(pool, logging.Discard())
+	walletID := newWallet(t, repo)
+	fund(t, repo, walletID, 200)
+	ctx := context.Background()
+
+	h, b, err := repo.Authorize(ctx, wallet.AuthorizeDB{WalletID: walletID, Amount: 100, TTL: time.Hour})
+	require.NoError(t, err)
+	assert.Equal(t, domain.HoldActive, h.Status)
+	assert.Equal(t, wallet.BalanceDB{Balance: 200, Available: 100, Currency: domain.DefaultCurrency}, b)
+
+	_, _, err = repo.Authorize(ctx, wallet.AuthorizeDB{WalletID: walletID, Amount: 150, TTL: time.Hour})
+	assert.ErrorIs(t, err, wErr.ErrNotEnoughFunds, "held funds cannot be reserved twice")
+
+	_, _, err = repo.Capture(ctx, wallet.CaptureDB{HoldID: h.ID, Amount: 150})
+	assert.ErrorIs(t, err, wErr.ErrCaptureExceedsHold)
+
+	h, b, err = repo.Capture(ctx, wallet.CaptureDB{HoldID: h.ID, Amount: 30})
+	require.NoError(t, err)
+	assert.Equal(t, domain.HoldCaptured, h.Status)
+	assert.Equal(t, int64(30), h.CapturedAmount)
+	assert.Equal(t, wallet.BalanceDB{Balance: 170, Available: 170, Currency: domain.DefaultCurrency}, b,
+		"the part that was not captured is released")
+
+	_, _, err = repo.Capture(ctx, wallet.CaptureDB{HoldID: h.ID})
+	assert.ErrorIs(t, err, wErr.ErrHoldNotActive)
+
+	got, err := repo.GetBalance(ctx, walletID)
+	require.NoError(t, err)
+	assert.Equal(t, b, got)
+
+	history, err := repo.History(ctx, wallet.HistoryFilterDB{WalletID: walletID, OperationType: domain.Capture, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, history, 1)
+	assert.Equal(t, int64(30), history[0].Amount)
+	assert.Equal(t, int64(170), history[0].BalanceAfter)
+}
+
+func TestHold_Void(t *testing.T) {
+	pool := testPool(t)
+	repo := wallet.NewRepository(pool, logging.Discard())
+	walletID := newWallet(t, repo)
+	fund(t, repo, walletID, 200)
+	ctx := context.Background()
+
+	h, _, err := repo.Authorize(ctx, wallet.AuthorizeDB{WalletID: walletID, Amount: 120, TTL: time.Hour})
+	require.NoError(t, err)
+
+	h, b, err := repo.Void(ctx, h.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.HoldVoided, h.Status)
+	assert.Equal(t, wallet.BalanceDB{Balance: 200, Available: 200, Currency: domain.DefaultCurrency}, b)
+
+	_, _, err = repo.Void(ctx, h.ID)
+	assert.ErrorIs(t, err, wErr.ErrHoldNotActive)
+	_, _, err = repo.Capture(ctx, wallet.CaptureDB{HoldID: h.ID})
+	assert.ErrorIs(t, err, wErr.ErrHoldNotActive)
+
+	_, _, err = repo.Void(ctx, uuid.New())
+	assert.ErrorIs(t, err, wErr.ErrHoldNotFound)
+}
+
+func TestHold_Expiry(t *testing.T) {
+	pool := testPool(t)
+	repo := wallet.NewRepository(pool, logging.Discard())
+	walletID := newWallet(t, repo)
+	fund(t, repo, walletID, 200)
+	ctx := context.Background()
+
+	h, _, err := repo.Authorize(ctx, wallet.AuthorizeDB{WalletID: walletID, Amount: 150, TTL: time.Hour})
+	require.NoError(t, err)
+	expire(t, pool, h.ID)
+
+	// The hold stops reserving funds as soon as it expires, before
+	// ExpireHolds has marked it.
+	got, err := repo.GetBalance(ctx, walletID)
+	require.NoError(t, err)
+	assert.Equal(t, wallet.BalanceDB{Balance: 200, Available: 200, Currency: domain.DefaultCurrency}, got)
+
+	_, _, err = repo.Capture(ctx, wallet.CaptureDB{HoldID: h.ID})
+	assert.ErrorIs(t, err, wErr.ErrHoldExpired)
+
+	_, b, err := repo.Authorize(ctx, wallet.AuthorizeDB{WalletID: walletID, Amount: 200, TTL: time.Hour})
+	require.NoError(t, err, "released funds can be held again")
+	assert.Equal(t, int64(0), b.Available)
+
+	n, err := repo.ExpireHolds(ctx)
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, n, int64(1))
+
+	var status string
+	err = pool.QueryRow(ctx, `SELECT status FROM wallet_holds WHERE id = $1`, h.ID).Scan(&status)
+	require.NoError(t, err)
+	assert.Equal(t, domain.HoldExpired, status)
+
+	_, _, err = repo.Void(ctx, h.ID)
+	assert.ErrorIs(t, err, wErr.ErrHoldExpired)
+}
@@ -0,0 +1,230 @@
+package wallet
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/totorialman/go-test-ac/internal/domain"
+	"github.com/totorialman/go-test-ac/internal/errors/wallet"
+	"github.com/totorialman/go-test-ac/internal/tracing"
+)
+
+// exactBalance is the balance of the wallets row aliased w: its balance
+// column plus the deposits parked in its sub-balance buckets that the
+// compactor has not folded back yet. Evaluated in one statement it is exact
+// for the snapshot of that statement.
+const exactBalance = `(w.balance + COALESCE((
+	SELECT SUM(b.amount) FROM wallet_balance_buckets b WHERE b.wallet_id = w.id
+), 0))::BIGINT`
+
+// useBuckets reports whether a deposit to the wallet may go to a bucket: the
+// wallet is hot and, when limits are enforced, has no balance cap. A capped
+// wallet takes the row lock like any other, as concurrent bucket deposits
+// each compare the cap with a balance that lacks the others and could
+// exceed it together.
+//
+// Nothing is locked yet, so the wallet may be unflagged or capped right
+// after. A deposit that still lands in a bucket is counted all the same:
+// every balance read includes the buckets and the compactor folds them
+// whether the wallet is hot or not. A cap set meanwhile applies from the
+// next deposit on.
+func useBuckets(ctx context.Context, tx pgx.Tx, w WalletDB) (bool, error) {
+	var hot, capped bool
+	err := tx.QueryRow(ctx, `
+		SELECT w.hot, COALESCE(o.max_balance, t.max_balance) IS NOT NULL
+		FROM wallets w
+		JOIN wallet_limit_tiers t ON t.name = w.limit_tier
+		LEFT JOIN wallet_limit_overrides o ON o.wallet_id = w.id
+		WHERE w.id = $1
+	`, w.ID).Scan(&hot, &capped)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, wallet.ErrWalletNotFound
+		}
+		return false, err
+	}
+	return hot && !(capped && w.CheckLimits != nil), nil
+}
+
+// lockedBalance returns the balance, base being the balance column, of a
+// wallet whose row tx has locked FOR UPDATE. The locking statement cannot
+// sum the buckets itself: it reads them in the snapshot taken before waiting
+// for the lock and would miss deposits committed meanwhile. A new statement
+// sees all of them, and no deposit to a hot wallet can start while its row
+// is locked.
+func (r *Repository) lockedBalance(ctx context.Context, tx pgx.Tx, id uuid.UUID, base int64, hot bool) (int64, error) {
+	if !hot && r.hotBuckets == 0 {
+		return base, nil
+	}
+
+	var balance int64
+	err := tx.QueryRow(ctx, `SELECT `+exactBalance+` FROM wallets w WHERE w.id = $1`, id).Scan(&balance)
+	return balance, err
+}
+
+// depositHot credits a hot wallet through one of its buckets, chosen at
+// random so that concurrent deposits rarely wait for each other. The wallet
+// row is only locked FOR KEY SHARE, which deposits share, while withdrawals
+// and status changes, locking it FOR UPDATE, still exclude them. The balance
+// returned and written as balance_after is not authoritative: it lacks the
+// deposits to the same wallet that are still in flight, so it need not grow
+// monotonically along the ledger.
+func (r *Repository) depositHot(ctx context.Context, tx pgx.Tx, w WalletDB) (BalanceDB, error) {
+	var current BalanceDB
+	var status string
+	err := tx.QueryRow(ctx, `
+		SELECT `+exactBalance+`, w.currency, w.status
+		FROM wallets w
+		WHERE w.id = $1
+		FOR KEY SHARE
+	`, w.ID).Scan(&current.Balance, &current.Currency, &status)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return BalanceDB{}, wallet.ErrWalletNotFound
+		}
+		return BalanceDB{}, err
+	}
+	if err := checkWalletActive(status); err != nil {
+		return BalanceDB{}, err
+	}
+	if w.Currency != "" && w.Currency != current.Currency {
+		return BalanceDB{}, wallet.ErrCurrencyMismatch
+	}
+
+	held, err := heldAmount(ctx, tx, w.ID)
+	if err != nil {
+		return BalanceDB{}, err
+	}
+	current.Available = current.Balance - held
+
+	if err := checkLimits(ctx, tx, w.CheckLimits, w.ID, domain.Deposit, w.Amount, current); err != nil {
+		return BalanceDB{}, err
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO wallet_balance_buckets AS b (wallet_id, bucket, amount)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (wallet_id, bucket) DO UPDATE SET amount = b.amount + EXCLUDED.amount
+	`, w.ID, rand.IntN(r.hotBuckets), w.Amount)
+	if err != nil {
+		return BalanceDB{}, err
+	}
+	newBalance := BalanceDB{
+		Balance:   current.Balance + w.Amount,
+		Available: current.Available + w.Amount,
+		Currency:  current.Currency,
+	}
+
+	_, err = insertTransaction(ctx, tx, TransactionDB{
+		WalletID:      w.ID,
+		OperationType: domain.Deposit,
+		Amount:        w.Amount,
+		BalanceAfter:  newBalance.Balance,
+	}, newBalance.Currency)
+	if err != nil {
+		return BalanceDB{}, err
+	}
+
+	if err := storeIdempotencyResult(ctx, tx, w, newBalance.Balance); err != nil {
+		return BalanceDB{}, err
+	}
+
+	return newBalance, nil
+}
+
+// SetHot flags or unflags a wallet as hot. Unflagging folds its buckets
+// back into the wallets row right away.
+func (r *Repository) SetHot(ctx context.Context, id uuid.UUID, hot bool) (WalletInfoDB, error) {
+	ctx, span := tracing.StartStatement(ctx, "wallet.Repository.SetHot", tracing.WalletID(id))
+	defer span.End()
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return WalletInfoDB{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := scanWalletInfo(tx.QueryRow(ctx, selectWalletInfo+` FOR UPDATE`, id)); err != nil {
+		return WalletInfoDB{}, err
+	}
+
+	if _, err := tx.Exec(ctx, `UPDATE wallets SET hot = $2, updated_at = now() WHERE id = $1`, id, hot); err != nil {
+		return WalletInfoDB{}, err
+	}
+	if !hot {
+		if err := foldBuckets(ctx, tx, id); err != nil {
+			return WalletInfoDB{}, err
+		}
+	}
+
+	info, err := scanWalletInfo(tx.QueryRow(ctx, selectWalletInfo, id))
+	if err != nil {
+		return WalletInfoDB{}, err
+	}
+	held, err := heldAmount(ctx, tx, id)
+	if err != nil {
+		return WalletInfoDB{}, err
+	}
+	info.Balance.Available = info.Balance.Balance - held
+
+	if err := tx.Commit(ctx); err != nil {
+		return WalletInfoDB{}, err
+	}
+
+	return info, nil
+}
+
+// CompactHotWallets folds the buckets of every wallet back into its wallets
+// row and returns how many wallets it compacted. Each wallet is compacted in
+// a transaction of its own, so deposits wait at most for one of them.
+func (r *Repository) CompactHotWallets(ctx context.Context) (int64, error) {
+	ctx, span := tracing.StartStatement(ctx, "wallet.Repository.CompactHotWallets")
+	defer span.End()
+
+	rows, err := r.db.Query(ctx, `SELECT DISTINCT wallet_id FROM wallet_balance_buckets WHERE amount <> 0`)
+	if err != nil {
+		return 0, err
+	}
+	ids, err := pgx.CollectRows(rows, pgx.RowTo[uuid.UUID])
+	if err != nil {
+		return 0, err
+	}
+
+	var compacted int64
+	for _, id := range ids {
+		err := pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+			// Compactors on other replicas queue here instead of updating
+			// the same buckets in a different order. Deposits only share
+			// the row and are not blocked.
+			if _, err := tx.Exec(ctx, `SELECT 1 FROM wallets WHERE id = $1 FOR NO KEY UPDATE`, id); err != nil {
+				return err
+			}
+			return foldBuckets(ctx, tx, id)
+		})
+		if err != nil {
+			return compacted, err
+		}
+		compacted++
+	}
+
+	return compacted, nil
+}
+
+// foldBuckets moves the bucket amounts of a wallet into its balance column
+// in one statement, so that every snapshot sees each amount exactly once.
+func foldBuckets(ctx context.Context, tx pgx.Tx, id uuid.UUID) error {
+	_, err := tx.Exec(ctx, `
+		WITH moved AS (
+			UPDATE wallet_balance_buckets SET amount = 0
+			WHERE wallet_id = $1 AND amount <> 0
+			RETURNING amount
+		)
+		UPDATE wallets SET balance = balance + (SELECT COALESCE(SUM(amount), 0) FROM moved)::BIGINT
+		WHERE id = $1
+	`, id)
+	return err
+}
@@ -0,0 +1,62 @@
+package wallet_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	wErr "github.com/totorialman/go-test-ac/internal/errors/wallet"
+	"github.com/totorialman/go-test-ac/internal/logging"
+	"github.com/totorialman/go-test-ac/internal/repository/wallet"
+)
+
+// capBalance is the MaxBalance part of the usecase limit check.
+func capBalance(s wallet.LimitStateDB) error {
+	if s.Limits.MaxBalance != nil && s.Balance.Balance+s.Amount > *s.Limits.MaxBalance {
+		return wErr.ErrLimitExceeded
+	}
+	return nil
+}
+
+func TestHotWallet_ConcurrentDepositsRespectCap(t *testing.T) {
+	pool := testPool(t)
+	repo := wallet.NewRepository(pool, logging.Discard(), wallet.WithHotBuckets(8))
+	walletID := newWallet(t, repo)
+	ctx := context.Background()
+
+	_, err := repo.SetHot(ctx, walletID, true)
+	require.NoError(t, err)
+	maxBalance := int64(1000)
+	_, err = repo.SetLimits(ctx, walletID, wallet.LimitsDB{MaxBalance: &maxBalance})
+	require.NoError(t, err)
+
+	const deposits = 30
+	var mu sync.Mutex
+	var accepted int
+	var wg sync.WaitGroup
+	for range deposits {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := repo.Deposit(ctx, wallet.WalletDB{ID: walletID, Amount: 100, CheckLimits: capBalance})
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				assert.ErrorIs(t, err, wErr.ErrLimitExceeded)
+				return
+			}
+			accepted++
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, 10, accepted, "deposits fill the wallet up to its cap")
+
+	got, err := repo.GetBalance(ctx, walletID)
+	require.NoError(t, err)
+	assert.Equal(t, maxBalance, got.Balance)
+}
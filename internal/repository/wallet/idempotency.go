@@ -0,0 +1,80 @@
+package wallet
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/totorialman/go-test-ac/internal/errors/wallet"
+	"github.com/totorialman/go-test-ac/internal/tracing"
+)
+
+// claimIdempotencyKey reserves the key for the current transaction. If the
+// key was already used by a committed operation, the stored balance is
+// returned with replayed set to true. A concurrent request holding the same
+// key blocks the INSERT until it commits or rolls back, so only one of them
+// is ever applied.
+func claimIdempotencyKey(ctx context.Context, tx pgx.Tx, w WalletDB) (balance BalanceDB, replayed bool, err error) {
+	if w.Idempotency.Key == "" {
+		return BalanceDB{}, false, nil
+	}
+
+	_, err = tx.Exec(ctx, `DELETE FROM idempotency_keys WHERE key = $1 AND expires_at <= now()`, w.Idempotency.Key)
+	if err != nil {
+		return BalanceDB{}, false, err
+	}
+
+	tag, err := tx.Exec(ctx, `
+		INSERT INTO idempotency_keys (key, request_hash, wallet_id, expires_at)
+		VALUES ($1, $2, $3, now() + $4::interval)
+		ON CONFLICT (key) DO NOTHING
+	`, w.Idempotency.Key, w.Idempotency.RequestHash, w.ID, w.Idempotency.TTL)
+	if err != nil {
+		return BalanceDB{}, false, err
+	}
+	if tag.RowsAffected() == 1 {
+		return BalanceDB{}, false, nil
+	}
+
+	var hash string
+	err = tx.QueryRow(ctx, `
+		SELECT k.request_hash, k.balance, w.currency
+		FROM idempotency_keys k
+		JOIN wallets w ON w.id = k.wallet_id
+		WHERE k.key = $1
+	`, w.Idempotency.Key).Scan(&hash, &balance.Balance, &balance.Currency)
+	if err != nil {
+		return BalanceDB{}, false, err
+	}
+	if hash != w.Idempotency.RequestHash {
+		return BalanceDB{}, false, wallet.ErrIdempotencyKeyReused
+	}
+
+	held, err := heldAmount(ctx, tx, w.ID)
+	if err != nil {
+		return BalanceDB{}, false, err
+	}
+	balance.Available = balance.Balance - held
+
+	return balance, true, nil
+}
+
+func storeIdempotencyResult(ctx context.Context, tx pgx.Tx, w WalletDB, balance int64) error {
+	if w.Idempotency.Key == "" {
+		return nil
+	}
+
+	_, err := tx.Exec(ctx, `UPDATE idempotency_keys SET balance = $2 WHERE key = $1`, w.Idempotency.Key, balance)
+	return err
+}
+
+func (r *Repository) DeleteExpiredIdempotencyKeys(ctx context.Context) (int64, error) {
+	ctx, span := tracing.StartStatement(ctx, "wallet.Repository.DeleteExpiredIdempotencyKeys")
+	defer span.End()
+
+	tag, err := r.db.Exec(ctx, `DELETE FROM idempotency_keys WHERE expires_at <= now()`)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
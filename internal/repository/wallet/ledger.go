@@ -0,0 +1,103 @@
+package wallet
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/totorialman/go-test-ac/internal/domain"
+	"github.com/totorialman/go-test-ac/internal/errors/wallet"
+	"github.com/totorialman/go-test-ac/internal/tracing"
+)
+
+// insertTransaction appends an entry to the ledger together with the
+// wallet.balance_changed event announcing it. It must be called inside the
+// same transaction that changes the wallet balance.
+func insertTransaction(ctx context.Context, tx pgx.Tx, t TransactionDB, currency string) (TransactionDB, error) {
+	err := tx.QueryRow(ctx, `
+		INSERT INTO wallet_transactions (wallet_id, operation_type, amount, balance_after, counterparty_wallet_id, reversal_of)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at
+	`, t.WalletID, t.OperationType, t.Amount, t.BalanceAfter, t.CounterpartyID, t.ReversalOf).Scan(&t.ID, &t.CreatedAt)
+	if err != nil {
+		return TransactionDB{}, err
+	}
+
+	err = insertOutboxEvent(ctx, tx, domain.EventBalanceChanged, t.WalletID, domain.BalanceChanged{
+		WalletID:      t.WalletID,
+		TransactionID: t.ID,
+		OperationType: t.OperationType,
+		Amount:        t.Amount,
+		BalanceAfter:  t.BalanceAfter,
+		Currency:      currency,
+		OccurredAt:    t.CreatedAt,
+	})
+	if err != nil {
+		return TransactionDB{}, err
+	}
+
+	return t, nil
+}
+
+func (r *Repository) History(ctx context.Context, f HistoryFilterDB) ([]TransactionDB, error) {
+	ctx, span := tracing.StartStatement(ctx, "wallet.Repository.History", tracing.WalletID(f.WalletID))
+	defer span.End()
+
+	query := `
+		SELECT id, wallet_id, operation_type, amount, balance_after, counterparty_wallet_id, reversal_of, created_at
+		FROM wallet_transactions
+		WHERE wallet_id = $1`
+	args := []any{f.WalletID}
+
+	if f.OperationType != "" {
+		args = append(args, f.OperationType)
+		query += fmt.Sprintf(" AND operation_type = $%d", len(args))
+	}
+	if !f.From.IsZero() {
+		args = append(args, f.From)
+		query += fmt.Sprintf(" AND created_at >= $%d", len(args))
+	}
+	if !f.To.IsZero() {
+		args = append(args, f.To)
+		query += fmt.Sprintf(" AND created_at < $%d", len(args))
+	}
+	if !f.BeforeCreatedAt.IsZero() {
+		args = append(args, f.BeforeCreatedAt, f.BeforeID)
+		query += fmt.Sprintf(" AND (created_at, id) < ($%d, $%d)", len(args)-1, len(args))
+	}
+
+	args = append(args, f.Limit)
+	query += fmt.Sprintf(" ORDER BY created_at DESC, id DESC LIMIT $%d", len(args))
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var res []TransactionDB
+	for rows.Next() {
+		var t TransactionDB
+		if err := rows.Scan(&t.ID, &t.WalletID, &t.OperationType, &t.Amount, &t.BalanceAfter, &t.CounterpartyID, &t.ReversalOf, &t.CreatedAt); err != nil {
+			return nil, err
+		}
+		res = append(res, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(res) == 0 {
+		var exists bool
+		err := r.db.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM wallets WHERE id = $1)`, f.WalletID).Scan(&exists)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			return nil, wallet.ErrWalletNotFound
+		}
+	}
+
+	return res, nil
+}
@@ -0,0 +1,123 @@
+package wallet
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/totorialman/go-test-ac/internal/domain"
+	"github.com/totorialman/go-test-ac/internal/errors/wallet"
+	"github.com/totorialman/go-test-ac/internal/tracing"
+)
+
+const selectWalletInfo = `
+	SELECT w.id, COALESCE(w.owner_id, ''), w.status, w.metadata, ` + exactBalance + `, w.currency, w.hot, w.created_at, w.updated_at
+	FROM wallets w
+	WHERE w.id = $1`
+
+func (r *Repository) Create(ctx context.Context, c CreateWalletDB) (WalletInfoDB, error) {
+	ctx, span := tracing.StartStatement(ctx, "wallet.Repository.Create")
+	defer span.End()
+
+	var info WalletInfoDB
+	err := r.db.QueryRow(ctx, `
+		INSERT INTO wallets (id, balance, currency, owner_id, metadata, status)
+		VALUES ($1, 0, $2, $3, $4, $5)
+		RETURNING id, owner_id, status, metadata, balance, currency, hot, created_at, updated_at
+	`, uuid.New(), c.Currency, c.OwnerID, c.Metadata, domain.WalletActive).Scan(
+		&info.ID, &info.OwnerID, &info.Status, &info.Metadata,
+		&info.Balance.Balance, &info.Balance.Currency, &info.Hot, &info.CreatedAt, &info.UpdatedAt,
+	)
+	if err != nil {
+		return WalletInfoDB{}, err
+	}
+	info.Balance.Available = info.Balance.Balance
+
+	return info, nil
+}
+
+func (r *Repository) GetWallet(ctx context.Context, id uuid.UUID) (WalletInfoDB, error) {
+	ctx, span := tracing.StartStatement(ctx, "wallet.Repository.GetWallet", tracing.WalletID(id))
+	defer span.End()
+
+	info, err := scanWalletInfo(r.db.QueryRow(ctx, selectWalletInfo, id))
+	if err != nil {
+		return WalletInfoDB{}, err
+	}
+
+	held, err := heldAmount(ctx, r.db, id)
+	if err != nil {
+		return WalletInfoDB{}, err
+	}
+	info.Balance.Available = info.Balance.Balance - held
+
+	return info, nil
+}
+
+// UpdateStatus moves the wallet to status. Closing requires a zero balance
+// and no active holds, checked under the wallet row lock.
+func (r *Repository) UpdateStatus(ctx context.Context, id uuid.UUID, status string) (WalletInfoDB, error) {
+	ctx, span := tracing.StartStatement(ctx, "wallet.Repository.UpdateStatus", tracing.WalletID(id))
+	defer span.End()
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return WalletInfoDB{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	info, err := scanWalletInfo(tx.QueryRow(ctx, selectWalletInfo+` FOR UPDATE`, id))
+	if err != nil {
+		return WalletInfoDB{}, err
+	}
+
+	info.Balance.Balance, err = r.lockedBalance(ctx, tx, id, info.Balance.Balance, info.Hot)
+	if err != nil {
+		return WalletInfoDB{}, err
+	}
+
+	if !domain.CanTransitionWallet(info.Status, status) {
+		return WalletInfoDB{}, wallet.ErrInvalidStatusTransition
+	}
+
+	held, err := heldAmount(ctx, tx, id)
+	if err != nil {
+		return WalletInfoDB{}, err
+	}
+	if status == domain.WalletClosed && (info.Balance.Balance != 0 || held != 0) {
+		return WalletInfoDB{}, wallet.ErrWalletNotEmpty
+	}
+
+	err = tx.QueryRow(ctx, `
+		UPDATE wallets SET status = $2, updated_at = now()
+		WHERE id = $1
+		RETURNING status, updated_at
+	`, id, status).Scan(&info.Status, &info.UpdatedAt)
+	if err != nil {
+		return WalletInfoDB{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return WalletInfoDB{}, err
+	}
+
+	info.Balance.Available = info.Balance.Balance - held
+	return info, nil
+}
+
+func scanWalletInfo(row pgx.Row) (WalletInfoDB, error) {
+	var info WalletInfoDB
+	err := row.Scan(
+		&info.ID, &info.OwnerID, &info.Status, &info.Metadata,
+		&info.Balance.Balance, &info.Balance.Currency, &info.Hot, &info.CreatedAt, &info.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return WalletInfoDB{}, wallet.ErrWalletNotFound
+		}
+		return WalletInfoDB{}, err
+	}
+	return info, nil
+}
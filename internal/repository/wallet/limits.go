@@ -0,0 +1,111 @@
+package wallet
+
+import (
+	"context"
+	"errors"
+	"slices"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/totorialman/go-test-ac/internal/domain"
+	"github.com/totorialman/go-test-ac/internal/errors/wallet"
+	"github.com/totorialman/go-test-ac/internal/tracing"
+)
+
+// GetLimits returns the limits in force for the wallet: its overrides on top
+// of its tier.
+func (r *Repository) GetLimits(ctx context.Context, id uuid.UUID) (LimitsDB, error) {
+	ctx, span := tracing.StartStatement(ctx, "wallet.Repository.GetLimits", tracing.WalletID(id))
+	defer span.End()
+
+	return loadLimits(ctx, r.db, id)
+}
+
+// SetLimits replaces the per-wallet overrides. Nil fields fall back to the
+// wallet's tier.
+func (r *Repository) SetLimits(ctx context.Context, id uuid.UUID, l LimitsDB) (LimitsDB, error) {
+	ctx, span := tracing.StartStatement(ctx, "wallet.Repository.SetLimits", tracing.WalletID(id))
+	defer span.End()
+
+	tag, err := r.db.Exec(ctx, `
+		INSERT INTO wallet_limit_overrides (wallet_id, max_withdraw_amount, max_daily_withdraw, max_balance, min_balance)
+		SELECT id, $2, $3, $4, $5 FROM wallets WHERE id = $1
+		ON CONFLICT (wallet_id) DO UPDATE SET
+			max_withdraw_amount = EXCLUDED.max_withdraw_amount,
+			max_daily_withdraw = EXCLUDED.max_daily_withdraw,
+			max_balance = EXCLUDED.max_balance,
+			min_balance = EXCLUDED.min_balance,
+			updated_at = now()
+	`, id, l.MaxWithdrawAmount, l.MaxDailyWithdraw, l.MaxBalance, l.MinBalance)
+	if err != nil {
+		return LimitsDB{}, err
+	}
+	if tag.RowsAffected() == 0 {
+		return LimitsDB{}, wallet.ErrWalletNotFound
+	}
+
+	return loadLimits(ctx, r.db, id)
+}
+
+func loadLimits(ctx context.Context, q querier, id uuid.UUID) (LimitsDB, error) {
+	var l LimitsDB
+	err := q.QueryRow(ctx, `
+		SELECT
+			COALESCE(o.max_withdraw_amount, t.max_withdraw_amount),
+			COALESCE(o.max_daily_withdraw, t.max_daily_withdraw),
+			COALESCE(o.max_balance, t.max_balance),
+			COALESCE(o.min_balance, t.min_balance)
+		FROM wallets w
+		JOIN wallet_limit_tiers t ON t.name = w.limit_tier
+		LEFT JOIN wallet_limit_overrides o ON o.wallet_id = w.id
+		WHERE w.id = $1
+	`, id).Scan(&l.MaxWithdrawAmount, &l.MaxDailyWithdraw, &l.MaxBalance, &l.MinBalance)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return LimitsDB{}, wallet.ErrWalletNotFound
+		}
+		return LimitsDB{}, err
+	}
+
+	return l, nil
+}
+
+// dailyDebits are the ledger entries that count towards the daily
+// withdrawal total. Taking back an erroneous deposit is a correction, not
+// spending, so DEPOSIT_REVERSAL is left out.
+var dailyDebits = []string{domain.Withdraw, domain.TransferOut, domain.Capture}
+
+// checkLimits runs check against the locked wallet id before opType moves
+// amount. It must be called after the wallet row is locked so that
+// concurrent debits cannot both fit under the daily total.
+func checkLimits(ctx context.Context, tx pgx.Tx, check LimitCheck, id uuid.UUID, opType string, amount int64, balance BalanceDB) error {
+	if check == nil {
+		return nil
+	}
+
+	limits, err := loadLimits(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+
+	state := LimitStateDB{Limits: limits, Balance: balance, OperationType: opType, Amount: amount}
+	if slices.Contains(dailyDebits, opType) && limits.MaxDailyWithdraw != nil {
+		// Days are counted in UTC. A refunded withdrawal from the same day
+		// no longer counts.
+		err = tx.QueryRow(ctx, `
+			SELECT (
+				COALESCE(SUM(t.amount) FILTER (WHERE t.operation_type = ANY($2)), 0) -
+				COALESCE(SUM(t.amount) FILTER (WHERE t.operation_type = $3 AND o.created_at >= date_trunc('day', now(), 'UTC')), 0)
+			)::BIGINT
+			FROM wallet_transactions t
+			LEFT JOIN wallet_transactions o ON o.id = t.reversal_of
+			WHERE t.wallet_id = $1 AND t.created_at >= date_trunc('day', now(), 'UTC')
+		`, id, dailyDebits, domain.WithdrawReversal).Scan(&state.WithdrawnToday)
+		if err != nil {
+			return err
+		}
+	}
+
+	return check(state)
+}
@@ -0,0 +1,69 @@
+package wallet_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/totorialman/go-test-ac/internal/domain"
+	wErr "github.com/totorialman/go-test-ac/internal/errors/wallet"
+	"github.com/totorialman/go-test-ac/internal/logging"
+	"github.com/totorialman/go-test-ac/internal/repository/wallet"
+)
+
+func TestLimits_NetDailyDebits(t *testing.T) {
+	pool := testPool(t)
+	repo := wallet.NewRepository(pool, logging.Discard())
+	walletID := newWallet(t, repo)
+	fund(t, repo, walletID, 500)
+	ctx := context.Background()
+
+	maxDaily := int64(100)
+	_, err := repo.SetLimits(ctx, walletID, wallet.LimitsDB{MaxDailyWithdraw: &maxDaily})
+	require.NoError(t, err)
+
+	// dailyCap is the MaxDailyWithdraw part of the usecase limit check; it
+	// records the daily total it was given.
+	var withdrawnToday int64
+	dailyCap := func(s wallet.LimitStateDB) error {
+		withdrawnToday = s.WithdrawnToday
+		if s.WithdrawnToday+s.Amount > *s.Limits.MaxDailyWithdraw {
+			return wErr.ErrLimitExceeded
+		}
+		return nil
+	}
+
+	_, err = repo.Withdraw(ctx, wallet.WalletDB{ID: walletID, Amount: 60, CheckLimits: dailyCap})
+	require.NoError(t, err)
+	_, err = repo.Withdraw(ctx, wallet.WalletDB{ID: walletID, Amount: 50, CheckLimits: dailyCap})
+	assert.ErrorIs(t, err, wErr.ErrLimitExceeded)
+	assert.Equal(t, int64(60), withdrawnToday)
+
+	// Refunding part of today's withdrawal frees that much of the limit.
+	withdrawal := lastEntry(t, repo, walletID, domain.Withdraw)
+	_, _, err = repo.Reverse(ctx, withdrawal.ID, reverseUpTo(30))
+	require.NoError(t, err)
+	_, err = repo.Withdraw(ctx, wallet.WalletDB{ID: walletID, Amount: 50, CheckLimits: dailyCap})
+	require.NoError(t, err)
+	assert.Equal(t, int64(30), withdrawnToday)
+
+	// Taking back a deposit is not spending and does not count.
+	deposit := lastEntry(t, repo, walletID, domain.Deposit)
+	_, _, err = repo.Reverse(ctx, deposit.ID, reverseUpTo(100))
+	require.NoError(t, err)
+
+	// A hold is checked as the withdrawal it becomes, and counts once
+	// captured.
+	h, _, err := repo.Authorize(ctx, wallet.AuthorizeDB{WalletID: walletID, Amount: 15, TTL: time.Hour, CheckLimits: dailyCap})
+	require.NoError(t, err)
+	assert.Equal(t, int64(80), withdrawnToday)
+	_, _, err = repo.Capture(ctx, wallet.CaptureDB{HoldID: h.ID, CheckLimits: dailyCap})
+	require.NoError(t, err)
+
+	_, _, err = repo.Authorize(ctx, wallet.AuthorizeDB{WalletID: walletID, Amount: 10, TTL: time.Hour, CheckLimits: dailyCap})
+	assert.ErrorIs(t, err, wErr.ErrLimitExceeded)
+	assert.Equal(t, int64(95), withdrawnToday)
+}
@@ -1,8 +1,165 @@
 package wallet
 
-import "github.com/google/uuid"
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
 
 type WalletDB struct {
 	ID     uuid.UUID
 	Amount int64
+	// Currency is optional; when set, the operation is rejected unless the
+	// wallet is denominated in it.
+	Currency    string
+	Idempotency IdempotencyDB
+	// CreateIfMissing lets a DEPOSIT open a wallet that does not exist yet.
+	CreateIfMissing bool
+	// CheckLimits, when set, is called with the locked wallet state before
+	// the balance changes.
+	CheckLimits LimitCheck
+}
+
+// OperationDB is one DEPOSIT or WITHDRAW of a batch.
+type OperationDB struct {
+	OperationType string
+	Wallet        WalletDB
+}
+
+// BalanceDB carries the ledger balance and what is left of it after active
+// holds.
+type BalanceDB struct {
+	Balance   int64
+	Available int64
+	Currency  string
+}
+
+// IdempotencyDB is empty when the client did not send an Idempotency-Key.
+type IdempotencyDB struct {
+	Key         string
+	RequestHash string
+	TTL         time.Duration
+}
+
+type TransactionDB struct {
+	ID             uuid.UUID
+	WalletID       uuid.UUID
+	OperationType  string
+	Amount         int64
+	BalanceAfter   int64
+	CounterpartyID uuid.NullUUID
+	ReversalOf     uuid.NullUUID
+	CreatedAt      time.Time
+}
+
+type TransferDB struct {
+	FromID   uuid.UUID
+	ToID     uuid.UUID
+	Amount   int64
+	Currency string
+	// CheckLimits, when set, is called for the source wallet as a
+	// TRANSFER_OUT and for the destination as a TRANSFER_IN.
+	CheckLimits LimitCheck
+}
+
+type TransferResultDB struct {
+	From BalanceDB
+	To   BalanceDB
+}
+
+type HistoryFilterDB struct {
+	WalletID      uuid.UUID
+	OperationType string
+	From          time.Time
+	To            time.Time
+	// Keyset cursor: only entries strictly older than (BeforeCreatedAt, BeforeID)
+	// are returned. Zero values mean "start from the newest entry".
+	BeforeCreatedAt time.Time
+	BeforeID        uuid.UUID
+	Limit           int
+}
+
+type AuthorizeDB struct {
+	WalletID    uuid.UUID
+	Amount      int64
+	Currency    string
+	TTL         time.Duration
+	CheckLimits LimitCheck
+}
+
+type CaptureDB struct {
+	HoldID uuid.UUID
+	// Amount is the part of the hold to settle; the rest is released.
+	// Zero settles the whole hold.
+	Amount int64
+	// CheckLimits, when set, is called with the locked wallet state before
+	// the hold is settled.
+	CheckLimits LimitCheck
+}
+
+type HoldDB struct {
+	ID             uuid.UUID
+	WalletID       uuid.UUID
+	Amount         int64
+	CapturedAmount int64
+	Status         string
+	ExpiresAt      time.Time
+	CreatedAt      time.Time
+}
+
+type CreateWalletDB struct {
+	OwnerID  string
+	Currency string
+	Metadata []byte
+}
+
+type WalletInfoDB struct {
+	ID       uuid.UUID
+	OwnerID  string
+	Status   string
+	Metadata []byte
+	Balance  BalanceDB
+	// Hot wallets take deposits into sub-balance buckets, see SetHot.
+	Hot       bool
+	CreatedAt time.Time
+	UpdatedAt time.Time
 }
+
+// ReversalStateDB is what a reversal is decided on, read under the wallet
+// row lock.
+type ReversalStateDB struct {
+	Original TransactionDB
+	Reversed int64
+	Balance  BalanceDB
+}
+
+// ReversalCheck validates a reversal against the locked state and returns
+// the amount to reverse.
+type ReversalCheck func(s ReversalStateDB) (int64, error)
+
+// LimitsDB holds the limits in force for a wallet. A nil field means the
+// limit is not set.
+type LimitsDB struct {
+	MaxWithdrawAmount *int64
+	MaxDailyWithdraw  *int64
+	MaxBalance        *int64
+	MinBalance        *int64
+}
+
+// LimitStateDB is what limits are checked against, read under the wallet row
+// lock before the balance changes.
+type LimitStateDB struct {
+	Limits  LimitsDB
+	Balance BalanceDB
+	// OperationType and Amount describe the change about to be made to the
+	// wallet.
+	OperationType string
+	Amount        int64
+	// WithdrawnToday is what left the wallet today through withdrawals,
+	// transfers and captures, net of refunded withdrawals. It is only
+	// loaded for debits when a daily limit is set.
+	WithdrawnToday int64
+}
+
+// LimitCheck rejects an operation that would break a limit.
+type LimitCheck func(s LimitStateDB) error
@@ -0,0 +1,25 @@
+package wallet
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// insertOutboxEvent queues an event for the outbox relay. Writing it in the
+// caller's transaction means the event exists if and only if the change it
+// describes was committed.
+func insertOutboxEvent(ctx context.Context, tx pgx.Tx, eventType string, aggregateID uuid.UUID, payload any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO outbox_events (event_type, aggregate_id, payload)
+		VALUES ($1, $2, $3)
+	`, eventType, aggregateID, body)
+	return err
+}
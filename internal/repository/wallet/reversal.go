@@ -0,0 +1,113 @@
+package wallet
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/totorialman/go-test-ac/internal/domain"
+	"github.com/totorialman/go-test-ac/internal/errors/wallet"
+	"github.com/totorialman/go-test-ac/internal/tracing"
+)
+
+// Reverse writes a compensating entry for the transaction txID. The business
+// rules live in check, which sees the original entry, how much of it was
+// already reversed and the wallet balance, all under the wallet row lock, so
+// two concurrent reversals cannot both pass.
+func (r *Repository) Reverse(ctx context.Context, txID uuid.UUID, check ReversalCheck) (TransactionDB, BalanceDB, error) {
+	ctx, span := tracing.StartStatement(ctx, "wallet.Repository.Reverse")
+	defer span.End()
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return TransactionDB{}, BalanceDB{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	var state ReversalStateDB
+	orig := &state.Original
+	err = tx.QueryRow(ctx, `
+		SELECT id, wallet_id, operation_type, amount, balance_after, counterparty_wallet_id, reversal_of, created_at
+		FROM wallet_transactions
+		WHERE id = $1
+	`, txID).Scan(&orig.ID, &orig.WalletID, &orig.OperationType, &orig.Amount, &orig.BalanceAfter, &orig.CounterpartyID, &orig.ReversalOf, &orig.CreatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return TransactionDB{}, BalanceDB{}, wallet.ErrTransactionNotFound
+		}
+		return TransactionDB{}, BalanceDB{}, err
+	}
+
+	// A reversal moves money like any other operation, so a frozen or
+	// closed wallet refuses it as well.
+	state.Balance, err = r.lockWallet(ctx, tx, orig.WalletID)
+	if err != nil {
+		return TransactionDB{}, BalanceDB{}, err
+	}
+
+	held, err := heldAmount(ctx, tx, orig.WalletID)
+	if err != nil {
+		return TransactionDB{}, BalanceDB{}, err
+	}
+	state.Balance.Available = state.Balance.Balance - held
+
+	err = tx.QueryRow(ctx, `
+		SELECT COALESCE(SUM(amount), 0)::BIGINT FROM wallet_transactions WHERE reversal_of = $1
+	`, txID).Scan(&state.Reversed)
+	if err != nil {
+		return TransactionDB{}, BalanceDB{}, err
+	}
+
+	amount, err := check(state)
+	if err != nil {
+		return TransactionDB{}, BalanceDB{}, err
+	}
+
+	entry := TransactionDB{
+		WalletID:   orig.WalletID,
+		Amount:     amount,
+		ReversalOf: uuid.NullUUID{UUID: orig.ID, Valid: true},
+	}
+	newBalance := state.Balance
+	switch orig.OperationType {
+	case domain.Deposit:
+		entry.OperationType = domain.DepositReversal
+		newBalance.Balance -= amount
+		newBalance.Available -= amount
+	case domain.Withdraw:
+		entry.OperationType = domain.WithdrawReversal
+		newBalance.Balance += amount
+		newBalance.Available += amount
+	default:
+		return TransactionDB{}, BalanceDB{}, wallet.ErrNotReversible
+	}
+	entry.BalanceAfter = newBalance.Balance
+
+	delta := newBalance.Balance - state.Balance.Balance
+	if _, err := tx.Exec(ctx, `UPDATE wallets SET balance = balance + $2 WHERE id = $1`, orig.WalletID, delta); err != nil {
+		return TransactionDB{}, BalanceDB{}, err
+	}
+
+	entry, err = insertTransaction(ctx, tx, entry, newBalance.Currency)
+	if err != nil {
+		return TransactionDB{}, BalanceDB{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return TransactionDB{}, BalanceDB{}, err
+	}
+
+	return entry, newBalance, nil
+}
+
+// TransactionWalletID returns the wallet the ledger entry txID belongs to.
+func (r *Repository) TransactionWalletID(ctx context.Context, txID uuid.UUID) (uuid.UUID, error) {
+	var walletID uuid.UUID
+	err := r.db.QueryRow(ctx, `SELECT wallet_id FROM wallet_transactions WHERE id = $1`, txID).Scan(&walletID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return uuid.Nil, wallet.ErrTransactionNotFound
+	}
+	return walletID, err
+}
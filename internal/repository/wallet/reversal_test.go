@@ -0,0 +1,79 @@
+package wallet_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/totorialman/go-test-ac/internal/domain"
+	wErr "github.com/totorialman/go-test-ac/internal/errors/wallet"
+	"github.com/totorialman/go-test-ac/internal/logging"
+	"github.com/totorialman/go-test-ac/internal/repository/wallet"
+)
+
+// lastEntry returns the newest ledger entry of the wallet with the given
+// operation type.
+func lastEntry(tb testing.TB, repo *wallet.Repository, walletID uuid.UUID, opType string) wallet.TransactionDB {
+	history, err := repo.History(context.Background(), wallet.HistoryFilterDB{WalletID: walletID, OperationType: opType, Limit: 1})
+	require.NoError(tb, err)
+	require.Len(tb, history, 1)
+	return history[0]
+}
+
+// reverseUpTo reverses amount, or what is left of the original entry if
+// that is less.
+func reverseUpTo(amount int64) wallet.ReversalCheck {
+	return func(s wallet.ReversalStateDB) (int64, error) {
+		return min(amount, s.Original.Amount-s.Reversed), nil
+	}
+}
+
+func TestReverse(t *testing.T) {
+	pool := testPool(t)
+	repo := wallet.NewRepository(pool, logging.Discard())
+	walletID := newWallet(t, repo)
+	fund(t, repo, walletID, 100)
+	ctx := context.Background()
+
+	_, err := repo.Withdraw(ctx, wallet.WalletDB{ID: walletID, Amount: 40})
+	require.NoError(t, err)
+	withdrawal := lastEntry(t, repo, walletID, domain.Withdraw)
+
+	entry, b, err := repo.Reverse(ctx, withdrawal.ID, reverseUpTo(10))
+	require.NoError(t, err)
+	assert.Equal(t, domain.WithdrawReversal, entry.OperationType)
+	assert.Equal(t, int64(10), entry.Amount)
+	assert.Equal(t, int64(70), entry.BalanceAfter)
+	assert.Equal(t, uuid.NullUUID{UUID: withdrawal.ID, Valid: true}, entry.ReversalOf)
+	assert.Equal(t, wallet.BalanceDB{Balance: 70, Available: 70, Currency: domain.DefaultCurrency}, b)
+
+	// The check sees what earlier partial reversals took back.
+	var seen wallet.ReversalStateDB
+	_, b, err = repo.Reverse(ctx, withdrawal.ID, func(s wallet.ReversalStateDB) (int64, error) {
+		seen = s
+		return reverseUpTo(100)(s)
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(10), seen.Reversed)
+	assert.Equal(t, withdrawal.ID, seen.Original.ID)
+	assert.Equal(t, int64(100), b.Balance)
+
+	deposit := lastEntry(t, repo, walletID, domain.Deposit)
+	_, err = repo.UpdateStatus(ctx, walletID, domain.WalletFrozen)
+	require.NoError(t, err)
+	_, _, err = repo.Reverse(ctx, deposit.ID, func(wallet.ReversalStateDB) (int64, error) {
+		t.Error("the check must not run for a frozen wallet")
+		return 0, nil
+	})
+	assert.ErrorIs(t, err, wErr.ErrWalletFrozen)
+
+	_, _, err = repo.Reverse(ctx, uuid.New(), reverseUpTo(1))
+	assert.ErrorIs(t, err, wErr.ErrTransactionNotFound)
+
+	got, err := repo.GetBalance(ctx, walletID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(100), got.Balance)
+}
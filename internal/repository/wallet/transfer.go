@@ -0,0 +1,141 @@
+package wallet
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/totorialman/go-test-ac/internal/domain"
+	"github.com/totorialman/go-test-ac/internal/errors/wallet"
+	"github.com/totorialman/go-test-ac/internal/tracing"
+)
+
+func (r *Repository) Transfer(ctx context.Context, t TransferDB) (TransferResultDB, error) {
+	ctx, span := tracing.StartStatement(ctx, "wallet.Repository.Transfer", tracing.WalletID(t.FromID))
+	defer span.End()
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return TransferResultDB{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	// Both rows are locked in id order, so two opposite transfers between
+	// the same pair of wallets cannot deadlock.
+	rows, err := tx.Query(ctx, `
+		SELECT id, balance, currency, status, hot FROM wallets
+		WHERE id = ANY($1)
+		ORDER BY id
+		FOR UPDATE
+	`, []uuid.UUID{t.FromID, t.ToID})
+	if err != nil {
+		return TransferResultDB{}, err
+	}
+
+	balances := make(map[uuid.UUID]BalanceDB, 2)
+	hot := make(map[uuid.UUID]bool, 2)
+	for rows.Next() {
+		var id uuid.UUID
+		var b BalanceDB
+		var status string
+		var isHot bool
+		if err := rows.Scan(&id, &b.Balance, &b.Currency, &status, &isHot); err != nil {
+			rows.Close()
+			return TransferResultDB{}, err
+		}
+		if err := checkWalletActive(status); err != nil {
+			rows.Close()
+			return TransferResultDB{}, err
+		}
+		balances[id] = b
+		hot[id] = isHot
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return TransferResultDB{}, err
+	}
+
+	for id, b := range balances {
+		b.Balance, err = r.lockedBalance(ctx, tx, id, b.Balance, hot[id])
+		if err != nil {
+			return TransferResultDB{}, err
+		}
+		balances[id] = b
+	}
+
+	from, ok := balances[t.FromID]
+	if !ok {
+		return TransferResultDB{}, wallet.ErrWalletNotFound
+	}
+	to, ok := balances[t.ToID]
+	if !ok {
+		return TransferResultDB{}, wallet.ErrWalletNotFound
+	}
+
+	if from.Currency != to.Currency || (t.Currency != "" && t.Currency != from.Currency) {
+		return TransferResultDB{}, wallet.ErrCurrencyMismatch
+	}
+
+	fromHeld, err := heldAmount(ctx, tx, t.FromID)
+	if err != nil {
+		return TransferResultDB{}, err
+	}
+	toHeld, err := heldAmount(ctx, tx, t.ToID)
+	if err != nil {
+		return TransferResultDB{}, err
+	}
+
+	if from.Balance-fromHeld < t.Amount {
+		return TransferResultDB{}, wallet.ErrNotEnoughFunds
+	}
+
+	from.Available = from.Balance - fromHeld
+	if err := checkLimits(ctx, tx, t.CheckLimits, t.FromID, domain.TransferOut, t.Amount, from); err != nil {
+		return TransferResultDB{}, err
+	}
+	to.Available = to.Balance - toHeld
+	if err := checkLimits(ctx, tx, t.CheckLimits, t.ToID, domain.TransferIn, t.Amount, to); err != nil {
+		return TransferResultDB{}, err
+	}
+
+	from.Balance -= t.Amount
+	from.Available = from.Balance - fromHeld
+	to.Balance += t.Amount
+	to.Available = to.Balance - toHeld
+	res := TransferResultDB{From: from, To: to}
+
+	if _, err := tx.Exec(ctx, `UPDATE wallets SET balance = balance - $2 WHERE id = $1`, t.FromID, t.Amount); err != nil {
+		return TransferResultDB{}, err
+	}
+	if _, err := tx.Exec(ctx, `UPDATE wallets SET balance = balance + $2 WHERE id = $1`, t.ToID, t.Amount); err != nil {
+		return TransferResultDB{}, err
+	}
+
+	_, err = insertTransaction(ctx, tx, TransactionDB{
+		WalletID:       t.FromID,
+		OperationType:  domain.TransferOut,
+		Amount:         t.Amount,
+		BalanceAfter:   from.Balance,
+		CounterpartyID: uuid.NullUUID{UUID: t.ToID, Valid: true},
+	}, from.Currency)
+	if err != nil {
+		return TransferResultDB{}, err
+	}
+
+	_, err = insertTransaction(ctx, tx, TransactionDB{
+		WalletID:       t.ToID,
+		OperationType:  domain.TransferIn,
+		Amount:         t.Amount,
+		BalanceAfter:   to.Balance,
+		CounterpartyID: uuid.NullUUID{UUID: t.FromID, Valid: true},
+	}, to.Currency)
+	if err != nil {
+		return TransferResultDB{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return TransferResultDB{}, err
+	}
+
+	return res, nil
+}
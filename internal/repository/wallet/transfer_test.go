@@ -0,0 +1,66 @@
+package wallet_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/totorialman/go-test-ac/internal/domain"
+	wErr "github.com/totorialman/go-test-ac/internal/errors/wallet"
+	"github.com/totorialman/go-test-ac/internal/logging"
+	"github.com/totorialman/go-test-ac/internal/repository/wallet"
+)
+
+func TestTransfer(t *testing.T) {
+	pool := testPool(t)
+	repo := wallet.NewRepository(pool, logging.Discard())
+	from, to := newWallet(t, repo), newWallet(t, repo)
+	fund(t, repo, from, 100)
+	ctx := context.Background()
+
+	res, err := repo.Transfer(ctx, wallet.TransferDB{FromID: from, ToID: to, Amount: 70})
+	require.NoError(t, err)
+	assert.Equal(t, wallet.BalanceDB{Balance: 30, Available: 30, Currency: domain.DefaultCurrency}, res.From)
+	assert.Equal(t, wallet.BalanceDB{Balance: 70, Available: 70, Currency: domain.DefaultCurrency}, res.To)
+
+	// Both sides are in the ledger, each naming the other wallet.
+	for _, side := range []struct {
+		wallet, counterparty uuid.UUID
+		opType               string
+		balanceAfter         int64
+	}{
+		{from, to, domain.TransferOut, 30},
+		{to, from, domain.TransferIn, 70},
+	} {
+		history, err := repo.History(ctx, wallet.HistoryFilterDB{WalletID: side.wallet, OperationType: side.opType, Limit: 10})
+		require.NoError(t, err)
+		require.Len(t, history, 1)
+		assert.Equal(t, int64(70), history[0].Amount)
+		assert.Equal(t, side.balanceAfter, history[0].BalanceAfter)
+		assert.Equal(t, uuid.NullUUID{UUID: side.counterparty, Valid: true}, history[0].CounterpartyID)
+	}
+
+	// Held funds cannot be transferred.
+	_, _, err = repo.Authorize(ctx, wallet.AuthorizeDB{WalletID: from, Amount: 20, TTL: time.Hour})
+	require.NoError(t, err)
+	_, err = repo.Transfer(ctx, wallet.TransferDB{FromID: from, ToID: to, Amount: 20})
+	assert.ErrorIs(t, err, wErr.ErrNotEnoughFunds)
+
+	_, err = repo.Transfer(ctx, wallet.TransferDB{FromID: from, ToID: uuid.New(), Amount: 1})
+	assert.ErrorIs(t, err, wErr.ErrWalletNotFound)
+
+	_, err = repo.UpdateStatus(ctx, to, domain.WalletFrozen)
+	require.NoError(t, err)
+	_, err = repo.Transfer(ctx, wallet.TransferDB{FromID: from, ToID: to, Amount: 1})
+	assert.ErrorIs(t, err, wErr.ErrWalletFrozen, "a frozen wallet takes no incoming transfers")
+
+	for id, want := range map[uuid.UUID]int64{from: 30, to: 70} {
+		got, err := repo.GetBalance(ctx, id)
+		require.NoError(t, err)
+		assert.Equal(t, want, got.Balance, "failed transfers leave the balances alone")
+	}
+}
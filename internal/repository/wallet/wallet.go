@@ -3,77 +3,295 @@ package wallet
 import (
 	"context"
 	"errors"
+	"log/slog"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/totorialman/go-test-ac/internal/domain"
 	"github.com/totorialman/go-test-ac/internal/errors/wallet"
+	"github.com/totorialman/go-test-ac/internal/tracing"
 )
 
 type Repository struct {
-	db *pgxpool.Pool
+	db         *pgxpool.Pool
+	logger     *slog.Logger
+	hotBuckets int
+	groups     *groupCommitter
 }
 
-func NewRepository(db *pgxpool.Pool) *Repository {
-	return &Repository{db: db}
+type Option func(*Repository)
+
+// WithHotBuckets spreads deposits to wallets flagged hot over n sub-balance
+// buckets instead of the wallets row. Zero, the default, disables the mode.
+func WithHotBuckets(n int) Option {
+	return func(r *Repository) {
+		r.hotBuckets = n
+	}
 }
 
-func (r *Repository) GetBalance(ctx context.Context, id uuid.UUID) (int64, error) {
-	var balance int64
-	err := r.db.QueryRow(ctx, `SELECT balance FROM wallets WHERE id = $1`, id).Scan(&balance)
+func NewRepository(db *pgxpool.Pool, logger *slog.Logger, opts ...Option) *Repository {
+	r := &Repository{db: db, logger: logger}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+func (r *Repository) GetBalance(ctx context.Context, id uuid.UUID) (BalanceDB, error) {
+	ctx, span := tracing.StartStatement(ctx, "wallet.Repository.GetBalance", tracing.WalletID(id))
+	defer span.End()
+
+	var b BalanceDB
+	err := r.db.QueryRow(ctx, `SELECT `+exactBalance+`, w.currency FROM wallets w WHERE w.id = $1`, id).Scan(&b.Balance, &b.Currency)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			return 0, wallet.ErrWalletNotFound
+			return BalanceDB{}, wallet.ErrWalletNotFound
 		}
-		return 0, err
+		return BalanceDB{}, err
+	}
+
+	held, err := heldAmount(ctx, r.db, id)
+	if err != nil {
+		return BalanceDB{}, err
+	}
+	b.Available = b.Balance - held
+
+	return b, nil
+}
+
+func (r *Repository) Deposit(ctx context.Context, w WalletDB) (BalanceDB, error) {
+	ctx, span := tracing.StartStatement(ctx, "wallet.Repository.Deposit", tracing.WalletID(w.ID))
+	defer span.End()
+
+	if r.groups != nil {
+		return r.groups.Do(ctx, w.ID, OperationDB{OperationType: domain.Deposit, Wallet: w})
+	}
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return BalanceDB{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	newBalance, err := r.deposit(ctx, tx, w)
+	if err != nil {
+		return BalanceDB{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return BalanceDB{}, err
 	}
-	return balance, nil
+
+	return newBalance, nil
 }
 
-func (r *Repository) Deposit(ctx context.Context, w WalletDB) (int64, error) {
-	var newBalance int64
-	err := r.db.QueryRow(ctx, `
-		INSERT INTO wallets (id, balance)
-		VALUES ($1, $2)
-		ON CONFLICT (id) DO UPDATE
-		SET balance = wallets.balance + EXCLUDED.balance
-		RETURNING balance
-	`, w.ID, w.Amount).Scan(&newBalance)
+// deposit credits the wallet within tx.
+func (r *Repository) deposit(ctx context.Context, tx pgx.Tx, w WalletDB) (BalanceDB, error) {
+	balance, replayed, err := claimIdempotencyKey(ctx, tx, w)
+	if err != nil {
+		return BalanceDB{}, err
+	}
+	if replayed {
+		r.logger.DebugContext(ctx, "idempotent replay", "wallet_id", w.ID, "idempotency_key", w.Idempotency.Key)
+		return balance, nil
+	}
+
+	if w.CreateIfMissing {
+		_, err = tx.Exec(ctx, `
+			INSERT INTO wallets (id, balance, currency)
+			VALUES ($1, 0, COALESCE($2, $3))
+			ON CONFLICT (id) DO NOTHING
+		`, w.ID, nullableCurrency(w.Currency), domain.DefaultCurrency)
+		if err != nil {
+			return BalanceDB{}, err
+		}
+	}
+
+	if r.hotBuckets > 0 {
+		buckets, err := useBuckets(ctx, tx, w)
+		if err != nil {
+			return BalanceDB{}, err
+		}
+		if buckets {
+			return r.depositHot(ctx, tx, w)
+		}
+	}
+
+	current, err := r.lockWallet(ctx, tx, w.ID)
+	if err != nil {
+		return BalanceDB{}, err
+	}
+	if w.Currency != "" && w.Currency != current.Currency {
+		return BalanceDB{}, wallet.ErrCurrencyMismatch
+	}
+
+	held, err := heldAmount(ctx, tx, w.ID)
+	if err != nil {
+		return BalanceDB{}, err
+	}
+	current.Available = current.Balance - held
+
+	if err := checkLimits(ctx, tx, w.CheckLimits, w.ID, domain.Deposit, w.Amount, current); err != nil {
+		return BalanceDB{}, err
+	}
+
+	if _, err := tx.Exec(ctx, `UPDATE wallets SET balance = balance + $2 WHERE id = $1`, w.ID, w.Amount); err != nil {
+		return BalanceDB{}, err
+	}
+	newBalance := BalanceDB{
+		Balance:   current.Balance + w.Amount,
+		Available: current.Available + w.Amount,
+		Currency:  current.Currency,
+	}
+
+	_, err = insertTransaction(ctx, tx, TransactionDB{
+		WalletID:      w.ID,
+		OperationType: domain.Deposit,
+		Amount:        w.Amount,
+		BalanceAfter:  newBalance.Balance,
+	}, newBalance.Currency)
 	if err != nil {
-		return 0, err
+		return BalanceDB{}, err
+	}
+
+	if err := storeIdempotencyResult(ctx, tx, w, newBalance.Balance); err != nil {
+		return BalanceDB{}, err
 	}
+
 	return newBalance, nil
 }
 
-func (r *Repository) Withdraw(ctx context.Context, w WalletDB) (int64, error) {
+func (r *Repository) Withdraw(ctx context.Context, w WalletDB) (BalanceDB, error) {
+	ctx, span := tracing.StartStatement(ctx, "wallet.Repository.Withdraw", tracing.WalletID(w.ID))
+	defer span.End()
+
+	if r.groups != nil {
+		return r.groups.Do(ctx, w.ID, OperationDB{OperationType: domain.Withdraw, Wallet: w})
+	}
+
 	tx, err := r.db.Begin(ctx)
 	if err != nil {
-		return 0, err
+		return BalanceDB{}, err
 	}
 	defer tx.Rollback(ctx)
 
-	var currentBalance int64
-	err = tx.QueryRow(ctx, `SELECT balance FROM wallets WHERE id = $1 FOR UPDATE`, w.ID).Scan(&currentBalance)
+	newBalance, err := r.withdraw(ctx, tx, w)
+	if err != nil {
+		return BalanceDB{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return BalanceDB{}, err
+	}
+
+	return newBalance, nil
+}
+
+// withdraw debits the wallet within tx.
+func (r *Repository) withdraw(ctx context.Context, tx pgx.Tx, w WalletDB) (BalanceDB, error) {
+	balance, replayed, err := claimIdempotencyKey(ctx, tx, w)
+	if err != nil {
+		return BalanceDB{}, err
+	}
+	if replayed {
+		r.logger.DebugContext(ctx, "idempotent replay", "wallet_id", w.ID, "idempotency_key", w.Idempotency.Key)
+		return balance, nil
+	}
+
+	current, err := r.lockWallet(ctx, tx, w.ID)
+	if err != nil {
+		return BalanceDB{}, err
+	}
+
+	if w.Currency != "" && w.Currency != current.Currency {
+		return BalanceDB{}, wallet.ErrCurrencyMismatch
+	}
+
+	held, err := heldAmount(ctx, tx, w.ID)
+	if err != nil {
+		return BalanceDB{}, err
+	}
+
+	current.Available = current.Balance - held
+
+	// Funds reserved by holds cannot be withdrawn.
+	if current.Available < w.Amount {
+		return BalanceDB{}, wallet.ErrNotEnoughFunds
+	}
+
+	if err := checkLimits(ctx, tx, w.CheckLimits, w.ID, domain.Withdraw, w.Amount, current); err != nil {
+		return BalanceDB{}, err
+	}
+
+	if _, err := tx.Exec(ctx, `UPDATE wallets SET balance = balance - $2 WHERE id = $1`, w.ID, w.Amount); err != nil {
+		return BalanceDB{}, err
+	}
+	newBalance := BalanceDB{
+		Balance:   current.Balance - w.Amount,
+		Available: current.Available - w.Amount,
+		Currency:  current.Currency,
+	}
+
+	_, err = insertTransaction(ctx, tx, TransactionDB{
+		WalletID:      w.ID,
+		OperationType: domain.Withdraw,
+		Amount:        w.Amount,
+		BalanceAfter:  newBalance.Balance,
+	}, newBalance.Currency)
+	if err != nil {
+		return BalanceDB{}, err
+	}
+
+	if err := storeIdempotencyResult(ctx, tx, w, newBalance.Balance); err != nil {
+		return BalanceDB{}, err
+	}
+
+	return newBalance, nil
+}
+
+// lockWallet takes the row lock that serializes every balance change of a
+// wallet and fails unless the wallet is ACTIVE. The balance it returns
+// includes the sub-balance buckets of a hot wallet.
+func (r *Repository) lockWallet(ctx context.Context, tx pgx.Tx, id uuid.UUID) (BalanceDB, error) {
+	var b BalanceDB
+	var status string
+	var hot bool
+	err := tx.QueryRow(ctx, `SELECT balance, currency, status, hot FROM wallets WHERE id = $1 FOR UPDATE`, id).Scan(&b.Balance, &b.Currency, &status, &hot)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			return 0, wallet.ErrWalletNotFound
+			return BalanceDB{}, wallet.ErrWalletNotFound
 		}
-		return 0, err
+		return BalanceDB{}, err
 	}
 
-	if currentBalance < w.Amount {
-		return 0, wallet.ErrNotEnoughFunds
+	if err := checkWalletActive(status); err != nil {
+		return BalanceDB{}, err
 	}
 
-	var newBalance int64
-	err = tx.QueryRow(ctx, `UPDATE wallets SET balance = $2 WHERE id = $1 RETURNING balance`, w.ID, currentBalance-w.Amount).Scan(&newBalance)
+	b.Balance, err = r.lockedBalance(ctx, tx, id, b.Balance, hot)
 	if err != nil {
-		return 0, err
+		return BalanceDB{}, err
 	}
 
-	if err := tx.Commit(ctx); err != nil {
-		return 0, err
+	return b, nil
+}
+
+func checkWalletActive(status string) error {
+	switch status {
+	case domain.WalletFrozen:
+		return wallet.ErrWalletFrozen
+	case domain.WalletClosed:
+		return wallet.ErrWalletClosed
+	default:
+		return nil
 	}
+}
 
-	return newBalance, nil
+func nullableCurrency(currency string) *string {
+	if currency == "" {
+		return nil
+	}
+	return &currency
 }
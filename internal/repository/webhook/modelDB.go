@@ -0,0 +1,47 @@
+package webhook
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type SubscriptionDB struct {
+	ID     uuid.UUID
+	URL    string
+	Secret string
+	// WalletID and EventType narrow the subscription; empty matches all.
+	WalletID  uuid.NullUUID
+	EventType string
+	CreatedAt time.Time
+}
+
+// EnqueueDB is an outbox event to fan out to the matching subscriptions.
+type EnqueueDB struct {
+	EventID   uuid.UUID
+	EventType string
+	WalletID  uuid.UUID
+	Payload   []byte
+}
+
+type DeliveryDB struct {
+	ID             uuid.UUID
+	SubscriptionID uuid.UUID
+	EventID        uuid.UUID
+	EventType      string
+	Payload        []byte
+	Status         string
+	Attempts       int
+	NextAttemptAt  time.Time
+	LastError      string
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+	// URL and Secret are filled in by ClaimDue from the subscription.
+	URL    string
+	Secret string
+}
+
+type DeliveryFilterDB struct {
+	Status string
+	Limit  int
+}
@@ -0,0 +1,259 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/totorialman/go-test-ac/internal/domain"
+	"github.com/totorialman/go-test-ac/internal/errors/wallet"
+	"github.com/totorialman/go-test-ac/internal/errors/webhook"
+)
+
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+func NewRepository(db *pgxpool.Pool) *Repository {
+	return &Repository{db: db}
+}
+
+func (r *Repository) CreateSubscription(ctx context.Context, s SubscriptionDB) (SubscriptionDB, error) {
+	if s.WalletID.Valid {
+		var exists bool
+		err := r.db.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM wallets WHERE id = $1)`, s.WalletID.UUID).Scan(&exists)
+		if err != nil {
+			return SubscriptionDB{}, err
+		}
+		if !exists {
+			return SubscriptionDB{}, wallet.ErrWalletNotFound
+		}
+	}
+
+	err := r.db.QueryRow(ctx, `
+		INSERT INTO webhook_subscriptions (url, secret, wallet_id, event_type)
+		VALUES ($1, $2, $3, NULLIF($4, ''))
+		RETURNING id, created_at
+	`, s.URL, s.Secret, s.WalletID, s.EventType).Scan(&s.ID, &s.CreatedAt)
+	if err != nil {
+		return SubscriptionDB{}, err
+	}
+
+	return s, nil
+}
+
+func (r *Repository) ListSubscriptions(ctx context.Context) ([]SubscriptionDB, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT id, url, secret, wallet_id, COALESCE(event_type, ''), created_at
+		FROM webhook_subscriptions
+		ORDER BY created_at, id
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var res []SubscriptionDB
+	for rows.Next() {
+		var s SubscriptionDB
+		if err := rows.Scan(&s.ID, &s.URL, &s.Secret, &s.WalletID, &s.EventType, &s.CreatedAt); err != nil {
+			return nil, err
+		}
+		res = append(res, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// DeleteSubscription removes the subscription together with its deliveries.
+func (r *Repository) DeleteSubscription(ctx context.Context, id uuid.UUID) error {
+	tag, err := r.db.Exec(ctx, `DELETE FROM webhook_subscriptions WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return webhook.ErrSubscriptionNotFound
+	}
+	return nil
+}
+
+// Enqueue queues a delivery of the event for every matching subscription.
+// Enqueueing the same event twice is a no-op.
+func (r *Repository) Enqueue(ctx context.Context, e EnqueueDB) (int64, error) {
+	tag, err := r.db.Exec(ctx, `
+		INSERT INTO webhook_deliveries (subscription_id, event_id, event_type, payload)
+		SELECT id, $1, $2, $4
+		FROM webhook_subscriptions
+		WHERE (wallet_id IS NULL OR wallet_id = $3)
+			AND (event_type IS NULL OR event_type = $2)
+		ON CONFLICT (subscription_id, event_id) DO NOTHING
+	`, e.EventID, e.EventType, e.WalletID, e.Payload)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+// ClaimDue leases up to limit deliveries whose next attempt is due by moving
+// their next attempt lease into the future, so that concurrent dispatchers
+// do not send the same delivery at the same time.
+func (r *Repository) ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]DeliveryDB, error) {
+	rows, err := r.db.Query(ctx, `
+		WITH claimed AS (
+			UPDATE webhook_deliveries
+			SET next_attempt_at = now() + $2::interval, attempts = attempts + 1, updated_at = now()
+			WHERE id IN (
+				SELECT id FROM webhook_deliveries
+				WHERE status = $3 AND next_attempt_at <= now()
+				ORDER BY next_attempt_at
+				LIMIT $1
+				FOR UPDATE SKIP LOCKED
+			)
+			RETURNING *
+		)
+		SELECT c.id, c.subscription_id, c.event_id, c.event_type, c.payload, c.status, c.attempts,
+			c.next_attempt_at, COALESCE(c.last_error, ''), c.created_at, c.updated_at, s.url, s.secret
+		FROM claimed c
+		JOIN webhook_subscriptions s ON s.id = c.subscription_id
+		ORDER BY c.created_at, c.id
+	`, limit, lease, domain.DeliveryPending)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var res []DeliveryDB
+	for rows.Next() {
+		var d DeliveryDB
+		err := rows.Scan(&d.ID, &d.SubscriptionID, &d.EventID, &d.EventType, &d.Payload, &d.Status, &d.Attempts,
+			&d.NextAttemptAt, &d.LastError, &d.CreatedAt, &d.UpdatedAt, &d.URL, &d.Secret)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// MarkDelivered records a successful attempt. attempt is the attempt count
+// the delivery was claimed with: if another dispatcher has claimed it since,
+// or it was replayed, the row is left alone and ErrLeaseLost is returned.
+// Reschedule and MarkDead are guarded the same way.
+func (r *Repository) MarkDelivered(ctx context.Context, id uuid.UUID, attempt int) error {
+	tag, err := r.db.Exec(ctx, `
+		UPDATE webhook_deliveries SET status = $3, last_error = NULL, updated_at = now()
+		WHERE id = $1 AND attempts = $2 AND status = $4
+	`, id, attempt, domain.DeliveryDelivered, domain.DeliveryPending)
+	return leaseHeld(tag, err)
+}
+
+// Reschedule records a failed attempt and when to try again.
+func (r *Repository) Reschedule(ctx context.Context, id uuid.UUID, attempt int, delay time.Duration, reason string) error {
+	tag, err := r.db.Exec(ctx, `
+		UPDATE webhook_deliveries SET next_attempt_at = now() + $3::interval, last_error = $4, updated_at = now()
+		WHERE id = $1 AND attempts = $2 AND status = $5
+	`, id, attempt, delay, reason, domain.DeliveryPending)
+	return leaseHeld(tag, err)
+}
+
+// MarkDead moves a delivery that ran out of attempts to the dead-letter list.
+func (r *Repository) MarkDead(ctx context.Context, id uuid.UUID, attempt int, reason string) error {
+	tag, err := r.db.Exec(ctx, `
+		UPDATE webhook_deliveries SET status = $3, last_error = $4, updated_at = now()
+		WHERE id = $1 AND attempts = $2 AND status = $5
+	`, id, attempt, domain.DeliveryDead, reason, domain.DeliveryPending)
+	return leaseHeld(tag, err)
+}
+
+func leaseHeld(tag pgconn.CommandTag, err error) error {
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return webhook.ErrLeaseLost
+	}
+	return nil
+}
+
+func (r *Repository) ListDeliveries(ctx context.Context, f DeliveryFilterDB) ([]DeliveryDB, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT id, subscription_id, event_id, event_type, payload, status, attempts,
+			next_attempt_at, COALESCE(last_error, ''), created_at, updated_at
+		FROM webhook_deliveries
+		WHERE status = $1
+		ORDER BY created_at DESC, id DESC
+		LIMIT $2
+	`, f.Status, f.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var res []DeliveryDB
+	for rows.Next() {
+		var d DeliveryDB
+		err := rows.Scan(&d.ID, &d.SubscriptionID, &d.EventID, &d.EventType, &d.Payload, &d.Status, &d.Attempts,
+			&d.NextAttemptAt, &d.LastError, &d.CreatedAt, &d.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// Replay puts a dead delivery back in the queue with a fresh attempt budget.
+func (r *Repository) Replay(ctx context.Context, id uuid.UUID) (DeliveryDB, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return DeliveryDB{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	var status string
+	err = tx.QueryRow(ctx, `SELECT status FROM webhook_deliveries WHERE id = $1 FOR UPDATE`, id).Scan(&status)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return DeliveryDB{}, webhook.ErrDeliveryNotFound
+		}
+		return DeliveryDB{}, err
+	}
+	if status != domain.DeliveryDead {
+		return DeliveryDB{}, webhook.ErrDeliveryNotDead
+	}
+
+	var d DeliveryDB
+	err = tx.QueryRow(ctx, `
+		UPDATE webhook_deliveries
+		SET status = $2, attempts = 0, next_attempt_at = now(), updated_at = now()
+		WHERE id = $1
+		RETURNING id, subscription_id, event_id, event_type, payload, status, attempts,
+			next_attempt_at, COALESCE(last_error, ''), created_at, updated_at
+	`, id, domain.DeliveryPending).Scan(&d.ID, &d.SubscriptionID, &d.EventID, &d.EventType, &d.Payload, &d.Status,
+		&d.Attempts, &d.NextAttemptAt, &d.LastError, &d.CreatedAt, &d.UpdatedAt)
+	if err != nil {
+		return DeliveryDB{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return DeliveryDB{}, err
+	}
+
+	return d, nil
+}
@@ -0,0 +1,61 @@
+// Package requestid carries the X-Request-ID of an HTTP request through its
+// context so that responses and log lines can be correlated.
+package requestid
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+const Header = "X-Request-ID"
+
+// maxLength bounds ids accepted from clients so that a caller cannot inflate
+// every log line and response of its own requests.
+const maxLength = 128
+
+type ctxKey struct{}
+
+// Middleware propagates the X-Request-ID of the incoming request, or
+// generates one when it is missing or unusable, and echoes it back in the
+// response headers.
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := Resolve(r.Header.Get(Header))
+
+		w.Header().Set(Header, id)
+		next.ServeHTTP(w, r.WithContext(NewContext(r.Context(), id)))
+	})
+}
+
+// Resolve returns id when it is usable as a request id and a freshly
+// generated one otherwise.
+func Resolve(id string) string {
+	if !valid(id) {
+		return uuid.NewString()
+	}
+	return id
+}
+
+func NewContext(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, ctxKey{}, id)
+}
+
+// FromContext returns the request id stored in ctx, or an empty string.
+func FromContext(ctx context.Context) string {
+	id, _ := ctx.Value(ctxKey{}).(string)
+	return id
+}
+
+func valid(id string) bool {
+	if id == "" || len(id) > maxLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
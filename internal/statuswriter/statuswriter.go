@@ -0,0 +1,26 @@
+// Package statuswriter records the status code a handler responds with, for
+// the middlewares that log, trace and count requests.
+package statuswriter
+
+import "net/http"
+
+// Writer wraps a ResponseWriter and remembers the status written through it.
+type Writer struct {
+	http.ResponseWriter
+	status int
+}
+
+// New wraps w. The status is 200 until the handler writes another one.
+func New(w http.ResponseWriter) *Writer {
+	return &Writer{ResponseWriter: w, status: http.StatusOK}
+}
+
+func (w *Writer) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// Status is the status code sent to the client.
+func (w *Writer) Status() int {
+	return w.status
+}
@@ -0,0 +1,111 @@
+// Package tracing wires OpenTelemetry into the service: the OTLP exporter,
+// the HTTP server span, statement spans around repository calls and a pgx
+// tracer for every SQL query.
+package tracing
+
+import (
+	"context"
+	"os"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	instrumentationName = "github.com/totorialman/go-test-ac"
+	defaultServiceName  = "wallet"
+)
+
+// Span attributes specific to the wallet service.
+const (
+	WalletIDKey      = attribute.Key("wallet.id")
+	OperationTypeKey = attribute.Key("wallet.operation_type")
+	StatementNameKey = attribute.Key("db.statement.name")
+)
+
+func WalletID(id uuid.UUID) attribute.KeyValue {
+	return WalletIDKey.String(id.String())
+}
+
+func OperationType(t string) attribute.KeyValue {
+	return OperationTypeKey.String(t)
+}
+
+// Setup installs the W3C trace context propagator and, when an OTLP endpoint
+// is configured through the standard OTEL_EXPORTER_OTLP_* variables, a
+// tracer provider exporting to it. Without an endpoint spans are not
+// recorded, but incoming trace context is still honored. The returned
+// function flushes pending spans.
+func Setup(ctx context.Context) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	if os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") == "" && os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") == "" {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	exporter, err := otlptracegrpc.New(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := resource.Merge(
+		resource.NewSchemaless(semconv.ServiceName(defaultServiceName)),
+		// OTEL_SERVICE_NAME and OTEL_RESOURCE_ATTRIBUTES take precedence.
+		resource.Environment(),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(tp)
+
+	return tp.Shutdown, nil
+}
+
+// Start starts a span as a child of the one in ctx.
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(instrumentationName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// End ends span, marking it failed when err is not nil.
+func End(span trace.Span, err error) {
+	RecordError(span, err)
+	span.End()
+}
+
+func RecordError(span trace.Span, err error) {
+	if err == nil {
+		return
+	}
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
+
+type statementKey struct{}
+
+// StartStatement starts the span of a repository call. Every SQL query run
+// with the returned context is traced as its child and tagged with name.
+func StartStatement(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	ctx, span := Start(ctx, name, append(attrs, StatementNameKey.String(name))...)
+	return context.WithValue(ctx, statementKey{}, name), span
+}
+
+func statementFromContext(ctx context.Context) string {
+	name, _ := ctx.Value(statementKey{}).(string)
+	return name
+}
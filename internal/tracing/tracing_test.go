@@ -0,0 +1,115 @@
+package tracing_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/totorialman/go-test-ac/internal/tracing"
+)
+
+func newExporter(t *testing.T) *tracetest.InMemoryExporter {
+	t.Helper()
+
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() { _ = tp.Shutdown(context.Background()) })
+
+	return exporter
+}
+
+func spanByName(t *testing.T, spans tracetest.SpanStubs, name string) tracetest.SpanStub {
+	t.Helper()
+
+	for _, s := range spans {
+		if s.Name == name {
+			return s
+		}
+	}
+	require.Failf(t, "span not found", "no span named %q", name)
+	return tracetest.SpanStub{}
+}
+
+func attr(s tracetest.SpanStub, key attribute.Key) string {
+	for _, kv := range s.Attributes {
+		if kv.Key == key {
+			return kv.Value.Emit()
+		}
+	}
+	return ""
+}
+
+func TestMiddleware_HonorsTraceparent(t *testing.T) {
+	exporter := newExporter(t)
+
+	r := mux.NewRouter()
+	r.Use(tracing.Middleware)
+	r.HandleFunc("/api/v1/wallets/{WALLET_UUID}", func(w http.ResponseWriter, r *http.Request) {
+		_, span := tracing.Start(r.Context(), "wallet.Handler.Balance")
+		span.End()
+		w.WriteHeader(http.StatusInternalServerError)
+	}).Methods("GET")
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/wallets/"+uuid.NewString(), nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := exporter.GetSpans()
+	server := spanByName(t, spans, "GET /api/v1/wallets/{WALLET_UUID}")
+	child := spanByName(t, spans, "wallet.Handler.Balance")
+
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", server.SpanContext.TraceID().String())
+	assert.Equal(t, "00f067aa0ba902b7", server.Parent.SpanID().String())
+	assert.True(t, server.Parent.IsRemote())
+	assert.Equal(t, "500", attr(server, "http.response.status_code"))
+	assert.Equal(t, codes.Error, server.Status.Code)
+
+	assert.Equal(t, server.SpanContext.SpanID(), child.Parent.SpanID())
+}
+
+func TestQueryTracer(t *testing.T) {
+	exporter := newExporter(t)
+	walletID := uuid.New()
+
+	ctx, stmt := tracing.StartStatement(context.Background(), "wallet.Repository.Deposit", tracing.WalletID(walletID))
+
+	qt := tracing.QueryTracer{}
+	qctx := qt.TraceQueryStart(ctx, nil, pgx.TraceQueryStartData{SQL: "\n\t\tSELECT balance FROM wallets WHERE id = $1 FOR UPDATE"})
+	qt.TraceQueryEnd(qctx, nil, pgx.TraceQueryEndData{Err: errors.New("deadlock detected")})
+	stmt.End()
+
+	// A query outside of a statement is named after its verb.
+	qctx = qt.TraceQueryStart(context.Background(), nil, pgx.TraceQueryStartData{SQL: "delete from outbox_events"})
+	qt.TraceQueryEnd(qctx, nil, pgx.TraceQueryEndData{})
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 3)
+
+	query, parent, bare := spans[0], spans[1], spans[2]
+	assert.Equal(t, "wallet.Repository.Deposit", query.Name)
+	assert.Equal(t, parent.SpanContext.SpanID(), query.Parent.SpanID())
+	assert.Equal(t, "wallet.Repository.Deposit", attr(query, tracing.StatementNameKey))
+	assert.Equal(t, "SELECT", attr(query, "db.operation.name"))
+	assert.Equal(t, "SELECT balance FROM wallets WHERE id = $1 FOR UPDATE", attr(query, "db.query.text"))
+	assert.Equal(t, codes.Error, query.Status.Code)
+	assert.Equal(t, walletID.String(), attr(parent, tracing.WalletIDKey))
+
+	assert.Equal(t, "DELETE", bare.Name)
+	assert.Equal(t, codes.Unset, bare.Status.Code)
+}
@@ -0,0 +1,79 @@
+package wallet
+
+import (
+	"context"
+	"errors"
+
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/totorialman/go-test-ac/internal/domain"
+	walletErrors "github.com/totorialman/go-test-ac/internal/errors/wallet"
+	"github.com/totorialman/go-test-ac/internal/repository/wallet"
+	"github.com/totorialman/go-test-ac/internal/tracing"
+)
+
+// BatchResult is the outcome of one operation of a BEST_EFFORT batch: the
+// new balance, or the error that operation alone failed with.
+type BatchResult struct {
+	Balance Balance
+	Err     error
+}
+
+// Batch applies a list of deposits and withdrawals in order.
+//
+// ALL_OR_NOTHING applies them in one transaction. If any of them is invalid
+// or fails, nothing is applied and the error is a
+// *walletErrors.BatchItemError naming that operation.
+//
+// BEST_EFFORT applies each one as Operate would and reports every outcome
+// in its BatchResult; the returned error is then always nil.
+func (u *Usecase) Batch(ctx context.Context, mode string, ops []Wallet) (results []BatchResult, err error) {
+	ctx, span := tracing.Start(ctx, "wallet.Usecase.Batch",
+		attribute.String("wallet.batch_mode", mode),
+		attribute.Int("wallet.batch_size", len(ops)),
+	)
+	defer func() { tracing.End(span, err) }()
+
+	switch mode {
+	case domain.BatchAllOrNothing:
+		return u.batchAllOrNothing(ctx, ops)
+	case domain.BatchBestEffort:
+		results = make([]BatchResult, len(ops))
+		for i, op := range ops {
+			results[i].Balance, results[i].Err = u.Operate(ctx, op)
+		}
+		return results, nil
+	default:
+		return nil, walletErrors.ErrInvalidBatchMode
+	}
+}
+
+func (u *Usecase) batchAllOrNothing(ctx context.Context, ops []Wallet) ([]BatchResult, error) {
+	dbOps := make([]wallet.OperationDB, len(ops))
+	for i, op := range ops {
+		dbWallet, err := u.walletDB(op)
+		if err != nil {
+			return nil, &walletErrors.BatchItemError{Index: i, Err: err}
+		}
+		dbOps[i] = wallet.OperationDB{OperationType: op.OperationType, Wallet: dbWallet}
+	}
+
+	balances, err := u.repo.Batch(ctx, dbOps)
+	if err != nil {
+		var itemErr *walletErrors.BatchItemError
+		if errors.As(err, &itemErr) {
+			u.observer.ObserveOperation(ops[itemErr.Index].OperationType, itemErr.Err)
+		}
+		u.logger.DebugContext(ctx, "batch rejected", "operations", len(ops), "error", err)
+		return nil, err
+	}
+
+	results := make([]BatchResult, len(ops))
+	for i, b := range balances {
+		results[i].Balance = toBalance(b)
+		u.observer.ObserveOperation(ops[i].OperationType, nil)
+	}
+	u.logger.DebugContext(ctx, "batch applied", "operations", len(ops))
+
+	return results, nil
+}
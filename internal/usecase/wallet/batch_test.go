@@ -0,0 +1,132 @@
+package wallet_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/totorialman/go-test-ac/internal/domain"
+	wErr "github.com/totorialman/go-test-ac/internal/errors/wallet"
+	repo "github.com/totorialman/go-test-ac/internal/repository/wallet"
+	w "github.com/totorialman/go-test-ac/internal/usecase/wallet"
+)
+
+func TestUsecase_BatchAllOrNothing(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := NewMockrepository(ctrl)
+	observer := &recordingObserver{}
+	usecase := w.NewUsecase(mockRepo, w.WithOperationObserver(observer))
+
+	first, second := uuid.New(), uuid.New()
+	ops := []w.Wallet{
+		{ID: first, OperationType: domain.Deposit, Amount: 100},
+		{ID: second, OperationType: domain.Withdraw, Amount: 30},
+	}
+
+	mockRepo.EXPECT().
+		Batch(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, got []repo.OperationDB) ([]repo.BalanceDB, error) {
+			require.Len(t, got, 2)
+			assert.True(t, walletDB(repo.WalletDB{ID: first, Amount: 100}).Matches(got[0].Wallet))
+			assert.Equal(t, domain.Deposit, got[0].OperationType)
+			assert.True(t, walletDB(repo.WalletDB{ID: second, Amount: 30}).Matches(got[1].Wallet))
+			assert.Equal(t, domain.Withdraw, got[1].OperationType)
+			return []repo.BalanceDB{
+				{Balance: 100, Available: 100, Currency: "RUB"},
+				{Balance: 70, Available: 70, Currency: "RUB"},
+			}, nil
+		})
+
+	results, err := usecase.Batch(context.Background(), domain.BatchAllOrNothing, ops)
+
+	require.NoError(t, err)
+	assert.Equal(t, []w.BatchResult{
+		{Balance: w.Balance{Amount: 100, Available: 100, Currency: "RUB", Exponent: 2}},
+		{Balance: w.Balance{Amount: 70, Available: 70, Currency: "RUB", Exponent: 2}},
+	}, results)
+	assert.Len(t, observer.ops, 2)
+}
+
+func TestUsecase_BatchAllOrNothingFailure(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := NewMockrepository(ctrl)
+	usecase := w.NewUsecase(mockRepo)
+
+	t.Run("invalid operation is not sent to the repository", func(t *testing.T) {
+		ops := []w.Wallet{
+			{ID: uuid.New(), OperationType: domain.Deposit, Amount: 100},
+			{ID: uuid.New(), OperationType: domain.Deposit, Amount: 100, Currency: "XXX"},
+		}
+
+		_, err := usecase.Batch(context.Background(), domain.BatchAllOrNothing, ops)
+
+		var itemErr *wErr.BatchItemError
+		require.ErrorAs(t, err, &itemErr)
+		assert.Equal(t, 1, itemErr.Index)
+		assert.ErrorIs(t, err, wErr.ErrInvalidCurrency)
+	})
+
+	t.Run("repository failure", func(t *testing.T) {
+		mockRepo.EXPECT().
+			Batch(gomock.Any(), gomock.Any()).
+			Return(nil, &wErr.BatchItemError{Index: 0, Err: wErr.ErrNotEnoughFunds})
+
+		_, err := usecase.Batch(context.Background(), domain.BatchAllOrNothing, []w.Wallet{
+			{ID: uuid.New(), OperationType: domain.Withdraw, Amount: 100},
+		})
+
+		assert.ErrorIs(t, err, wErr.ErrNotEnoughFunds)
+	})
+}
+
+func TestUsecase_BatchBestEffort(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := NewMockrepository(ctrl)
+	usecase := w.NewUsecase(mockRepo)
+
+	first, second := uuid.New(), uuid.New()
+	dbErr := errors.New("connection reset")
+	gomock.InOrder(
+		mockRepo.EXPECT().
+			Withdraw(gomock.Any(), walletDB(repo.WalletDB{ID: first, Amount: 500})).
+			Return(repo.BalanceDB{}, wErr.ErrNotEnoughFunds),
+		mockRepo.EXPECT().
+			Deposit(gomock.Any(), walletDB(repo.WalletDB{ID: second, Amount: 100})).
+			Return(repo.BalanceDB{Balance: 100, Available: 100, Currency: "JPY"}, nil),
+		mockRepo.EXPECT().
+			Deposit(gomock.Any(), walletDB(repo.WalletDB{ID: second, Amount: 1})).
+			Return(repo.BalanceDB{}, dbErr),
+	)
+
+	results, err := usecase.Batch(context.Background(), domain.BatchBestEffort, []w.Wallet{
+		{ID: first, OperationType: domain.Withdraw, Amount: 500},
+		{ID: second, OperationType: domain.Deposit, Amount: 100},
+		{ID: second, OperationType: domain.Deposit, Amount: 1},
+	})
+
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+	assert.ErrorIs(t, results[0].Err, wErr.ErrNotEnoughFunds)
+	assert.NoError(t, results[1].Err)
+	assert.Equal(t, w.Balance{Amount: 100, Available: 100, Currency: "JPY"}, results[1].Balance)
+	assert.ErrorIs(t, results[2].Err, dbErr)
+}
+
+func TestUsecase_BatchInvalidMode(t *testing.T) {
+	usecase := w.NewUsecase(nil)
+
+	_, err := usecase.Batch(context.Background(), "SOMETIMES", nil)
+
+	assert.ErrorIs(t, err, wErr.ErrInvalidBatchMode)
+}
@@ -10,7 +10,20 @@ import (
 )
 
 type repository interface {
-	GetBalance(ctx context.Context, id uuid.UUID) (int64, error)
-	Deposit(ctx context.Context, w wallet.WalletDB) (int64, error)
-	Withdraw(ctx context.Context, w wallet.WalletDB) (int64, error)
+	GetBalance(ctx context.Context, id uuid.UUID) (wallet.BalanceDB, error)
+	Deposit(ctx context.Context, w wallet.WalletDB) (wallet.BalanceDB, error)
+	Withdraw(ctx context.Context, w wallet.WalletDB) (wallet.BalanceDB, error)
+	Batch(ctx context.Context, ops []wallet.OperationDB) ([]wallet.BalanceDB, error)
+	Transfer(ctx context.Context, t wallet.TransferDB) (wallet.TransferResultDB, error)
+	Authorize(ctx context.Context, a wallet.AuthorizeDB) (wallet.HoldDB, wallet.BalanceDB, error)
+	Capture(ctx context.Context, c wallet.CaptureDB) (wallet.HoldDB, wallet.BalanceDB, error)
+	Void(ctx context.Context, holdID uuid.UUID) (wallet.HoldDB, wallet.BalanceDB, error)
+	Create(ctx context.Context, c wallet.CreateWalletDB) (wallet.WalletInfoDB, error)
+	GetWallet(ctx context.Context, id uuid.UUID) (wallet.WalletInfoDB, error)
+	UpdateStatus(ctx context.Context, id uuid.UUID, status string) (wallet.WalletInfoDB, error)
+	SetHot(ctx context.Context, id uuid.UUID, hot bool) (wallet.WalletInfoDB, error)
+	GetLimits(ctx context.Context, id uuid.UUID) (wallet.LimitsDB, error)
+	SetLimits(ctx context.Context, id uuid.UUID, l wallet.LimitsDB) (wallet.LimitsDB, error)
+	Reverse(ctx context.Context, txID uuid.UUID, check wallet.ReversalCheck) (wallet.TransactionDB, wallet.BalanceDB, error)
+	History(ctx context.Context, f wallet.HistoryFilterDB) ([]wallet.TransactionDB, error)
 }
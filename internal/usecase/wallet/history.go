@@ -0,0 +1,113 @@
+package wallet
+
+import (
+	"context"
+	"encoding/base64"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/totorialman/go-test-ac/internal/domain"
+	walletErrors "github.com/totorialman/go-test-ac/internal/errors/wallet"
+	"github.com/totorialman/go-test-ac/internal/repository/wallet"
+)
+
+const (
+	DefaultHistoryLimit = 50
+	MaxHistoryLimit     = 500
+)
+
+func (u *Usecase) History(ctx context.Context, f HistoryFilter) (HistoryPage, error) {
+	if f.Limit == 0 {
+		f.Limit = DefaultHistoryLimit
+	}
+	if f.Limit < 0 || f.Limit > MaxHistoryLimit {
+		return HistoryPage{}, walletErrors.ErrInvalidFilter
+	}
+	if f.OperationType != "" && !domain.IsLedgerOperation(f.OperationType) {
+		return HistoryPage{}, walletErrors.ErrInvalidOperation
+	}
+	if !f.From.IsZero() && !f.To.IsZero() && !f.From.Before(f.To) {
+		return HistoryPage{}, walletErrors.ErrInvalidFilter
+	}
+
+	dbFilter := wallet.HistoryFilterDB{
+		WalletID:      f.WalletID,
+		OperationType: f.OperationType,
+		From:          f.From,
+		To:            f.To,
+		// One extra row tells us whether there is a next page.
+		Limit: f.Limit + 1,
+	}
+	if f.Cursor != "" {
+		createdAt, id, err := decodeCursor(f.Cursor)
+		if err != nil {
+			return HistoryPage{}, err
+		}
+		dbFilter.BeforeCreatedAt = createdAt
+		dbFilter.BeforeID = id
+	}
+
+	rows, err := u.repo.History(ctx, dbFilter)
+	if err != nil {
+		return HistoryPage{}, err
+	}
+
+	var page HistoryPage
+	if len(rows) > f.Limit {
+		rows = rows[:f.Limit]
+		last := rows[len(rows)-1]
+		page.NextCursor = encodeCursor(last.CreatedAt, last.ID)
+	}
+
+	page.Transactions = make([]Transaction, 0, len(rows))
+	for _, t := range rows {
+		page.Transactions = append(page.Transactions, toTransaction(t))
+	}
+
+	return page, nil
+}
+
+func toTransaction(t wallet.TransactionDB) Transaction {
+	return Transaction{
+		ID:             t.ID,
+		WalletID:       t.WalletID,
+		OperationType:  t.OperationType,
+		Amount:         t.Amount,
+		BalanceAfter:   t.BalanceAfter,
+		CounterpartyID: t.CounterpartyID,
+		ReversalOf:     t.ReversalOf,
+		CreatedAt:      t.CreatedAt,
+	}
+}
+
+// A cursor is the (created_at, id) of the last entry on the previous page,
+// so pages stay stable while new entries are being appended.
+func encodeCursor(createdAt time.Time, id uuid.UUID) string {
+	raw := createdAt.UTC().Format(time.RFC3339Nano) + "|" + id.String()
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeCursor(cursor string) (time.Time, uuid.UUID, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return time.Time{}, uuid.Nil, walletErrors.ErrInvalidCursor
+	}
+
+	tsPart, idPart, ok := strings.Cut(string(raw), "|")
+	if !ok {
+		return time.Time{}, uuid.Nil, walletErrors.ErrInvalidCursor
+	}
+
+	createdAt, err := time.Parse(time.RFC3339Nano, tsPart)
+	if err != nil {
+		return time.Time{}, uuid.Nil, walletErrors.ErrInvalidCursor
+	}
+	id, err := uuid.Parse(idPart)
+	if err != nil {
+		return time.Time{}, uuid.Nil, walletErrors.ErrInvalidCursor
+	}
+
+	return createdAt, id, nil
+}
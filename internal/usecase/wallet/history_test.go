@@ -0,0 +1,102 @@
+package wallet_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/totorialman/go-test-ac/internal/domain"
+	wErr "github.com/totorialman/go-test-ac/internal/errors/wallet"
+	repo "github.com/totorialman/go-test-ac/internal/repository/wallet"
+	w "github.com/totorialman/go-test-ac/internal/usecase/wallet"
+)
+
+func TestUsecase_History(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := NewMockrepository(ctrl)
+	usecase := w.NewUsecase(mockRepo)
+
+	walletID := uuid.New()
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	rows := []repo.TransactionDB{
+		{ID: uuid.New(), WalletID: walletID, OperationType: domain.Deposit, Amount: 30, BalanceAfter: 60, CreatedAt: now},
+		{ID: uuid.New(), WalletID: walletID, OperationType: domain.Deposit, Amount: 20, BalanceAfter: 30, CreatedAt: now.Add(-time.Second)},
+		{ID: uuid.New(), WalletID: walletID, OperationType: domain.Deposit, Amount: 10, BalanceAfter: 10, CreatedAt: now.Add(-2 * time.Second)},
+	}
+
+	t.Run("first page returns cursor", func(t *testing.T) {
+		mockRepo.EXPECT().
+			History(gomock.Any(), repo.HistoryFilterDB{WalletID: walletID, Limit: 3}).
+			Return(rows, nil)
+
+		page, err := usecase.History(context.Background(), w.HistoryFilter{WalletID: walletID, Limit: 2})
+		assert.NoError(t, err)
+		assert.Len(t, page.Transactions, 2)
+		assert.Equal(t, rows[0].ID, page.Transactions[0].ID)
+		assert.NotEmpty(t, page.NextCursor)
+
+		mockRepo.EXPECT().
+			History(gomock.Any(), repo.HistoryFilterDB{
+				WalletID:        walletID,
+				BeforeCreatedAt: rows[1].CreatedAt,
+				BeforeID:        rows[1].ID,
+				Limit:           3,
+			}).
+			Return(rows[2:], nil)
+
+		page, err = usecase.History(context.Background(), w.HistoryFilter{WalletID: walletID, Limit: 2, Cursor: page.NextCursor})
+		assert.NoError(t, err)
+		assert.Len(t, page.Transactions, 1)
+		assert.Empty(t, page.NextCursor)
+	})
+
+	t.Run("default limit", func(t *testing.T) {
+		mockRepo.EXPECT().
+			History(gomock.Any(), repo.HistoryFilterDB{WalletID: walletID, OperationType: domain.Withdraw, Limit: w.DefaultHistoryLimit + 1}).
+			Return(nil, nil)
+
+		page, err := usecase.History(context.Background(), w.HistoryFilter{WalletID: walletID, OperationType: domain.Withdraw})
+		assert.NoError(t, err)
+		assert.Empty(t, page.Transactions)
+	})
+
+	tests := []struct {
+		name    string
+		filter  w.HistoryFilter
+		wantErr error
+	}{
+		{
+			name:    "invalid cursor",
+			filter:  w.HistoryFilter{WalletID: walletID, Cursor: "garbage"},
+			wantErr: wErr.ErrInvalidCursor,
+		},
+		{
+			name:    "limit too large",
+			filter:  w.HistoryFilter{WalletID: walletID, Limit: w.MaxHistoryLimit + 1},
+			wantErr: wErr.ErrInvalidFilter,
+		},
+		{
+			name:    "inverted time range",
+			filter:  w.HistoryFilter{WalletID: walletID, From: now, To: now.Add(-time.Hour)},
+			wantErr: wErr.ErrInvalidFilter,
+		},
+		{
+			name:    "unknown operation type",
+			filter:  w.HistoryFilter{WalletID: walletID, OperationType: "invalid"},
+			wantErr: wErr.ErrInvalidOperation,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := usecase.History(context.Background(), tt.filter)
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
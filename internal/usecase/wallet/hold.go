@@ -0,0 +1,88 @@
+package wallet
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/totorialman/go-test-ac/internal/domain"
+	walletErrors "github.com/totorialman/go-test-ac/internal/errors/wallet"
+	"github.com/totorialman/go-test-ac/internal/repository/wallet"
+)
+
+// Authorize reserves funds: the available balance drops by the amount while
+// the ledger balance stays the same until the hold is captured.
+func (u *Usecase) Authorize(ctx context.Context, a Authorization) (res Hold, err error) {
+	defer func() { u.observer.ObserveOperation(domain.Authorize, err) }()
+
+	if a.Amount <= 0 {
+		return Hold{}, walletErrors.ErrInvalidAmount
+	}
+	if a.Currency != "" {
+		if _, ok := domain.CurrencyExponent(a.Currency); !ok {
+			return Hold{}, walletErrors.ErrInvalidCurrency
+		}
+	}
+
+	ttl := a.TTL
+	if ttl == 0 {
+		ttl = u.holdTTL
+	}
+	if ttl < 0 || ttl > MaxHoldTTL {
+		return Hold{}, walletErrors.ErrInvalidHoldTTL
+	}
+
+	h, b, err := u.repo.Authorize(ctx, wallet.AuthorizeDB{
+		WalletID:    a.WalletID,
+		Amount:      a.Amount,
+		Currency:    a.Currency,
+		TTL:         ttl,
+		CheckLimits: checkLimits,
+	})
+	if err != nil {
+		return Hold{}, err
+	}
+
+	return toHold(h, b), nil
+}
+
+// Capture settles amount out of the hold and releases the remainder. A zero
+// amount captures the whole hold.
+func (u *Usecase) Capture(ctx context.Context, holdID uuid.UUID, amount int64) (res Hold, err error) {
+	defer func() { u.observer.ObserveOperation(domain.Capture, err) }()
+
+	if amount < 0 {
+		return Hold{}, walletErrors.ErrInvalidAmount
+	}
+
+	h, b, err := u.repo.Capture(ctx, wallet.CaptureDB{HoldID: holdID, Amount: amount, CheckLimits: checkLimits})
+	if err != nil {
+		return Hold{}, err
+	}
+
+	return toHold(h, b), nil
+}
+
+func (u *Usecase) Void(ctx context.Context, holdID uuid.UUID) (res Hold, err error) {
+	defer func() { u.observer.ObserveOperation(domain.Void, err) }()
+
+	h, b, err := u.repo.Void(ctx, holdID)
+	if err != nil {
+		return Hold{}, err
+	}
+
+	return toHold(h, b), nil
+}
+
+func toHold(h wallet.HoldDB, b wallet.BalanceDB) Hold {
+	return Hold{
+		ID:             h.ID,
+		WalletID:       h.WalletID,
+		Amount:         h.Amount,
+		CapturedAmount: h.CapturedAmount,
+		Status:         h.Status,
+		ExpiresAt:      h.ExpiresAt,
+		CreatedAt:      h.CreatedAt,
+		Balance:        toBalance(b),
+	}
+}
@@ -0,0 +1,119 @@
+package wallet_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/totorialman/go-test-ac/internal/domain"
+	wErr "github.com/totorialman/go-test-ac/internal/errors/wallet"
+	repo "github.com/totorialman/go-test-ac/internal/repository/wallet"
+	w "github.com/totorialman/go-test-ac/internal/usecase/wallet"
+)
+
+func TestUsecase_Authorize(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := NewMockrepository(ctrl)
+	usecase := w.NewUsecase(mockRepo, w.WithHoldTTL(time.Hour))
+
+	walletID := uuid.New()
+	holdID := uuid.New()
+
+	tests := []struct {
+		name      string
+		auth      w.Authorization
+		mockSetup func()
+		wantErr   error
+	}{
+		{
+			name: "Default TTL",
+			auth: w.Authorization{WalletID: walletID, Amount: 30},
+			mockSetup: func() {
+				mockRepo.EXPECT().
+					Authorize(gomock.Any(), limitChecked(repo.AuthorizeDB{WalletID: walletID, Amount: 30, TTL: time.Hour})).
+					Return(
+						repo.HoldDB{ID: holdID, WalletID: walletID, Amount: 30, Status: domain.HoldActive},
+						repo.BalanceDB{Balance: 100, Available: 70, Currency: "RUB"},
+						nil,
+					)
+			},
+		},
+		{
+			name: "Explicit TTL",
+			auth: w.Authorization{WalletID: walletID, Amount: 30, TTL: time.Minute},
+			mockSetup: func() {
+				mockRepo.EXPECT().
+					Authorize(gomock.Any(), limitChecked(repo.AuthorizeDB{WalletID: walletID, Amount: 30, TTL: time.Minute})).
+					Return(repo.HoldDB{}, repo.BalanceDB{}, wErr.ErrNotEnoughFunds)
+			},
+			wantErr: wErr.ErrNotEnoughFunds,
+		},
+		{
+			name:      "TTL too long",
+			auth:      w.Authorization{WalletID: walletID, Amount: 30, TTL: w.MaxHoldTTL + time.Second},
+			mockSetup: func() {},
+			wantErr:   wErr.ErrInvalidHoldTTL,
+		},
+		{
+			name:      "Invalid amount",
+			auth:      w.Authorization{WalletID: walletID, Amount: -1},
+			mockSetup: func() {},
+			wantErr:   wErr.ErrInvalidAmount,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+			hold, err := usecase.Authorize(context.Background(), tt.auth)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, holdID, hold.ID)
+			assert.Equal(t, int64(100), hold.Balance.Amount)
+			assert.Equal(t, int64(70), hold.Balance.Available)
+		})
+	}
+}
+
+func TestUsecase_CaptureAndVoid(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := NewMockrepository(ctrl)
+	usecase := w.NewUsecase(mockRepo)
+
+	walletID := uuid.New()
+	holdID := uuid.New()
+
+	mockRepo.EXPECT().
+		Capture(gomock.Any(), limitChecked(repo.CaptureDB{HoldID: holdID, Amount: 20})).
+		Return(
+			repo.HoldDB{ID: holdID, WalletID: walletID, Amount: 30, CapturedAmount: 20, Status: domain.HoldCaptured},
+			repo.BalanceDB{Balance: 80, Available: 80, Currency: "RUB"},
+			nil,
+		)
+
+	hold, err := usecase.Capture(context.Background(), holdID, 20)
+	assert.NoError(t, err)
+	assert.Equal(t, domain.HoldCaptured, hold.Status)
+	assert.Equal(t, int64(80), hold.Balance.Amount)
+
+	_, err = usecase.Capture(context.Background(), holdID, -5)
+	assert.ErrorIs(t, err, wErr.ErrInvalidAmount)
+
+	mockRepo.EXPECT().
+		Void(gomock.Any(), holdID).
+		Return(repo.HoldDB{}, repo.BalanceDB{}, wErr.ErrHoldNotActive)
+
+	_, err = usecase.Void(context.Background(), holdID)
+	assert.ErrorIs(t, err, wErr.ErrHoldNotActive)
+}
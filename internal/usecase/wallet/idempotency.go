@@ -0,0 +1,34 @@
+package wallet
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	walletErrors "github.com/totorialman/go-test-ac/internal/errors/wallet"
+	"github.com/totorialman/go-test-ac/internal/repository/wallet"
+)
+
+const maxIdempotencyKeyLen = 255
+
+func (u *Usecase) idempotency(w Wallet) (wallet.IdempotencyDB, error) {
+	if w.IdempotencyKey == "" {
+		return wallet.IdempotencyDB{}, nil
+	}
+	if len(w.IdempotencyKey) > maxIdempotencyKeyLen {
+		return wallet.IdempotencyDB{}, walletErrors.ErrInvalidIdempotencyKey
+	}
+
+	return wallet.IdempotencyDB{
+		Key:         w.IdempotencyKey,
+		RequestHash: requestHash(w),
+		TTL:         u.idempotencyTTL,
+	}, nil
+}
+
+// requestHash fingerprints everything that defines the operation, so a key
+// can only be replayed with an identical request.
+func requestHash(w Wallet) string {
+	sum := sha256.Sum256(fmt.Appendf(nil, "%s|%s|%d|%s", w.ID, w.OperationType, w.Amount, w.Currency))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,103 @@
+package wallet
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+
+	"github.com/google/uuid"
+
+	"github.com/totorialman/go-test-ac/internal/domain"
+	walletErrors "github.com/totorialman/go-test-ac/internal/errors/wallet"
+	"github.com/totorialman/go-test-ac/internal/repository/wallet"
+)
+
+const maxOwnerIDLen = 255
+
+func (u *Usecase) Create(ctx context.Context, w NewWallet) (WalletInfo, error) {
+	if w.OwnerID == "" || len(w.OwnerID) > maxOwnerIDLen {
+		return WalletInfo{}, walletErrors.ErrInvalidOwner
+	}
+
+	if w.Currency == "" {
+		w.Currency = domain.DefaultCurrency
+	}
+	if _, ok := domain.CurrencyExponent(w.Currency); !ok {
+		return WalletInfo{}, walletErrors.ErrInvalidCurrency
+	}
+
+	metadata, err := normalizeMetadata(w.Metadata)
+	if err != nil {
+		return WalletInfo{}, err
+	}
+
+	info, err := u.repo.Create(ctx, wallet.CreateWalletDB{
+		OwnerID:  w.OwnerID,
+		Currency: w.Currency,
+		Metadata: metadata,
+	})
+	if err != nil {
+		return WalletInfo{}, err
+	}
+
+	return toWalletInfo(info), nil
+}
+
+func (u *Usecase) Get(ctx context.Context, id uuid.UUID) (WalletInfo, error) {
+	info, err := u.repo.GetWallet(ctx, id)
+	if err != nil {
+		return WalletInfo{}, err
+	}
+	return toWalletInfo(info), nil
+}
+
+func (u *Usecase) UpdateStatus(ctx context.Context, id uuid.UUID, status string) (WalletInfo, error) {
+	switch status {
+	case domain.WalletActive, domain.WalletFrozen, domain.WalletClosed:
+	default:
+		return WalletInfo{}, walletErrors.ErrInvalidStatus
+	}
+
+	info, err := u.repo.UpdateStatus(ctx, id, status)
+	if err != nil {
+		return WalletInfo{}, err
+	}
+	return toWalletInfo(info), nil
+}
+
+// SetHot flags or unflags the wallet as hot. Deposits to a hot wallet
+// contend less with each other when the service runs with hot buckets.
+func (u *Usecase) SetHot(ctx context.Context, id uuid.UUID, hot bool) (WalletInfo, error) {
+	info, err := u.repo.SetHot(ctx, id, hot)
+	if err != nil {
+		return WalletInfo{}, err
+	}
+	return toWalletInfo(info), nil
+}
+
+func normalizeMetadata(raw []byte) ([]byte, error) {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 || bytes.Equal(raw, []byte("null")) {
+		return []byte("{}"), nil
+	}
+
+	var obj map[string]any
+	if err := json.Unmarshal(raw, &obj); err != nil {
+		return nil, walletErrors.ErrInvalidMetadata
+	}
+
+	return raw, nil
+}
+
+func toWalletInfo(info wallet.WalletInfoDB) WalletInfo {
+	return WalletInfo{
+		ID:        info.ID,
+		OwnerID:   info.OwnerID,
+		Status:    info.Status,
+		Metadata:  info.Metadata,
+		Balance:   toBalance(info.Balance),
+		Hot:       info.Hot,
+		CreatedAt: info.CreatedAt,
+		UpdatedAt: info.UpdatedAt,
+	}
+}
@@ -0,0 +1,136 @@
+package wallet_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/totorialman/go-test-ac/internal/domain"
+	wErr "github.com/totorialman/go-test-ac/internal/errors/wallet"
+	repo "github.com/totorialman/go-test-ac/internal/repository/wallet"
+	w "github.com/totorialman/go-test-ac/internal/usecase/wallet"
+)
+
+func TestUsecase_Create(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := NewMockrepository(ctrl)
+	usecase := w.NewUsecase(mockRepo)
+
+	walletID := uuid.New()
+
+	tests := []struct {
+		name      string
+		wallet    w.NewWallet
+		mockSetup func()
+		wantErr   error
+	}{
+		{
+			name:   "Defaults",
+			wallet: w.NewWallet{OwnerID: "owner-1"},
+			mockSetup: func() {
+				mockRepo.EXPECT().
+					Create(gomock.Any(), repo.CreateWalletDB{OwnerID: "owner-1", Currency: domain.DefaultCurrency, Metadata: []byte("{}")}).
+					Return(repo.WalletInfoDB{ID: walletID, OwnerID: "owner-1", Status: domain.WalletActive}, nil)
+			},
+		},
+		{
+			name:   "With metadata",
+			wallet: w.NewWallet{OwnerID: "owner-1", Currency: "USD", Metadata: []byte(` {"tier":"gold"} `)},
+			mockSetup: func() {
+				mockRepo.EXPECT().
+					Create(gomock.Any(), repo.CreateWalletDB{OwnerID: "owner-1", Currency: "USD", Metadata: []byte(`{"tier":"gold"}`)}).
+					Return(repo.WalletInfoDB{ID: walletID, OwnerID: "owner-1", Status: domain.WalletActive}, nil)
+			},
+		},
+		{
+			name:      "Missing owner",
+			wallet:    w.NewWallet{},
+			mockSetup: func() {},
+			wantErr:   wErr.ErrInvalidOwner,
+		},
+		{
+			name:      "Metadata is not an object",
+			wallet:    w.NewWallet{OwnerID: "owner-1", Metadata: []byte(`[1,2]`)},
+			mockSetup: func() {},
+			wantErr:   wErr.ErrInvalidMetadata,
+		},
+		{
+			name:      "Unknown currency",
+			wallet:    w.NewWallet{OwnerID: "owner-1", Currency: "XXX"},
+			mockSetup: func() {},
+			wantErr:   wErr.ErrInvalidCurrency,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+			info, err := usecase.Create(context.Background(), tt.wallet)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, walletID, info.ID)
+		})
+	}
+}
+
+func TestUsecase_UpdateStatus(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := NewMockrepository(ctrl)
+	usecase := w.NewUsecase(mockRepo)
+
+	walletID := uuid.New()
+
+	mockRepo.EXPECT().
+		UpdateStatus(gomock.Any(), walletID, domain.WalletFrozen).
+		Return(repo.WalletInfoDB{ID: walletID, Status: domain.WalletFrozen}, nil)
+
+	info, err := usecase.UpdateStatus(context.Background(), walletID, domain.WalletFrozen)
+	assert.NoError(t, err)
+	assert.Equal(t, domain.WalletFrozen, info.Status)
+
+	_, err = usecase.UpdateStatus(context.Background(), walletID, "DELETED")
+	assert.ErrorIs(t, err, wErr.ErrInvalidStatus)
+
+	mockRepo.EXPECT().
+		UpdateStatus(gomock.Any(), walletID, domain.WalletClosed).
+		Return(repo.WalletInfoDB{}, wErr.ErrWalletNotEmpty)
+
+	_, err = usecase.UpdateStatus(context.Background(), walletID, domain.WalletClosed)
+	assert.ErrorIs(t, err, wErr.ErrWalletNotEmpty)
+}
+
+func TestUsecase_SetHot(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := NewMockrepository(ctrl)
+	usecase := w.NewUsecase(mockRepo)
+
+	walletID := uuid.New()
+
+	mockRepo.EXPECT().
+		SetHot(gomock.Any(), walletID, true).
+		Return(repo.WalletInfoDB{ID: walletID, Hot: true, Balance: repo.BalanceDB{Balance: 500, Available: 500, Currency: "RUB"}}, nil)
+
+	info, err := usecase.SetHot(context.Background(), walletID, true)
+	assert.NoError(t, err)
+	assert.True(t, info.Hot)
+	assert.Equal(t, int64(500), info.Balance.Amount)
+
+	mockRepo.EXPECT().
+		SetHot(gomock.Any(), walletID, false).
+		Return(repo.WalletInfoDB{}, wErr.ErrWalletNotFound)
+
+	_, err = usecase.SetHot(context.Background(), walletID, false)
+	assert.ErrorIs(t, err, wErr.ErrWalletNotFound)
+}
@@ -0,0 +1,73 @@
+package wallet
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/totorialman/go-test-ac/internal/domain"
+	walletErrors "github.com/totorialman/go-test-ac/internal/errors/wallet"
+	"github.com/totorialman/go-test-ac/internal/repository/wallet"
+)
+
+func (u *Usecase) GetLimits(ctx context.Context, id uuid.UUID) (Limits, error) {
+	res, err := u.repo.GetLimits(ctx, id)
+	if err != nil {
+		return Limits{}, err
+	}
+	return Limits(res), nil
+}
+
+// SetLimits replaces the wallet's overrides and returns the limits now in
+// force. Nil fields fall back to the wallet's tier.
+func (u *Usecase) SetLimits(ctx context.Context, id uuid.UUID, l Limits) (Limits, error) {
+	if !positiveOrNil(l.MaxWithdrawAmount) || !positiveOrNil(l.MaxDailyWithdraw) ||
+		!nonNegativeOrNil(l.MaxBalance) || !nonNegativeOrNil(l.MinBalance) {
+		return Limits{}, walletErrors.ErrInvalidLimits
+	}
+
+	res, err := u.repo.SetLimits(ctx, id, wallet.LimitsDB(l))
+	if err != nil {
+		return Limits{}, err
+	}
+	return Limits(res), nil
+}
+
+// checkLimits holds the rules every balance change is checked against. The
+// repository runs it under the wallet row lock, right before the balance
+// changes, once per wallet involved.
+func checkLimits(s wallet.LimitStateDB) error {
+	l := s.Limits
+	amount := s.Amount
+
+	switch s.OperationType {
+	case domain.Deposit, domain.TransferIn:
+		if l.MaxBalance != nil && s.Balance.Balance+amount > *l.MaxBalance {
+			return fmt.Errorf("%w: balance cap %d", walletErrors.ErrLimitExceeded, *l.MaxBalance)
+		}
+	case domain.Withdraw, domain.TransferOut, domain.Capture:
+		if l.MaxWithdrawAmount != nil && amount > *l.MaxWithdrawAmount {
+			return fmt.Errorf("%w: single withdrawal above %d", walletErrors.ErrLimitExceeded, *l.MaxWithdrawAmount)
+		}
+		if l.MaxDailyWithdraw != nil && s.WithdrawnToday+amount > *l.MaxDailyWithdraw {
+			return fmt.Errorf("%w: daily withdrawals above %d", walletErrors.ErrLimitExceeded, *l.MaxDailyWithdraw)
+		}
+		// Funds reserved by holds do not count towards the retained
+		// balance: they are about to leave the wallet. A capture spends
+		// funds its hold already took out of it.
+		if s.OperationType != domain.Capture && l.MinBalance != nil && s.Balance.Available-amount < *l.MinBalance {
+			return fmt.Errorf("%w: retained balance below %d", walletErrors.ErrLimitExceeded, *l.MinBalance)
+		}
+	}
+
+	return nil
+}
+
+func positiveOrNil(v *int64) bool {
+	return v == nil || *v > 0
+}
+
+func nonNegativeOrNil(v *int64) bool {
+	return v == nil || *v >= 0
+}
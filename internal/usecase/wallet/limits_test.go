@@ -0,0 +1,255 @@
+package wallet_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/totorialman/go-test-ac/internal/domain"
+	wErr "github.com/totorialman/go-test-ac/internal/errors/wallet"
+	repo "github.com/totorialman/go-test-ac/internal/repository/wallet"
+	w "github.com/totorialman/go-test-ac/internal/usecase/wallet"
+)
+
+func limit(v int64) *int64 {
+	return &v
+}
+
+func TestUsecase_OperateLimits(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := NewMockrepository(ctrl)
+	usecase := w.NewUsecase(mockRepo)
+
+	walletID := uuid.New()
+
+	// applyCheck makes the mocked repository run the usecase rules against
+	// the given state, the way the real one does under the wallet row lock.
+	applyCheck := func(opType string, state repo.LimitStateDB) func(context.Context, repo.WalletDB) (repo.BalanceDB, error) {
+		return func(_ context.Context, db repo.WalletDB) (repo.BalanceDB, error) {
+			state.OperationType = opType
+			state.Amount = db.Amount
+			if err := db.CheckLimits(state); err != nil {
+				return repo.BalanceDB{}, err
+			}
+			return repo.BalanceDB{Currency: "RUB"}, nil
+		}
+	}
+
+	tests := []struct {
+		name    string
+		opType  string
+		amount  int64
+		state   repo.LimitStateDB
+		wantErr error
+	}{
+		{
+			name:   "No limits",
+			opType: domain.Withdraw,
+			amount: 1000,
+			state:  repo.LimitStateDB{Balance: repo.BalanceDB{Balance: 1000, Available: 1000}},
+		},
+		{
+			name:    "Single withdrawal above max",
+			opType:  domain.Withdraw,
+			amount:  501,
+			state:   repo.LimitStateDB{Limits: repo.LimitsDB{MaxWithdrawAmount: limit(500)}, Balance: repo.BalanceDB{Balance: 1000, Available: 1000}},
+			wantErr: wErr.ErrLimitExceeded,
+		},
+		{
+			name:   "Daily total reached exactly",
+			opType: domain.Withdraw,
+			amount: 300,
+			state:  repo.LimitStateDB{Limits: repo.LimitsDB{MaxDailyWithdraw: limit(1000)}, WithdrawnToday: 700, Balance: repo.BalanceDB{Balance: 1000, Available: 1000}},
+		},
+		{
+			name:    "Daily total exceeded",
+			opType:  domain.Withdraw,
+			amount:  301,
+			state:   repo.LimitStateDB{Limits: repo.LimitsDB{MaxDailyWithdraw: limit(1000)}, WithdrawnToday: 700, Balance: repo.BalanceDB{Balance: 1000, Available: 1000}},
+			wantErr: wErr.ErrLimitExceeded,
+		},
+		{
+			name:    "Retained balance counts holds",
+			opType:  domain.Withdraw,
+			amount:  300,
+			state:   repo.LimitStateDB{Limits: repo.LimitsDB{MinBalance: limit(100)}, Balance: repo.BalanceDB{Balance: 1000, Available: 350}},
+			wantErr: wErr.ErrLimitExceeded,
+		},
+		{
+			name:    "Deposit above balance cap",
+			opType:  domain.Deposit,
+			amount:  200,
+			state:   repo.LimitStateDB{Limits: repo.LimitsDB{MaxBalance: limit(1000)}, Balance: repo.BalanceDB{Balance: 900, Available: 900}},
+			wantErr: wErr.ErrLimitExceeded,
+		},
+		{
+			name:   "Withdrawal limits do not apply to deposits",
+			opType: domain.Deposit,
+			amount: 5000,
+			state:  repo.LimitStateDB{Limits: repo.LimitsDB{MaxWithdrawAmount: limit(1), MinBalance: limit(10000)}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.opType == domain.Deposit {
+				mockRepo.EXPECT().Deposit(gomock.Any(), gomock.Any()).DoAndReturn(applyCheck(tt.opType, tt.state))
+			} else {
+				mockRepo.EXPECT().Withdraw(gomock.Any(), gomock.Any()).DoAndReturn(applyCheck(tt.opType, tt.state))
+			}
+
+			_, err := usecase.Operate(context.Background(), w.Wallet{ID: walletID, OperationType: tt.opType, Amount: tt.amount})
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestUsecase_TransferAndCaptureLimits(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := NewMockrepository(ctrl)
+	usecase := w.NewUsecase(mockRepo)
+
+	fromID, toID, holdID := uuid.New(), uuid.New(), uuid.New()
+	daily := repo.LimitsDB{MaxDailyWithdraw: limit(1000)}
+
+	t.Run("Transfer counts towards the daily total", func(t *testing.T) {
+		mockRepo.EXPECT().
+			Transfer(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, tr repo.TransferDB) (repo.TransferResultDB, error) {
+				return repo.TransferResultDB{}, tr.CheckLimits(repo.LimitStateDB{
+					Limits: daily, OperationType: domain.TransferOut, Amount: tr.Amount, WithdrawnToday: 900,
+					Balance: repo.BalanceDB{Balance: 5000, Available: 5000},
+				})
+			})
+
+		_, err := usecase.Transfer(context.Background(), w.Transfer{FromID: fromID, ToID: toID, Amount: 200})
+		assert.ErrorIs(t, err, wErr.ErrLimitExceeded)
+	})
+
+	t.Run("Transfer respects the destination cap", func(t *testing.T) {
+		mockRepo.EXPECT().
+			Transfer(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, tr repo.TransferDB) (repo.TransferResultDB, error) {
+				return repo.TransferResultDB{}, tr.CheckLimits(repo.LimitStateDB{
+					Limits: repo.LimitsDB{MaxBalance: limit(1000)}, OperationType: domain.TransferIn, Amount: tr.Amount,
+					Balance: repo.BalanceDB{Balance: 900, Available: 900},
+				})
+			})
+
+		_, err := usecase.Transfer(context.Background(), w.Transfer{FromID: fromID, ToID: toID, Amount: 200})
+		assert.ErrorIs(t, err, wErr.ErrLimitExceeded)
+	})
+
+	t.Run("Authorize is checked as a withdrawal", func(t *testing.T) {
+		mockRepo.EXPECT().
+			Authorize(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, a repo.AuthorizeDB) (repo.HoldDB, repo.BalanceDB, error) {
+				return repo.HoldDB{}, repo.BalanceDB{}, a.CheckLimits(repo.LimitStateDB{
+					Limits: repo.LimitsDB{MaxWithdrawAmount: limit(100)}, OperationType: domain.Withdraw, Amount: a.Amount,
+					Balance: repo.BalanceDB{Balance: 5000, Available: 5000},
+				})
+			})
+
+		_, err := usecase.Authorize(context.Background(), w.Authorization{WalletID: fromID, Amount: 200})
+		assert.ErrorIs(t, err, wErr.ErrLimitExceeded)
+	})
+
+	t.Run("Capture counts towards the daily total", func(t *testing.T) {
+		mockRepo.EXPECT().
+			Capture(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, c repo.CaptureDB) (repo.HoldDB, repo.BalanceDB, error) {
+				return repo.HoldDB{}, repo.BalanceDB{}, c.CheckLimits(repo.LimitStateDB{
+					Limits: daily, OperationType: domain.Capture, Amount: 300, WithdrawnToday: 800,
+				})
+			})
+
+		_, err := usecase.Capture(context.Background(), holdID, 0)
+		assert.ErrorIs(t, err, wErr.ErrLimitExceeded)
+	})
+
+	t.Run("Capture ignores the retained balance", func(t *testing.T) {
+		mockRepo.EXPECT().
+			Capture(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, c repo.CaptureDB) (repo.HoldDB, repo.BalanceDB, error) {
+				return repo.HoldDB{}, repo.BalanceDB{}, c.CheckLimits(repo.LimitStateDB{
+					Limits: repo.LimitsDB{MinBalance: limit(100)}, OperationType: domain.Capture, Amount: 300,
+					Balance: repo.BalanceDB{Balance: 300, Available: 0},
+				})
+			})
+
+		_, err := usecase.Capture(context.Background(), holdID, 0)
+		assert.NoError(t, err)
+	})
+}
+
+func TestUsecase_SetLimits(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := NewMockrepository(ctrl)
+	usecase := w.NewUsecase(mockRepo)
+
+	walletID := uuid.New()
+
+	tests := []struct {
+		name      string
+		limits    w.Limits
+		mockSetup func()
+		wantErr   error
+	}{
+		{
+			name:   "Overrides stored",
+			limits: w.Limits{MaxWithdrawAmount: limit(500), MinBalance: limit(0)},
+			mockSetup: func() {
+				mockRepo.EXPECT().
+					SetLimits(gomock.Any(), walletID, repo.LimitsDB{MaxWithdrawAmount: limit(500), MinBalance: limit(0)}).
+					Return(repo.LimitsDB{MaxWithdrawAmount: limit(500), MaxDailyWithdraw: limit(10000), MinBalance: limit(0)}, nil)
+			},
+		},
+		{
+			name:   "Wallet not found",
+			limits: w.Limits{},
+			mockSetup: func() {
+				mockRepo.EXPECT().
+					SetLimits(gomock.Any(), walletID, repo.LimitsDB{}).
+					Return(repo.LimitsDB{}, wErr.ErrWalletNotFound)
+			},
+			wantErr: wErr.ErrWalletNotFound,
+		},
+		{
+			name:      "Zero withdrawal limit",
+			limits:    w.Limits{MaxWithdrawAmount: limit(0)},
+			mockSetup: func() {},
+			wantErr:   wErr.ErrInvalidLimits,
+		},
+		{
+			name:      "Negative balance cap",
+			limits:    w.Limits{MaxBalance: limit(-1)},
+			mockSetup: func() {},
+			wantErr:   wErr.ErrInvalidLimits,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+			_, err := usecase.SetLimits(context.Background(), walletID, tt.limits)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
@@ -1,9 +1,113 @@
 package wallet
 
-import "github.com/google/uuid"
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
 
 type Wallet struct {
-	ID            uuid.UUID
+	ID             uuid.UUID
+	OperationType  string
+	Amount         int64
+	Currency       string
+	IdempotencyKey string
+}
+
+// Balance amounts are in minor units of Currency; Exponent is the number of
+// minor-unit digits (2 for RUB, 0 for JPY). Amount is the ledger balance and
+// Available is what remains of it after active holds.
+type Balance struct {
+	Amount    int64
+	Available int64
+	Currency  string
+	Exponent  int
+}
+
+type Transaction struct {
+	ID             uuid.UUID
+	WalletID       uuid.UUID
+	OperationType  string
+	Amount         int64
+	BalanceAfter   int64
+	CounterpartyID uuid.NullUUID
+	ReversalOf     uuid.NullUUID
+	CreatedAt      time.Time
+}
+
+type HistoryFilter struct {
+	WalletID      uuid.UUID
 	OperationType string
-	Amount        int64
+	From          time.Time
+	To            time.Time
+	Cursor        string
+	Limit         int
+}
+
+type HistoryPage struct {
+	Transactions []Transaction
+	NextCursor   string
+}
+
+type Transfer struct {
+	FromID   uuid.UUID
+	ToID     uuid.UUID
+	Amount   int64
+	Currency string
+}
+
+type TransferResult struct {
+	From Balance
+	To   Balance
+}
+
+type Authorization struct {
+	WalletID uuid.UUID
+	Amount   int64
+	Currency string
+	// TTL is optional; the usecase default applies when it is zero.
+	TTL time.Duration
+}
+
+type Hold struct {
+	ID             uuid.UUID
+	WalletID       uuid.UUID
+	Amount         int64
+	CapturedAmount int64
+	Status         string
+	ExpiresAt      time.Time
+	CreatedAt      time.Time
+	Balance        Balance
+}
+
+type NewWallet struct {
+	OwnerID  string
+	Currency string
+	// Metadata is an optional JSON object stored as is.
+	Metadata []byte
+}
+
+type WalletInfo struct {
+	ID        uuid.UUID
+	OwnerID   string
+	Status    string
+	Metadata  []byte
+	Balance   Balance
+	Hot       bool
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+type Reversal struct {
+	Transaction Transaction
+	Balance     Balance
+}
+
+// Limits are in minor units of the wallet currency. A nil field means the
+// limit is not set.
+type Limits struct {
+	MaxWithdrawAmount *int64
+	MaxDailyWithdraw  *int64
+	MaxBalance        *int64
+	MinBalance        *int64
 }
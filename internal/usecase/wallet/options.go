@@ -0,0 +1,61 @@
+package wallet
+
+import (
+	"log/slog"
+	"time"
+)
+
+const (
+	DefaultIdempotencyTTL = 24 * time.Hour
+	DefaultHoldTTL        = 7 * 24 * time.Hour
+	MaxHoldTTL            = 30 * 24 * time.Hour
+)
+
+type Option func(*Usecase)
+
+// WithIdempotencyTTL sets how long an Idempotency-Key is remembered.
+func WithIdempotencyTTL(ttl time.Duration) Option {
+	return func(u *Usecase) {
+		u.idempotencyTTL = ttl
+	}
+}
+
+// WithHoldTTL sets how long an authorization reserves funds when the caller
+// does not ask for a specific duration.
+func WithHoldTTL(ttl time.Duration) Option {
+	return func(u *Usecase) {
+		u.holdTTL = ttl
+	}
+}
+
+// WithImplicitCreate lets a DEPOSIT to an unknown wallet id open that wallet.
+// It is off by default: wallets are created explicitly with Create.
+func WithImplicitCreate(enabled bool) Option {
+	return func(u *Usecase) {
+		u.implicitCreate = enabled
+	}
+}
+
+// WithLogger sets the logger for operation outcomes.
+func WithLogger(l *slog.Logger) Option {
+	return func(u *Usecase) {
+		u.logger = l
+	}
+}
+
+// OperationObserver is told the outcome of every balance-changing operation,
+// with a nil error on success.
+type OperationObserver interface {
+	ObserveOperation(opType string, err error)
+}
+
+type noopObserver struct{}
+
+func (noopObserver) ObserveOperation(string, error) {}
+
+// WithOperationObserver reports operation outcomes to o, typically metrics.
+func WithOperationObserver(o OperationObserver) Option {
+	return func(u *Usecase) {
+		u.observer = o
+	}
+}
@@ -0,0 +1,63 @@
+package wallet
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/totorialman/go-test-ac/internal/domain"
+	walletErrors "github.com/totorialman/go-test-ac/internal/errors/wallet"
+	"github.com/totorialman/go-test-ac/internal/repository/wallet"
+)
+
+// Reverse undoes a DEPOSIT or WITHDRAW by writing a compensating entry linked
+// to the original one. A zero amount reverses whatever is left; a smaller
+// amount is a partial refund, and several partial reversals may follow each
+// other until the original amount is used up.
+func (u *Usecase) Reverse(ctx context.Context, txID uuid.UUID, amount int64) (res Reversal, err error) {
+	defer func() { u.observer.ObserveOperation(domain.Reverse, err) }()
+
+	if amount < 0 {
+		return Reversal{}, walletErrors.ErrInvalidAmount
+	}
+
+	entry, balance, err := u.repo.Reverse(ctx, txID, reversalCheck(amount))
+	if err != nil {
+		return Reversal{}, err
+	}
+
+	return Reversal{
+		Transaction: toTransaction(entry),
+		Balance:     toBalance(balance),
+	}, nil
+}
+
+func reversalCheck(amount int64) wallet.ReversalCheck {
+	return func(s wallet.ReversalStateDB) (int64, error) {
+		switch s.Original.OperationType {
+		case domain.Deposit, domain.Withdraw:
+		default:
+			return 0, walletErrors.ErrNotReversible
+		}
+
+		remaining := s.Original.Amount - s.Reversed
+		if remaining <= 0 {
+			return 0, walletErrors.ErrAlreadyReversed
+		}
+
+		if amount == 0 {
+			amount = remaining
+		}
+		if amount > remaining {
+			return 0, walletErrors.ErrReversalExceedsAmount
+		}
+
+		// Taking a deposit back must not dip into money that is gone or
+		// reserved by holds.
+		if s.Original.OperationType == domain.Deposit && s.Balance.Available < amount {
+			return 0, walletErrors.ErrNotEnoughFunds
+		}
+
+		return amount, nil
+	}
+}
@@ -0,0 +1,144 @@
+package wallet_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/totorialman/go-test-ac/internal/domain"
+	wErr "github.com/totorialman/go-test-ac/internal/errors/wallet"
+	repo "github.com/totorialman/go-test-ac/internal/repository/wallet"
+	w "github.com/totorialman/go-test-ac/internal/usecase/wallet"
+)
+
+func TestUsecase_Reverse(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := NewMockrepository(ctrl)
+	observer := &recordingObserver{}
+	usecase := w.NewUsecase(mockRepo, w.WithOperationObserver(observer))
+
+	walletID := uuid.New()
+	txID := uuid.New()
+
+	deposit := repo.TransactionDB{ID: txID, WalletID: walletID, OperationType: domain.Deposit, Amount: 100}
+	withdraw := repo.TransactionDB{ID: txID, WalletID: walletID, OperationType: domain.Withdraw, Amount: 100}
+
+	// applyCheck makes the mocked repository run the usecase rule against the
+	// given state, the way the real one does under the wallet row lock.
+	applyCheck := func(state repo.ReversalStateDB) func(context.Context, uuid.UUID, repo.ReversalCheck) (repo.TransactionDB, repo.BalanceDB, error) {
+		return func(_ context.Context, _ uuid.UUID, check repo.ReversalCheck) (repo.TransactionDB, repo.BalanceDB, error) {
+			amount, err := check(state)
+			if err != nil {
+				return repo.TransactionDB{}, repo.BalanceDB{}, err
+			}
+			opType := domain.DepositReversal
+			if state.Original.OperationType == domain.Withdraw {
+				opType = domain.WithdrawReversal
+			}
+			return repo.TransactionDB{
+					WalletID:      walletID,
+					OperationType: opType,
+					Amount:        amount,
+					ReversalOf:    uuid.NullUUID{UUID: txID, Valid: true},
+				},
+				repo.BalanceDB{Currency: "RUB"},
+				nil
+		}
+	}
+
+	tests := []struct {
+		name       string
+		amount     int64
+		state      repo.ReversalStateDB
+		wantType   string
+		wantAmount int64
+		wantErr    error
+	}{
+		{
+			name:       "Full deposit reversal",
+			state:      repo.ReversalStateDB{Original: deposit, Balance: repo.BalanceDB{Balance: 150, Available: 150}},
+			wantType:   domain.DepositReversal,
+			wantAmount: 100,
+		},
+		{
+			name:       "Partial refund of a withdrawal",
+			amount:     30,
+			state:      repo.ReversalStateDB{Original: withdraw},
+			wantType:   domain.WithdrawReversal,
+			wantAmount: 30,
+		},
+		{
+			name:       "Remainder after a partial reversal",
+			state:      repo.ReversalStateDB{Original: withdraw, Reversed: 30},
+			wantType:   domain.WithdrawReversal,
+			wantAmount: 70,
+		},
+		{
+			name:    "Already reversed",
+			state:   repo.ReversalStateDB{Original: deposit, Reversed: 100},
+			wantErr: wErr.ErrAlreadyReversed,
+		},
+		{
+			name:    "Exceeds remaining amount",
+			amount:  80,
+			state:   repo.ReversalStateDB{Original: withdraw, Reversed: 30},
+			wantErr: wErr.ErrReversalExceedsAmount,
+		},
+		{
+			name:    "Deposit already spent",
+			state:   repo.ReversalStateDB{Original: deposit, Balance: repo.BalanceDB{Balance: 100, Available: 40}},
+			wantErr: wErr.ErrNotEnoughFunds,
+		},
+		{
+			name:    "Transfer is not reversible",
+			state:   repo.ReversalStateDB{Original: repo.TransactionDB{OperationType: domain.TransferOut, Amount: 100}},
+			wantErr: wErr.ErrNotReversible,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo.EXPECT().
+				Reverse(gomock.Any(), txID, gomock.Any()).
+				DoAndReturn(applyCheck(tt.state))
+
+			res, err := usecase.Reverse(context.Background(), txID, tt.amount)
+			require.NotEmpty(t, observer.ops)
+			last := observer.ops[len(observer.ops)-1]
+			assert.Equal(t, domain.Reverse, last.opType)
+			assert.ErrorIs(t, last.err, tt.wantErr)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantType, res.Transaction.OperationType)
+			assert.Equal(t, tt.wantAmount, res.Transaction.Amount)
+			assert.Equal(t, txID, res.Transaction.ReversalOf.UUID)
+		})
+	}
+
+	t.Run("Frozen wallet", func(t *testing.T) {
+		// The repository refuses it under the wallet row lock.
+		mockRepo.EXPECT().
+			Reverse(gomock.Any(), txID, gomock.Any()).
+			Return(repo.TransactionDB{}, repo.BalanceDB{}, wErr.ErrWalletFrozen)
+
+		_, err := usecase.Reverse(context.Background(), txID, 0)
+		assert.ErrorIs(t, err, wErr.ErrWalletFrozen)
+		last := observer.ops[len(observer.ops)-1]
+		assert.Equal(t, domain.Reverse, last.opType)
+		assert.ErrorIs(t, last.err, wErr.ErrWalletFrozen)
+	})
+
+	t.Run("Negative amount", func(t *testing.T) {
+		_, err := usecase.Reverse(context.Background(), txID, -1)
+		assert.ErrorIs(t, err, wErr.ErrInvalidAmount)
+	})
+}
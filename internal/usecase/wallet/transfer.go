@@ -0,0 +1,42 @@
+package wallet
+
+import (
+	"context"
+
+	"github.com/totorialman/go-test-ac/internal/domain"
+	walletErrors "github.com/totorialman/go-test-ac/internal/errors/wallet"
+	"github.com/totorialman/go-test-ac/internal/repository/wallet"
+)
+
+func (u *Usecase) Transfer(ctx context.Context, t Transfer) (result TransferResult, err error) {
+	defer func() { u.observer.ObserveOperation(domain.TransferOut, err) }()
+
+	if t.Amount <= 0 {
+		return TransferResult{}, walletErrors.ErrInvalidAmount
+	}
+	if t.FromID == t.ToID {
+		return TransferResult{}, walletErrors.ErrSameWallet
+	}
+	if t.Currency != "" {
+		if _, ok := domain.CurrencyExponent(t.Currency); !ok {
+			return TransferResult{}, walletErrors.ErrInvalidCurrency
+		}
+	}
+
+	res, err := u.repo.Transfer(ctx, wallet.TransferDB{
+		FromID:   t.FromID,
+		ToID:     t.ToID,
+		Amount:   t.Amount,
+		Currency: t.Currency,
+
+		CheckLimits: checkLimits,
+	})
+	if err != nil {
+		return TransferResult{}, err
+	}
+
+	return TransferResult{
+		From: toBalance(res.From),
+		To:   toBalance(res.To),
+	}, nil
+}
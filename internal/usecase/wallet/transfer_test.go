@@ -0,0 +1,101 @@
+package wallet_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	wErr "github.com/totorialman/go-test-ac/internal/errors/wallet"
+	repo "github.com/totorialman/go-test-ac/internal/repository/wallet"
+	w "github.com/totorialman/go-test-ac/internal/usecase/wallet"
+)
+
+func TestUsecase_Transfer(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := NewMockrepository(ctrl)
+	usecase := w.NewUsecase(mockRepo)
+
+	fromID := uuid.New()
+	toID := uuid.New()
+
+	tests := []struct {
+		name      string
+		transfer  w.Transfer
+		mockSetup func()
+		want      w.TransferResult
+		wantErr   error
+	}{
+		{
+			name:     "Transfer success",
+			transfer: w.Transfer{FromID: fromID, ToID: toID, Amount: 40},
+			mockSetup: func() {
+				mockRepo.EXPECT().
+					Transfer(gomock.Any(), limitChecked(repo.TransferDB{FromID: fromID, ToID: toID, Amount: 40})).
+					Return(repo.TransferResultDB{
+						From: repo.BalanceDB{Balance: 60, Available: 50, Currency: "RUB"},
+						To:   repo.BalanceDB{Balance: 40, Available: 40, Currency: "RUB"},
+					}, nil)
+			},
+			want: w.TransferResult{
+				From: w.Balance{Amount: 60, Available: 50, Currency: "RUB", Exponent: 2},
+				To:   w.Balance{Amount: 40, Available: 40, Currency: "RUB", Exponent: 2},
+			},
+		},
+		{
+			name:      "Invalid amount",
+			transfer:  w.Transfer{FromID: fromID, ToID: toID, Amount: 0},
+			mockSetup: func() {},
+			wantErr:   wErr.ErrInvalidAmount,
+		},
+		{
+			name:      "Same wallet",
+			transfer:  w.Transfer{FromID: fromID, ToID: fromID, Amount: 10},
+			mockSetup: func() {},
+			wantErr:   wErr.ErrSameWallet,
+		},
+		{
+			name:      "Unknown currency",
+			transfer:  w.Transfer{FromID: fromID, ToID: toID, Amount: 10, Currency: "XXX"},
+			mockSetup: func() {},
+			wantErr:   wErr.ErrInvalidCurrency,
+		},
+		{
+			name:     "Currency mismatch",
+			transfer: w.Transfer{FromID: fromID, ToID: toID, Amount: 10, Currency: "USD"},
+			mockSetup: func() {
+				mockRepo.EXPECT().
+					Transfer(gomock.Any(), limitChecked(repo.TransferDB{FromID: fromID, ToID: toID, Amount: 10, Currency: "USD"})).
+					Return(repo.TransferResultDB{}, wErr.ErrCurrencyMismatch)
+			},
+			wantErr: wErr.ErrCurrencyMismatch,
+		},
+		{
+			name:     "Not enough funds",
+			transfer: w.Transfer{FromID: fromID, ToID: toID, Amount: 1000},
+			mockSetup: func() {
+				mockRepo.EXPECT().
+					Transfer(gomock.Any(), gomock.Any()).
+					Return(repo.TransferResultDB{}, wErr.ErrNotEnoughFunds)
+			},
+			wantErr: wErr.ErrNotEnoughFunds,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+			res, err := usecase.Transfer(context.Background(), tt.transfer)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, res)
+			}
+		})
+	}
+}
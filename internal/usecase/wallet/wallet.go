@@ -2,38 +2,131 @@ package wallet
 
 import (
 	"context"
+	"log/slog"
+	"time"
 
 	"github.com/google/uuid"
-	
+
+	"github.com/totorialman/go-test-ac/internal/domain"
 	walletErrors "github.com/totorialman/go-test-ac/internal/errors/wallet"
 	"github.com/totorialman/go-test-ac/internal/repository/wallet"
-	"github.com/totorialman/go-test-ac/internal/domain"
+	"github.com/totorialman/go-test-ac/internal/tracing"
 )
 
 type Usecase struct {
-	repo repository
+	repo           repository
+	idempotencyTTL time.Duration
+	holdTTL        time.Duration
+	implicitCreate bool
+	observer       OperationObserver
+	logger         *slog.Logger
 }
 
-func NewUsecase(repo repository) *Usecase {
-	return &Usecase{repo: repo}
+func NewUsecase(repo repository, opts ...Option) *Usecase {
+	u := &Usecase{
+		repo:           repo,
+		idempotencyTTL: DefaultIdempotencyTTL,
+		holdTTL:        DefaultHoldTTL,
+		observer:       noopObserver{},
+		logger:         slog.Default(),
+	}
+	for _, opt := range opts {
+		opt(u)
+	}
+	return u
+}
+
+func (u *Usecase) Operate(ctx context.Context, w Wallet) (balance Balance, err error) {
+	ctx, span := tracing.Start(ctx, "wallet.Usecase.Operate",
+		tracing.WalletID(w.ID),
+		tracing.OperationType(w.OperationType),
+	)
+	defer func() { tracing.End(span, err) }()
+
+	defer func() {
+		// Unknown types are rejected below and would only add label values.
+		if w.OperationType == domain.Deposit || w.OperationType == domain.Withdraw {
+			u.observer.ObserveOperation(w.OperationType, err)
+		}
+	}()
+
+	defer func() {
+		if err != nil {
+			u.logger.DebugContext(ctx, "operation rejected",
+				"wallet_id", w.ID,
+				"operation_type", w.OperationType,
+				"amount", w.Amount,
+				"error", err,
+			)
+			return
+		}
+		u.logger.DebugContext(ctx, "operation applied",
+			"wallet_id", w.ID,
+			"operation_type", w.OperationType,
+			"amount", w.Amount,
+			"balance", balance.Amount,
+		)
+	}()
+
+	dbWallet, err := u.walletDB(w)
+	if err != nil {
+		return Balance{}, err
+	}
+
+	var res wallet.BalanceDB
+	if w.OperationType == domain.Deposit {
+		res, err = u.repo.Deposit(ctx, dbWallet)
+	} else {
+		res, err = u.repo.Withdraw(ctx, dbWallet)
+	}
+	if err != nil {
+		return Balance{}, err
+	}
+
+	return toBalance(res), nil
 }
 
-func (u *Usecase) Operate(ctx context.Context, w Wallet) (int64, error) {
-	dbWallet := wallet.WalletDB{
-		ID:     w.ID,
-		Amount: w.Amount,
+// walletDB validates a DEPOSIT or WITHDRAW and builds its repository form.
+func (u *Usecase) walletDB(w Wallet) (wallet.WalletDB, error) {
+	if w.Currency != "" {
+		if _, ok := domain.CurrencyExponent(w.Currency); !ok {
+			return wallet.WalletDB{}, walletErrors.ErrInvalidCurrency
+		}
+	}
+
+	idempotency, err := u.idempotency(w)
+	if err != nil {
+		return wallet.WalletDB{}, err
 	}
 
-	switch w.OperationType {
-	case domain.Deposit:
-		return u.repo.Deposit(ctx, dbWallet)
-	case domain.Withdraw:
-		return u.repo.Withdraw(ctx, dbWallet)
-	default:
-		return 0, walletErrors.ErrInvalidOperation
+	if w.OperationType != domain.Deposit && w.OperationType != domain.Withdraw {
+		return wallet.WalletDB{}, walletErrors.ErrInvalidOperation
 	}
+
+	return wallet.WalletDB{
+		ID:              w.ID,
+		Amount:          w.Amount,
+		Currency:        w.Currency,
+		Idempotency:     idempotency,
+		CheckLimits:     checkLimits,
+		CreateIfMissing: u.implicitCreate && w.OperationType == domain.Deposit,
+	}, nil
 }
 
-func (u *Usecase) Balance(ctx context.Context, id uuid.UUID) (int64, error) {
-	return u.repo.GetBalance(ctx, id)
+func (u *Usecase) Balance(ctx context.Context, id uuid.UUID) (Balance, error) {
+	res, err := u.repo.GetBalance(ctx, id)
+	if err != nil {
+		return Balance{}, err
+	}
+	return toBalance(res), nil
+}
+
+func toBalance(b wallet.BalanceDB) Balance {
+	exp, _ := domain.CurrencyExponent(b.Currency)
+	return Balance{
+		Amount:    b.Balance,
+		Available: b.Available,
+		Currency:  b.Currency,
+		Exponent:  exp,
+	}
 }
@@ -36,11 +36,73 @@ func (m *Mockrepository) EXPECT() *MockrepositoryMockRecorder {
 	return m.recorder
 }
 
+// Authorize mocks base method.
+func (m *Mockrepository) Authorize(ctx context.Context, a wallet.AuthorizeDB) (wallet.HoldDB, wallet.BalanceDB, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Authorize", ctx, a)
+	ret0, _ := ret[0].(wallet.HoldDB)
+	ret1, _ := ret[1].(wallet.BalanceDB)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Authorize indicates an expected call of Authorize.
+func (mr *MockrepositoryMockRecorder) Authorize(ctx, a interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Authorize", reflect.TypeOf((*Mockrepository)(nil).Authorize), ctx, a)
+}
+
+// Batch mocks base method.
+func (m *Mockrepository) Batch(ctx context.Context, ops []wallet.OperationDB) ([]wallet.BalanceDB, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Batch", ctx, ops)
+	ret0, _ := ret[0].([]wallet.BalanceDB)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Batch indicates an expected call of Batch.
+func (mr *MockrepositoryMockRecorder) Batch(ctx, ops interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Batch", reflect.TypeOf((*Mockrepository)(nil).Batch), ctx, ops)
+}
+
+// Capture mocks base method.
+func (m *Mockrepository) Capture(ctx context.Context, c wallet.CaptureDB) (wallet.HoldDB, wallet.BalanceDB, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Capture", ctx, c)
+	ret0, _ := ret[0].(wallet.HoldDB)
+	ret1, _ := ret[1].(wallet.BalanceDB)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Capture indicates an expected call of Capture.
+func (mr *MockrepositoryMockRecorder) Capture(ctx, c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Capture", reflect.TypeOf((*Mockrepository)(nil).Capture), ctx, c)
+}
+
+// Create mocks base method.
+func (m *Mockrepository) Create(ctx context.Context, c wallet.CreateWalletDB) (wallet.WalletInfoDB, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, c)
+	ret0, _ := ret[0].(wallet.WalletInfoDB)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockrepositoryMockRecorder) Create(ctx, c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*Mockrepository)(nil).Create), ctx, c)
+}
+
 // Deposit mocks base method.
-func (m *Mockrepository) Deposit(ctx context.Context, w wallet.WalletDB) (int64, error) {
+func (m *Mockrepository) Deposit(ctx context.Context, w wallet.WalletDB) (wallet.BalanceDB, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "Deposit", ctx, w)
-	ret0, _ := ret[0].(int64)
+	ret0, _ := ret[0].(wallet.BalanceDB)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -52,10 +114,10 @@ func (mr *MockrepositoryMockRecorder) Deposit(ctx, w interface{}) *gomock.Call {
 }
 
 // GetBalance mocks base method.
-func (m *Mockrepository) GetBalance(ctx context.Context, id uuid.UUID) (int64, error) {
+func (m *Mockrepository) GetBalance(ctx context.Context, id uuid.UUID) (wallet.BalanceDB, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetBalance", ctx, id)
-	ret0, _ := ret[0].(int64)
+	ret0, _ := ret[0].(wallet.BalanceDB)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -66,11 +128,148 @@ func (mr *MockrepositoryMockRecorder) GetBalance(ctx, id interface{}) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBalance", reflect.TypeOf((*Mockrepository)(nil).GetBalance), ctx, id)
 }
 
+// GetLimits mocks base method.
+func (m *Mockrepository) GetLimits(ctx context.Context, id uuid.UUID) (wallet.LimitsDB, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLimits", ctx, id)
+	ret0, _ := ret[0].(wallet.LimitsDB)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLimits indicates an expected call of GetLimits.
+func (mr *MockrepositoryMockRecorder) GetLimits(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLimits", reflect.TypeOf((*Mockrepository)(nil).GetLimits), ctx, id)
+}
+
+// GetWallet mocks base method.
+func (m *Mockrepository) GetWallet(ctx context.Context, id uuid.UUID) (wallet.WalletInfoDB, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWallet", ctx, id)
+	ret0, _ := ret[0].(wallet.WalletInfoDB)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWallet indicates an expected call of GetWallet.
+func (mr *MockrepositoryMockRecorder) GetWallet(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWallet", reflect.TypeOf((*Mockrepository)(nil).GetWallet), ctx, id)
+}
+
+// History mocks base method.
+func (m *Mockrepository) History(ctx context.Context, f wallet.HistoryFilterDB) ([]wallet.TransactionDB, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "History", ctx, f)
+	ret0, _ := ret[0].([]wallet.TransactionDB)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// History indicates an expected call of History.
+func (mr *MockrepositoryMockRecorder) History(ctx, f interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "History", reflect.TypeOf((*Mockrepository)(nil).History), ctx, f)
+}
+
+// Reverse mocks base method.
+func (m *Mockrepository) Reverse(ctx context.Context, txID uuid.UUID, check wallet.ReversalCheck) (wallet.TransactionDB, wallet.BalanceDB, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Reverse", ctx, txID, check)
+	ret0, _ := ret[0].(wallet.TransactionDB)
+	ret1, _ := ret[1].(wallet.BalanceDB)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Reverse indicates an expected call of Reverse.
+func (mr *MockrepositoryMockRecorder) Reverse(ctx, txID, check interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reverse", reflect.TypeOf((*Mockrepository)(nil).Reverse), ctx, txID, check)
+}
+
+// SetHot mocks base method.
+func (m *Mockrepository) SetHot(ctx context.Context, id uuid.UUID, hot bool) (wallet.WalletInfoDB, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetHot", ctx, id, hot)
+	ret0, _ := ret[0].(wallet.WalletInfoDB)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetHot indicates an expected call of SetHot.
+func (mr *MockrepositoryMockRecorder) SetHot(ctx, id, hot interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetHot", reflect.TypeOf((*Mockrepository)(nil).SetHot), ctx, id, hot)
+}
+
+// SetLimits mocks base method.
+func (m *Mockrepository) SetLimits(ctx context.Context, id uuid.UUID, l wallet.LimitsDB) (wallet.LimitsDB, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetLimits", ctx, id, l)
+	ret0, _ := ret[0].(wallet.LimitsDB)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetLimits indicates an expected call of SetLimits.
+func (mr *MockrepositoryMockRecorder) SetLimits(ctx, id, l interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetLimits", reflect.TypeOf((*Mockrepository)(nil).SetLimits), ctx, id, l)
+}
+
+// Transfer mocks base method.
+func (m *Mockrepository) Transfer(ctx context.Context, t wallet.TransferDB) (wallet.TransferResultDB, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Transfer", ctx, t)
+	ret0, _ := ret[0].(wallet.TransferResultDB)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Transfer indicates an expected call of Transfer.
+func (mr *MockrepositoryMockRecorder) Transfer(ctx, t interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Transfer", reflect.TypeOf((*Mockrepository)(nil).Transfer), ctx, t)
+}
+
+// UpdateStatus mocks base method.
+func (m *Mockrepository) UpdateStatus(ctx context.Context, id uuid.UUID, status string) (wallet.WalletInfoDB, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateStatus", ctx, id, status)
+	ret0, _ := ret[0].(wallet.WalletInfoDB)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateStatus indicates an expected call of UpdateStatus.
+func (mr *MockrepositoryMockRecorder) UpdateStatus(ctx, id, status interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateStatus", reflect.TypeOf((*Mockrepository)(nil).UpdateStatus), ctx, id, status)
+}
+
+// Void mocks base method.
+func (m *Mockrepository) Void(ctx context.Context, holdID uuid.UUID) (wallet.HoldDB, wallet.BalanceDB, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Void", ctx, holdID)
+	ret0, _ := ret[0].(wallet.HoldDB)
+	ret1, _ := ret[1].(wallet.BalanceDB)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Void indicates an expected call of Void.
+func (mr *MockrepositoryMockRecorder) Void(ctx, holdID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Void", reflect.TypeOf((*Mockrepository)(nil).Void), ctx, holdID)
+}
+
 // Withdraw mocks base method.
-func (m *Mockrepository) Withdraw(ctx context.Context, w wallet.WalletDB) (int64, error) {
+func (m *Mockrepository) Withdraw(ctx context.Context, w wallet.WalletDB) (wallet.BalanceDB, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "Withdraw", ctx, w)
-	ret0, _ := ret[0].(int64)
+	ret0, _ := ret[0].(wallet.BalanceDB)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
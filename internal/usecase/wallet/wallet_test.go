@@ -2,7 +2,11 @@ package wallet_test
 
 import (
 	"context"
+	"fmt"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/google/uuid"
@@ -39,8 +43,8 @@ func TestUsecase_Operate(t *testing.T) {
 			},
 			mockSetup: func() {
 				mockRepo.EXPECT().
-					Deposit(gomock.Any(), repo.WalletDB{ID: userID, Amount: 100}).
-					Return(int64(150), nil)
+					Deposit(gomock.Any(), walletDB(repo.WalletDB{ID: userID, Amount: 100})).
+					Return(repo.BalanceDB{Balance: 150, Currency: "RUB"}, nil)
 			},
 			wantBalance: 150,
 			wantErr:     nil,
@@ -54,8 +58,8 @@ func TestUsecase_Operate(t *testing.T) {
 			},
 			mockSetup: func() {
 				mockRepo.EXPECT().
-					Withdraw(gomock.Any(), repo.WalletDB{ID: userID, Amount: 50}).
-					Return(int64(50), nil)
+					Withdraw(gomock.Any(), walletDB(repo.WalletDB{ID: userID, Amount: 50})).
+					Return(repo.BalanceDB{Balance: 50, Currency: "RUB"}, nil)
 			},
 			wantBalance: 50,
 			wantErr:     nil,
@@ -71,6 +75,46 @@ func TestUsecase_Operate(t *testing.T) {
 			wantBalance: 0,
 			wantErr:     wErr.ErrInvalidOperation,
 		},
+		{
+			name: "Idempotency key too long",
+			wallet: w.Wallet{
+				ID:             userID,
+				OperationType:  domain.Deposit,
+				Amount:         10,
+				IdempotencyKey: strings.Repeat("k", 256),
+			},
+			mockSetup:   func() {},
+			wantBalance: 0,
+			wantErr:     wErr.ErrInvalidIdempotencyKey,
+		},
+		{
+			name: "Unknown currency",
+			wallet: w.Wallet{
+				ID:            userID,
+				OperationType: domain.Deposit,
+				Amount:        10,
+				Currency:      "XXX",
+			},
+			mockSetup:   func() {},
+			wantBalance: 0,
+			wantErr:     wErr.ErrInvalidCurrency,
+		},
+		{
+			name: "Withdraw currency mismatch",
+			wallet: w.Wallet{
+				ID:            userID,
+				OperationType: domain.Withdraw,
+				Amount:        10,
+				Currency:      "USD",
+			},
+			mockSetup: func() {
+				mockRepo.EXPECT().
+					Withdraw(gomock.Any(), walletDB(repo.WalletDB{ID: userID, Amount: 10, Currency: "USD"})).
+					Return(repo.BalanceDB{}, wErr.ErrCurrencyMismatch)
+			},
+			wantBalance: 0,
+			wantErr:     wErr.ErrCurrencyMismatch,
+		},
 		{
 			name: "Withdraw not enough funds",
 			wallet: w.Wallet{
@@ -80,8 +124,8 @@ func TestUsecase_Operate(t *testing.T) {
 			},
 			mockSetup: func() {
 				mockRepo.EXPECT().
-					Withdraw(gomock.Any(), repo.WalletDB{ID: userID, Amount: 200}).
-					Return(int64(0), wErr.ErrNotEnoughFunds)
+					Withdraw(gomock.Any(), walletDB(repo.WalletDB{ID: userID, Amount: 200})).
+					Return(repo.BalanceDB{}, wErr.ErrNotEnoughFunds)
 			},
 			wantBalance: 0,
 			wantErr:     wErr.ErrNotEnoughFunds,
@@ -96,7 +140,7 @@ func TestUsecase_Operate(t *testing.T) {
 				assert.ErrorIs(t, err, tt.wantErr)
 			} else {
 				assert.NoError(t, err)
-				assert.Equal(t, tt.wantBalance, balance)
+				assert.Equal(t, tt.wantBalance, balance.Amount)
 			}
 		})
 	}
@@ -120,7 +164,7 @@ func TestUsecase_Balance(t *testing.T) {
 		{
 			name: "Get balance success",
 			mockSetup: func() {
-				mockRepo.EXPECT().GetBalance(gomock.Any(), userID).Return(int64(100), nil)
+				mockRepo.EXPECT().GetBalance(gomock.Any(), userID).Return(repo.BalanceDB{Balance: 100, Currency: "JPY"}, nil)
 			},
 			wantBalance: 100,
 			wantErr:     nil,
@@ -128,7 +172,7 @@ func TestUsecase_Balance(t *testing.T) {
 		{
 			name: "Wallet not found",
 			mockSetup: func() {
-				mockRepo.EXPECT().GetBalance(gomock.Any(), userID).Return(int64(0), wErr.ErrWalletNotFound)
+				mockRepo.EXPECT().GetBalance(gomock.Any(), userID).Return(repo.BalanceDB{}, wErr.ErrWalletNotFound)
 			},
 			wantBalance: 0,
 			wantErr:     wErr.ErrWalletNotFound,
@@ -143,8 +187,156 @@ func TestUsecase_Balance(t *testing.T) {
 				assert.ErrorIs(t, err, tt.wantErr)
 			} else {
 				assert.NoError(t, err)
-				assert.Equal(t, tt.wantBalance, balance)
+				assert.Equal(t, tt.wantBalance, balance.Amount)
 			}
 		})
 	}
 }
+
+func TestUsecase_OperateImplicitCreate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := NewMockrepository(ctrl)
+	userID := uuid.New()
+
+	mockRepo.EXPECT().
+		Deposit(gomock.Any(), walletDB(repo.WalletDB{ID: userID, Amount: 10, CreateIfMissing: true})).
+		Return(repo.BalanceDB{Balance: 10, Available: 10, Currency: "RUB"}, nil)
+	mockRepo.EXPECT().
+		Withdraw(gomock.Any(), walletDB(repo.WalletDB{ID: userID, Amount: 10})).
+		Return(repo.BalanceDB{Currency: "RUB"}, nil)
+	mockRepo.EXPECT().
+		Deposit(gomock.Any(), walletDB(repo.WalletDB{ID: userID, Amount: 10})).
+		Return(repo.BalanceDB{}, wErr.ErrWalletNotFound)
+
+	enabled := w.NewUsecase(mockRepo, w.WithImplicitCreate(true))
+	_, err := enabled.Operate(context.Background(), w.Wallet{ID: userID, OperationType: domain.Deposit, Amount: 10})
+	assert.NoError(t, err)
+	_, err = enabled.Operate(context.Background(), w.Wallet{ID: userID, OperationType: domain.Withdraw, Amount: 10})
+	assert.NoError(t, err)
+
+	disabled := w.NewUsecase(mockRepo)
+	_, err = disabled.Operate(context.Background(), w.Wallet{ID: userID, OperationType: domain.Deposit, Amount: 10})
+	assert.ErrorIs(t, err, wErr.ErrWalletNotFound)
+}
+
+func TestUsecase_BalanceDetails(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := NewMockrepository(ctrl)
+	usecase := w.NewUsecase(mockRepo)
+
+	userID := uuid.New()
+	mockRepo.EXPECT().GetBalance(gomock.Any(), userID).Return(repo.BalanceDB{Balance: 1500, Available: 1000, Currency: "KWD"}, nil)
+
+	balance, err := usecase.Balance(context.Background(), userID)
+	assert.NoError(t, err)
+	assert.Equal(t, w.Balance{Amount: 1500, Available: 1000, Currency: "KWD", Exponent: 3}, balance)
+}
+
+func TestUsecase_OperateIdempotency(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := NewMockrepository(ctrl)
+	usecase := w.NewUsecase(mockRepo, w.WithIdempotencyTTL(time.Hour))
+
+	userID := uuid.New()
+
+	var first, second repo.WalletDB
+	gomock.InOrder(
+		mockRepo.EXPECT().Deposit(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, db repo.WalletDB) (repo.BalanceDB, error) {
+				first = db
+				return repo.BalanceDB{Balance: 100, Currency: "RUB"}, nil
+			}),
+		mockRepo.EXPECT().Deposit(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, db repo.WalletDB) (repo.BalanceDB, error) {
+				second = db
+				return repo.BalanceDB{}, wErr.ErrIdempotencyKeyReused
+			}),
+	)
+
+	_, err := usecase.Operate(context.Background(), w.Wallet{ID: userID, OperationType: domain.Deposit, Amount: 100, IdempotencyKey: "key-1"})
+	assert.NoError(t, err)
+	_, err = usecase.Operate(context.Background(), w.Wallet{ID: userID, OperationType: domain.Deposit, Amount: 200, IdempotencyKey: "key-1"})
+	assert.ErrorIs(t, err, wErr.ErrIdempotencyKeyReused)
+
+	assert.Equal(t, "key-1", first.Idempotency.Key)
+	assert.Equal(t, time.Hour, first.Idempotency.TTL)
+	assert.NotEmpty(t, first.Idempotency.RequestHash)
+	assert.NotEqual(t, first.Idempotency.RequestHash, second.Idempotency.RequestHash)
+}
+
+// limitCheckedMatcher compares a repository argument by value, ignoring its
+// CheckLimits function, which only has to be present.
+type limitCheckedMatcher struct {
+	want any
+}
+
+func walletDB(want repo.WalletDB) gomock.Matcher {
+	return limitCheckedMatcher{want: want}
+}
+
+func limitChecked(want any) gomock.Matcher {
+	return limitCheckedMatcher{want: want}
+}
+
+func (m limitCheckedMatcher) Matches(x interface{}) bool {
+	if reflect.TypeOf(x) != reflect.TypeOf(m.want) {
+		return false
+	}
+	got := reflect.New(reflect.TypeOf(x)).Elem()
+	got.Set(reflect.ValueOf(x))
+	check := got.FieldByName("CheckLimits")
+	if check.IsNil() {
+		return false
+	}
+	check.SetZero()
+	return reflect.DeepEqual(got.Interface(), m.want)
+}
+
+func (m limitCheckedMatcher) String() string {
+	return fmt.Sprintf("is %+v with a limit check", m.want)
+}
+
+type recordedOperation struct {
+	opType string
+	err    error
+}
+
+type recordingObserver struct {
+	ops []recordedOperation
+}
+
+func (o *recordingObserver) ObserveOperation(opType string, err error) {
+	o.ops = append(o.ops, recordedOperation{opType: opType, err: err})
+}
+
+func TestUsecase_OperateObserver(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := NewMockrepository(ctrl)
+	observer := &recordingObserver{}
+	usecase := w.NewUsecase(mockRepo, w.WithOperationObserver(observer))
+
+	userID := uuid.New()
+	mockRepo.EXPECT().
+		Deposit(gomock.Any(), gomock.Any()).
+		Return(repo.BalanceDB{Balance: 10, Available: 10, Currency: "RUB"}, nil)
+	mockRepo.EXPECT().
+		Withdraw(gomock.Any(), gomock.Any()).
+		Return(repo.BalanceDB{}, wErr.ErrNotEnoughFunds)
+
+	_, _ = usecase.Operate(context.Background(), w.Wallet{ID: userID, OperationType: domain.Deposit, Amount: 10})
+	_, _ = usecase.Operate(context.Background(), w.Wallet{ID: userID, OperationType: domain.Withdraw, Amount: 100})
+	_, _ = usecase.Operate(context.Background(), w.Wallet{ID: userID, OperationType: "REFUND", Amount: 100})
+
+	assert.Equal(t, []recordedOperation{
+		{opType: domain.Deposit},
+		{opType: domain.Withdraw, err: wErr.ErrNotEnoughFunds},
+	}, observer.ops)
+}
@@ -0,0 +1,18 @@
+//go:generate mockgen -source=contract.go -destination=webhook_repository_mocks_test.go -package=webhook_test
+package webhook
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/totorialman/go-test-ac/internal/repository/webhook"
+)
+
+type repository interface {
+	CreateSubscription(ctx context.Context, s webhook.SubscriptionDB) (webhook.SubscriptionDB, error)
+	ListSubscriptions(ctx context.Context) ([]webhook.SubscriptionDB, error)
+	DeleteSubscription(ctx context.Context, id uuid.UUID) error
+	ListDeliveries(ctx context.Context, f webhook.DeliveryFilterDB) ([]webhook.DeliveryDB, error)
+	Replay(ctx context.Context, id uuid.UUID) (webhook.DeliveryDB, error)
+}
@@ -0,0 +1,44 @@
+package webhook
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type NewSubscription struct {
+	URL string
+	// WalletID and EventType narrow the subscription; zero values match all.
+	WalletID  uuid.NullUUID
+	EventType string
+}
+
+type Subscription struct {
+	ID        uuid.UUID
+	URL       string
+	WalletID  uuid.NullUUID
+	EventType string
+	CreatedAt time.Time
+	// Secret is only filled in when the subscription is created.
+	Secret string
+}
+
+type DeliveryFilter struct {
+	// Status defaults to DEAD: the dead-letter list.
+	Status string
+	Limit  int
+}
+
+type Delivery struct {
+	ID             uuid.UUID
+	SubscriptionID uuid.UUID
+	EventID        uuid.UUID
+	EventType      string
+	Payload        []byte
+	Status         string
+	Attempts       int
+	NextAttemptAt  time.Time
+	LastError      string
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
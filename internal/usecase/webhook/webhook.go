@@ -0,0 +1,149 @@
+package webhook
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/url"
+
+	"github.com/google/uuid"
+
+	"github.com/totorialman/go-test-ac/internal/domain"
+	webhookErrors "github.com/totorialman/go-test-ac/internal/errors/webhook"
+	"github.com/totorialman/go-test-ac/internal/repository/webhook"
+)
+
+const (
+	DefaultDeliveriesLimit = 50
+	MaxDeliveriesLimit     = 500
+
+	secretBytes = 32
+)
+
+type Usecase struct {
+	repo repository
+}
+
+func NewUsecase(repo repository) *Usecase {
+	return &Usecase{repo: repo}
+}
+
+// Register creates a subscription with a freshly generated signing secret.
+// The secret is returned only here.
+func (u *Usecase) Register(ctx context.Context, s NewSubscription) (Subscription, error) {
+	parsed, err := url.Parse(s.URL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return Subscription{}, webhookErrors.ErrInvalidURL
+	}
+	if s.EventType != "" && !domain.IsEventType(s.EventType) {
+		return Subscription{}, webhookErrors.ErrInvalidEventType
+	}
+
+	secret, err := newSecret()
+	if err != nil {
+		return Subscription{}, err
+	}
+
+	res, err := u.repo.CreateSubscription(ctx, webhook.SubscriptionDB{
+		URL:       s.URL,
+		Secret:    secret,
+		WalletID:  s.WalletID,
+		EventType: s.EventType,
+	})
+	if err != nil {
+		return Subscription{}, err
+	}
+
+	sub := toSubscription(res)
+	sub.Secret = res.Secret
+	return sub, nil
+}
+
+func (u *Usecase) ListSubscriptions(ctx context.Context) ([]Subscription, error) {
+	rows, err := u.repo.ListSubscriptions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]Subscription, 0, len(rows))
+	for _, s := range rows {
+		res = append(res, toSubscription(s))
+	}
+	return res, nil
+}
+
+func (u *Usecase) DeleteSubscription(ctx context.Context, id uuid.UUID) error {
+	return u.repo.DeleteSubscription(ctx, id)
+}
+
+func (u *Usecase) ListDeliveries(ctx context.Context, f DeliveryFilter) ([]Delivery, error) {
+	if f.Status == "" {
+		f.Status = domain.DeliveryDead
+	}
+	switch f.Status {
+	case domain.DeliveryPending, domain.DeliveryDelivered, domain.DeliveryDead:
+	default:
+		return nil, webhookErrors.ErrInvalidDeliveryFilter
+	}
+
+	if f.Limit == 0 {
+		f.Limit = DefaultDeliveriesLimit
+	}
+	if f.Limit < 0 || f.Limit > MaxDeliveriesLimit {
+		return nil, webhookErrors.ErrInvalidDeliveryFilter
+	}
+
+	rows, err := u.repo.ListDeliveries(ctx, webhook.DeliveryFilterDB{Status: f.Status, Limit: f.Limit})
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]Delivery, 0, len(rows))
+	for _, d := range rows {
+		res = append(res, toDelivery(d))
+	}
+	return res, nil
+}
+
+// Replay queues a dead delivery again with a fresh attempt budget.
+func (u *Usecase) Replay(ctx context.Context, id uuid.UUID) (Delivery, error) {
+	res, err := u.repo.Replay(ctx, id)
+	if err != nil {
+		return Delivery{}, err
+	}
+	return toDelivery(res), nil
+}
+
+func newSecret() (string, error) {
+	b := make([]byte, secretBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func toSubscription(s webhook.SubscriptionDB) Subscription {
+	return Subscription{
+		ID:        s.ID,
+		URL:       s.URL,
+		WalletID:  s.WalletID,
+		EventType: s.EventType,
+		CreatedAt: s.CreatedAt,
+	}
+}
+
+func toDelivery(d webhook.DeliveryDB) Delivery {
+	return Delivery{
+		ID:             d.ID,
+		SubscriptionID: d.SubscriptionID,
+		EventID:        d.EventID,
+		EventType:      d.EventType,
+		Payload:        d.Payload,
+		Status:         d.Status,
+		Attempts:       d.Attempts,
+		NextAttemptAt:  d.NextAttemptAt,
+		LastError:      d.LastError,
+		CreatedAt:      d.CreatedAt,
+		UpdatedAt:      d.UpdatedAt,
+	}
+}
@@ -0,0 +1,154 @@
+package webhook_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/totorialman/go-test-ac/internal/domain"
+	whErr "github.com/totorialman/go-test-ac/internal/errors/webhook"
+	repo "github.com/totorialman/go-test-ac/internal/repository/webhook"
+	wh "github.com/totorialman/go-test-ac/internal/usecase/webhook"
+)
+
+func TestUsecase_Register(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := NewMockrepository(ctrl)
+	usecase := wh.NewUsecase(mockRepo)
+
+	walletID := uuid.NullUUID{UUID: uuid.New(), Valid: true}
+	subID := uuid.New()
+
+	tests := []struct {
+		name      string
+		sub       wh.NewSubscription
+		mockSetup func()
+		wantErr   error
+	}{
+		{
+			name: "Per wallet and event type",
+			sub:  wh.NewSubscription{URL: "https://example.com/hook", WalletID: walletID, EventType: domain.EventBalanceChanged},
+			mockSetup: func() {
+				mockRepo.EXPECT().CreateSubscription(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, s repo.SubscriptionDB) (repo.SubscriptionDB, error) {
+						assert.Equal(t, "https://example.com/hook", s.URL)
+						assert.Equal(t, walletID, s.WalletID)
+						assert.Len(t, s.Secret, 64)
+						s.ID = subID
+						return s, nil
+					})
+			},
+		},
+		{
+			name:      "Not http",
+			sub:       wh.NewSubscription{URL: "ftp://example.com/hook"},
+			mockSetup: func() {},
+			wantErr:   whErr.ErrInvalidURL,
+		},
+		{
+			name:      "Relative url",
+			sub:       wh.NewSubscription{URL: "/hook"},
+			mockSetup: func() {},
+			wantErr:   whErr.ErrInvalidURL,
+		},
+		{
+			name:      "Unknown event type",
+			sub:       wh.NewSubscription{URL: "https://example.com/hook", EventType: "wallet.deleted"},
+			mockSetup: func() {},
+			wantErr:   whErr.ErrInvalidEventType,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+			sub, err := usecase.Register(context.Background(), tt.sub)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, subID, sub.ID)
+			assert.NotEmpty(t, sub.Secret)
+		})
+	}
+}
+
+func TestUsecase_ListSubscriptionsHidesSecret(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := NewMockrepository(ctrl)
+	usecase := wh.NewUsecase(mockRepo)
+
+	mockRepo.EXPECT().ListSubscriptions(gomock.Any()).
+		Return([]repo.SubscriptionDB{{ID: uuid.New(), URL: "https://example.com", Secret: "s3cret"}}, nil)
+
+	subs, err := usecase.ListSubscriptions(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, subs, 1)
+	assert.Empty(t, subs[0].Secret)
+}
+
+func TestUsecase_ListDeliveries(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := NewMockrepository(ctrl)
+	usecase := wh.NewUsecase(mockRepo)
+
+	tests := []struct {
+		name      string
+		filter    wh.DeliveryFilter
+		mockSetup func()
+		wantErr   error
+	}{
+		{
+			name:   "Dead letters by default",
+			filter: wh.DeliveryFilter{},
+			mockSetup: func() {
+				mockRepo.EXPECT().
+					ListDeliveries(gomock.Any(), repo.DeliveryFilterDB{Status: domain.DeliveryDead, Limit: wh.DefaultDeliveriesLimit}).
+					Return(nil, nil)
+			},
+		},
+		{
+			name:   "Pending",
+			filter: wh.DeliveryFilter{Status: domain.DeliveryPending, Limit: 10},
+			mockSetup: func() {
+				mockRepo.EXPECT().
+					ListDeliveries(gomock.Any(), repo.DeliveryFilterDB{Status: domain.DeliveryPending, Limit: 10}).
+					Return(nil, nil)
+			},
+		},
+		{
+			name:      "Unknown status",
+			filter:    wh.DeliveryFilter{Status: "LOST"},
+			mockSetup: func() {},
+			wantErr:   whErr.ErrInvalidDeliveryFilter,
+		},
+		{
+			name:      "Limit too large",
+			filter:    wh.DeliveryFilter{Limit: wh.MaxDeliveriesLimit + 1},
+			mockSetup: func() {},
+			wantErr:   whErr.ErrInvalidDeliveryFilter,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+			_, err := usecase.ListDeliveries(context.Background(), tt.filter)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,19 @@
+//go:generate mockgen -source=contract.go -destination=webhook_mocks_test.go -package=webhook_test
+package webhook
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/totorialman/go-test-ac/internal/repository/webhook"
+)
+
+type store interface {
+	Enqueue(ctx context.Context, e webhook.EnqueueDB) (int64, error)
+	ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]webhook.DeliveryDB, error)
+	MarkDelivered(ctx context.Context, id uuid.UUID, attempt int) error
+	Reschedule(ctx context.Context, id uuid.UUID, attempt int, delay time.Duration, reason string) error
+	MarkDead(ctx context.Context, id uuid.UUID, attempt int, reason string) error
+}
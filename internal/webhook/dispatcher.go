@@ -0,0 +1,204 @@
+// Package webhook delivers outbox events to subscribers' URLs as signed HTTP
+// POST requests.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	webhookErrors "github.com/totorialman/go-test-ac/internal/errors/webhook"
+	"github.com/totorialman/go-test-ac/internal/repository/webhook"
+)
+
+const (
+	DefaultMaxAttempts  = 8
+	DefaultBaseBackoff  = 10 * time.Second
+	DefaultMaxBackoff   = time.Hour
+	DefaultBatchSize    = 50
+	DefaultPollInterval = time.Second
+	DefaultTimeout      = 10 * time.Second
+
+	// The lease must outlast sending the whole batch, one request after
+	// another, so that another dispatcher does not pick a delivery up while
+	// it is still waiting its turn or in flight.
+	leaseMargin = 5 * time.Second
+)
+
+type Dispatcher struct {
+	store        store
+	client       *http.Client
+	maxAttempts  int
+	baseBackoff  time.Duration
+	maxBackoff   time.Duration
+	batchSize    int
+	pollInterval time.Duration
+	now          func() time.Time
+	logger       *slog.Logger
+}
+
+type Option func(*Dispatcher)
+
+// WithHTTPClient sets the client used for deliveries. Its Timeout bounds
+// each attempt.
+func WithHTTPClient(c *http.Client) Option {
+	return func(d *Dispatcher) {
+		d.client = c
+	}
+}
+
+// WithMaxAttempts sets after how many failed attempts a delivery is moved to
+// the dead-letter list.
+func WithMaxAttempts(n int) Option {
+	return func(d *Dispatcher) {
+		d.maxAttempts = n
+	}
+}
+
+// WithBackoff sets the delay after the first failure and its upper bound;
+// the delay doubles after every further failure.
+func WithBackoff(base, max time.Duration) Option {
+	return func(d *Dispatcher) {
+		d.baseBackoff = base
+		d.maxBackoff = max
+	}
+}
+
+// WithPollInterval sets how long the dispatcher sleeps when nothing is due.
+func WithPollInterval(interval time.Duration) Option {
+	return func(d *Dispatcher) {
+		d.pollInterval = interval
+	}
+}
+
+// WithLogger sets the logger for failed and dead deliveries.
+func WithLogger(l *slog.Logger) Option {
+	return func(d *Dispatcher) {
+		d.logger = l
+	}
+}
+
+func NewDispatcher(store store, opts ...Option) *Dispatcher {
+	d := &Dispatcher{
+		store:        store,
+		client:       &http.Client{Timeout: DefaultTimeout},
+		maxAttempts:  DefaultMaxAttempts,
+		baseBackoff:  DefaultBaseBackoff,
+		maxBackoff:   DefaultMaxBackoff,
+		batchSize:    DefaultBatchSize,
+		pollInterval: DefaultPollInterval,
+		now:          time.Now,
+		logger:       slog.Default(),
+	}
+	for _, opt := range opts {
+		opt(d)
+	}
+	return d
+}
+
+// Run dispatches due deliveries until ctx is cancelled.
+func (d *Dispatcher) Run(ctx context.Context) {
+	for {
+		n, err := d.DispatchOnce(ctx)
+		if err != nil {
+			d.logger.ErrorContext(ctx, "webhook dispatch error", "error", err)
+		}
+		if err == nil && n == d.batchSize {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(d.pollInterval):
+		}
+	}
+}
+
+// DispatchOnce sends one batch of due deliveries and returns its size.
+func (d *Dispatcher) DispatchOnce(ctx context.Context) (int, error) {
+	lease := time.Duration(d.batchSize)*d.client.Timeout + leaseMargin
+	deliveries, err := d.store.ClaimDue(ctx, d.batchSize, lease)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, dl := range deliveries {
+		sendErr := d.send(ctx, dl)
+		switch {
+		case sendErr == nil:
+			err = d.store.MarkDelivered(ctx, dl.ID, dl.Attempts)
+		case dl.Attempts >= d.maxAttempts:
+			d.logger.ErrorContext(ctx, "webhook delivery dead",
+				"delivery_id", dl.ID,
+				"url", dl.URL,
+				"attempts", dl.Attempts,
+				"error", sendErr,
+			)
+			err = d.store.MarkDead(ctx, dl.ID, dl.Attempts, sendErr.Error())
+		default:
+			d.logger.WarnContext(ctx, "webhook delivery failed",
+				"delivery_id", dl.ID,
+				"url", dl.URL,
+				"attempt", dl.Attempts,
+				"error", sendErr,
+			)
+			err = d.store.Reschedule(ctx, dl.ID, dl.Attempts, d.backoff(dl.Attempts), sendErr.Error())
+		}
+		// The lease ran out and another dispatcher claimed the delivery, or
+		// it was replayed; the outcome is theirs to record now.
+		if errors.Is(err, webhookErrors.ErrLeaseLost) {
+			d.logger.WarnContext(ctx, "webhook delivery lease lost",
+				"delivery_id", dl.ID,
+				"attempt", dl.Attempts,
+			)
+			continue
+		}
+		if err != nil {
+			return len(deliveries), err
+		}
+	}
+
+	return len(deliveries), nil
+}
+
+// backoff returns the delay after the given number of failed attempts.
+func (d *Dispatcher) backoff(attempts int) time.Duration {
+	delay := d.baseBackoff
+	for i := 1; i < attempts && delay < d.maxBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, d.maxBackoff)
+}
+
+func (d *Dispatcher) send(ctx context.Context, dl webhook.DeliveryDB) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, dl.URL, bytes.NewReader(dl.Payload))
+	if err != nil {
+		return err
+	}
+
+	ts := d.now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(TimestampHeader, strconv.FormatInt(ts, 10))
+	req.Header.Set(SignatureHeader, Sign(dl.Secret, ts, dl.Payload))
+	req.Header.Set(EventHeader, dl.EventType)
+	req.Header.Set(DeliveryHeader, dl.ID.String())
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("receiver responded %d", resp.StatusCode)
+	}
+	return nil
+}
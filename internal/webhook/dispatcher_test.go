@@ -0,0 +1,172 @@
+package webhook_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	webhookErrors "github.com/totorialman/go-test-ac/internal/errors/webhook"
+	repo "github.com/totorialman/go-test-ac/internal/repository/webhook"
+	"github.com/totorialman/go-test-ac/internal/webhook"
+)
+
+func TestDispatcher_DispatchOnce(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	const secret = "s3cret"
+	payload := []byte(`{"id":"evt-1","type":"wallet.balance_changed"}`)
+
+	status := http.StatusOK
+	var received *http.Request
+	var receivedBody []byte
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r
+		receivedBody, _ = io.ReadAll(r.Body)
+		w.WriteHeader(status)
+	}))
+	defer receiver.Close()
+
+	mockStore := NewMockstore(ctrl)
+	d := webhook.NewDispatcher(mockStore,
+		webhook.WithHTTPClient(&http.Client{Timeout: time.Second}),
+		webhook.WithMaxAttempts(5),
+		webhook.WithBackoff(time.Second, 3*time.Second),
+	)
+
+	delivery := func(attempts int) repo.DeliveryDB {
+		return repo.DeliveryDB{
+			ID:        uuid.New(),
+			EventType: "wallet.balance_changed",
+			Payload:   payload,
+			Attempts:  attempts,
+			URL:       receiver.URL,
+			Secret:    secret,
+		}
+	}
+
+	tests := []struct {
+		name      string
+		status    int
+		delivery  repo.DeliveryDB
+		mockSetup func(dl repo.DeliveryDB)
+	}{
+		{
+			name:     "Delivered",
+			status:   http.StatusNoContent,
+			delivery: delivery(1),
+			mockSetup: func(dl repo.DeliveryDB) {
+				mockStore.EXPECT().MarkDelivered(gomock.Any(), dl.ID, 1).Return(nil)
+			},
+		},
+		{
+			name:     "Retried with backoff",
+			status:   http.StatusInternalServerError,
+			delivery: delivery(2),
+			mockSetup: func(dl repo.DeliveryDB) {
+				mockStore.EXPECT().Reschedule(gomock.Any(), dl.ID, 2, 2*time.Second, "receiver responded 500").Return(nil)
+			},
+		},
+		{
+			name:     "Backoff is capped",
+			status:   http.StatusBadGateway,
+			delivery: delivery(4),
+			mockSetup: func(dl repo.DeliveryDB) {
+				mockStore.EXPECT().Reschedule(gomock.Any(), dl.ID, 4, 3*time.Second, "receiver responded 502").Return(nil)
+			},
+		},
+		{
+			name:     "Dead after max attempts",
+			status:   http.StatusGone,
+			delivery: delivery(5),
+			mockSetup: func(dl repo.DeliveryDB) {
+				mockStore.EXPECT().MarkDead(gomock.Any(), dl.ID, 5, "receiver responded 410").Return(nil)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status = tt.status
+			received = nil
+
+			// The lease covers the whole batch sent one request at a time.
+			lease := webhook.DefaultBatchSize*time.Second + 5*time.Second
+			mockStore.EXPECT().ClaimDue(gomock.Any(), webhook.DefaultBatchSize, lease).
+				Return([]repo.DeliveryDB{tt.delivery}, nil)
+			tt.mockSetup(tt.delivery)
+
+			n, err := d.DispatchOnce(context.Background())
+			assert.NoError(t, err)
+			assert.Equal(t, 1, n)
+
+			if assert.NotNil(t, received) {
+				assert.Equal(t, payload, receivedBody)
+				assert.Equal(t, "wallet.balance_changed", received.Header.Get(webhook.EventHeader))
+				assert.Equal(t, tt.delivery.ID.String(), received.Header.Get(webhook.DeliveryHeader))
+				assert.NoError(t, webhook.Verify(secret,
+					received.Header.Get(webhook.SignatureHeader),
+					received.Header.Get(webhook.TimestampHeader),
+					receivedBody, time.Minute, time.Now(),
+				))
+			}
+		})
+	}
+}
+
+func TestDispatcher_BackoffGrowth(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer receiver.Close()
+
+	mockStore := NewMockstore(ctrl)
+	d := webhook.NewDispatcher(mockStore, webhook.WithBackoff(10*time.Second, time.Hour))
+
+	for attempts, want := range map[int]time.Duration{1: 10 * time.Second, 2: 20 * time.Second, 4: 80 * time.Second, 7: 640 * time.Second} {
+		dl := repo.DeliveryDB{ID: uuid.New(), Attempts: attempts, URL: receiver.URL, Payload: []byte(`{}`)}
+		mockStore.EXPECT().ClaimDue(gomock.Any(), gomock.Any(), gomock.Any()).Return([]repo.DeliveryDB{dl}, nil)
+		mockStore.EXPECT().Reschedule(gomock.Any(), dl.ID, attempts, want, gomock.Any()).Return(nil)
+
+		_, err := d.DispatchOnce(context.Background())
+		assert.NoError(t, err)
+	}
+}
+
+func TestDispatcher_LeaseLost(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var sent int
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sent++
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer receiver.Close()
+
+	mockStore := NewMockstore(ctrl)
+	d := webhook.NewDispatcher(mockStore)
+
+	stale := repo.DeliveryDB{ID: uuid.New(), Attempts: 1, URL: receiver.URL, Payload: []byte(`{}`)}
+	next := repo.DeliveryDB{ID: uuid.New(), Attempts: 1, URL: receiver.URL, Payload: []byte(`{}`)}
+	mockStore.EXPECT().ClaimDue(gomock.Any(), gomock.Any(), gomock.Any()).Return([]repo.DeliveryDB{stale, next}, nil)
+	gomock.InOrder(
+		mockStore.EXPECT().MarkDelivered(gomock.Any(), stale.ID, 1).Return(webhookErrors.ErrLeaseLost),
+		mockStore.EXPECT().MarkDelivered(gomock.Any(), next.ID, 1).Return(nil),
+	)
+
+	n, err := d.DispatchOnce(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 2, n)
+	assert.Equal(t, 2, sent)
+}
@@ -0,0 +1,34 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/totorialman/go-test-ac/internal/outbox"
+	"github.com/totorialman/go-test-ac/internal/repository/webhook"
+)
+
+// Fanout is an outbox.Publisher that turns each event into one queued
+// delivery per matching subscription. The Dispatcher sends them.
+type Fanout struct {
+	store store
+}
+
+func NewFanout(store store) *Fanout {
+	return &Fanout{store: store}
+}
+
+func (f *Fanout) Publish(ctx context.Context, e outbox.Event) error {
+	body, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	_, err = f.store.Enqueue(ctx, webhook.EnqueueDB{
+		EventID:   e.ID,
+		EventType: e.Type,
+		WalletID:  e.AggregateID,
+		Payload:   body,
+	})
+	return err
+}
@@ -0,0 +1,41 @@
+package webhook_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/totorialman/go-test-ac/internal/outbox"
+	repo "github.com/totorialman/go-test-ac/internal/repository/webhook"
+	"github.com/totorialman/go-test-ac/internal/webhook"
+)
+
+func TestFanout_Publish(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStore := NewMockstore(ctrl)
+	f := webhook.NewFanout(mockStore)
+
+	e := outbox.Event{
+		ID:          uuid.New(),
+		Type:        "wallet.balance_changed",
+		AggregateID: uuid.New(),
+		Payload:     json.RawMessage(`{"amount":100}`),
+	}
+
+	mockStore.EXPECT().Enqueue(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, q repo.EnqueueDB) (int64, error) {
+			assert.Equal(t, e.ID, q.EventID)
+			assert.Equal(t, e.Type, q.EventType)
+			assert.Equal(t, e.AggregateID, q.WalletID)
+			assert.Contains(t, string(q.Payload), `"payload":{"amount":100}`)
+			return 2, nil
+		})
+
+	assert.NoError(t, f.Publish(context.Background(), e))
+}
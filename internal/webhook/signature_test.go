@@ -0,0 +1,44 @@
+package webhook_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/totorialman/go-test-ac/internal/webhook"
+)
+
+func TestVerify(t *testing.T) {
+	now := time.Unix(1_800_000_000, 0)
+	body := []byte(`{"amount":100}`)
+	signature := webhook.Sign("secret", now.Unix(), body)
+
+	tests := []struct {
+		name      string
+		secret    string
+		signature string
+		timestamp string
+		body      []byte
+		wantErr   error
+	}{
+		{name: "valid", secret: "secret", signature: signature, timestamp: "1800000000", body: body},
+		{name: "tampered body", secret: "secret", signature: signature, timestamp: "1800000000", body: []byte(`{"amount":999}`), wantErr: webhook.ErrBadSignature},
+		{name: "wrong secret", secret: "other", signature: signature, timestamp: "1800000000", body: body, wantErr: webhook.ErrBadSignature},
+		{name: "replayed later", secret: "secret", signature: signature, timestamp: "1799999000", body: body, wantErr: webhook.ErrStaleRequest},
+		{name: "bad timestamp", secret: "secret", signature: signature, timestamp: "yesterday", body: body, wantErr: webhook.ErrBadSignature},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := webhook.Verify(tt.secret, tt.signature, tt.timestamp, tt.body, 5*time.Minute, now)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+
+	assert.Regexp(t, `^sha256=[0-9a-f]{64}$`, signature)
+}
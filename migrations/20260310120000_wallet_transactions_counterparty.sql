@@ -0,0 +1,9 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE wallet_transactions ADD COLUMN IF NOT EXISTS counterparty_wallet_id UUID REFERENCES wallets (id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE wallet_transactions DROP COLUMN IF EXISTS counterparty_wallet_id;
+-- +goose StatementEnd
@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE wallet_transactions ADD COLUMN IF NOT EXISTS reversal_of UUID REFERENCES wallet_transactions (id);
+
+CREATE INDEX IF NOT EXISTS wallet_transactions_reversal_of_idx
+    ON wallet_transactions (reversal_of) WHERE reversal_of IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS wallet_transactions_reversal_of_idx;
+ALTER TABLE wallet_transactions DROP COLUMN IF EXISTS reversal_of;
+-- +goose StatementEnd
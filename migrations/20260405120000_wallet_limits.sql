@@ -0,0 +1,33 @@
+-- +goose Up
+-- +goose StatementBegin
+-- NULL in any limit column means "no limit".
+CREATE TABLE IF NOT EXISTS wallet_limit_tiers (
+    name                TEXT PRIMARY KEY,
+    max_withdraw_amount BIGINT CHECK (max_withdraw_amount > 0),
+    max_daily_withdraw  BIGINT CHECK (max_daily_withdraw > 0),
+    max_balance         BIGINT CHECK (max_balance >= 0),
+    min_balance         BIGINT CHECK (min_balance >= 0)
+);
+
+INSERT INTO wallet_limit_tiers (name) VALUES ('default') ON CONFLICT (name) DO NOTHING;
+
+ALTER TABLE wallets
+    ADD COLUMN IF NOT EXISTS limit_tier TEXT NOT NULL DEFAULT 'default' REFERENCES wallet_limit_tiers (name);
+
+-- Per-wallet overrides; a NULL column falls back to the wallet's tier.
+CREATE TABLE IF NOT EXISTS wallet_limit_overrides (
+    wallet_id           UUID PRIMARY KEY REFERENCES wallets (id),
+    max_withdraw_amount BIGINT CHECK (max_withdraw_amount > 0),
+    max_daily_withdraw  BIGINT CHECK (max_daily_withdraw > 0),
+    max_balance         BIGINT CHECK (max_balance >= 0),
+    min_balance         BIGINT CHECK (min_balance >= 0),
+    updated_at          TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS wallet_limit_overrides;
+ALTER TABLE wallets DROP COLUMN IF EXISTS limit_tier;
+DROP TABLE IF EXISTS wallet_limit_tiers;
+-- +goose StatementEnd
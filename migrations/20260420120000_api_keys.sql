@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS api_keys (
+    id         UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name       TEXT NOT NULL,
+    -- The first characters of the key, kept to tell keys apart in listings.
+    prefix     TEXT NOT NULL,
+    -- SHA-256 of the whole key; the key itself is never stored.
+    key_hash   BYTEA NOT NULL UNIQUE,
+    scopes     TEXT[] NOT NULL,
+    -- Empty wallet_ids and NULL owner_id allow every wallet.
+    wallet_ids UUID[] NOT NULL DEFAULT '{}',
+    owner_id   TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    expires_at TIMESTAMPTZ,
+    revoked_at TIMESTAMPTZ
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS api_keys;
+-- +goose StatementEnd
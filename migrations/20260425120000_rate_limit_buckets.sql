@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Token buckets shared by all replicas. Losing them on a crash only resets
+-- the limits, so the table is not WAL-logged.
+CREATE UNLOGGED TABLE IF NOT EXISTS rate_limit_buckets (
+    key        TEXT PRIMARY KEY,
+    tokens     DOUBLE PRECISION NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS rate_limit_buckets;
+-- +goose StatementEnd
@@ -0,0 +1,29 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Deposits to a hot wallet go to one of its sub-balance buckets instead of
+-- the wallets row. The exact balance is wallets.balance plus the sum of the
+-- buckets until the compactor folds them back.
+ALTER TABLE wallets ADD COLUMN IF NOT EXISTS hot BOOLEAN NOT NULL DEFAULT false;
+
+CREATE TABLE IF NOT EXISTS wallet_balance_buckets (
+    wallet_id  UUID     NOT NULL REFERENCES wallets (id),
+    bucket     SMALLINT NOT NULL,
+    amount     BIGINT   NOT NULL DEFAULT 0,
+    PRIMARY KEY (wallet_id, bucket)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+UPDATE wallets w
+SET balance = w.balance + b.amount
+FROM (
+    SELECT wallet_id, SUM(amount)::BIGINT AS amount
+    FROM wallet_balance_buckets
+    GROUP BY wallet_id
+) b
+WHERE w.id = b.wallet_id;
+
+DROP TABLE IF EXISTS wallet_balance_buckets;
+ALTER TABLE wallets DROP COLUMN IF EXISTS hot;
+-- +goose StatementEnd
@@ -0,0 +1,62 @@
+// Package migrations embeds the goose SQL migrations so the binary can apply
+// them and knows which schema version it expects.
+package migrations
+
+import (
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/pressly/goose/v3"
+	"github.com/pressly/goose/v3/lock"
+)
+
+//go:embed *.sql
+var FS embed.FS
+
+// LatestVersion returns the version of the newest migration, taken from the
+// numeric prefix of its file name.
+func LatestVersion() (int64, error) {
+	files, err := fs.Glob(FS, "*.sql")
+	if err != nil {
+		return 0, err
+	}
+
+	var latest int64
+	for _, f := range files {
+		prefix, _, ok := strings.Cut(path.Base(f), "_")
+		if !ok {
+			return 0, fmt.Errorf("migration %s has no version prefix", f)
+		}
+		v, err := strconv.ParseInt(prefix, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("migration %s: %w", f, err)
+		}
+		latest = max(latest, v)
+	}
+	if latest == 0 {
+		return 0, fmt.Errorf("no migrations embedded")
+	}
+
+	return latest, nil
+}
+
+// NewProvider returns a goose provider over the embedded migrations. Every
+// command holds a Postgres advisory lock while it runs, so replicas migrating
+// the same database at once apply each migration exactly once.
+func NewProvider(db *sql.DB, logger *slog.Logger) (*goose.Provider, error) {
+	locker, err := lock.NewPostgresSessionLocker()
+	if err != nil {
+		return nil, err
+	}
+
+	return goose.NewProvider(goose.DialectPostgres, db, FS,
+		goose.WithSessionLocker(locker),
+		goose.WithSlog(logger),
+	)
+}